@@ -1,77 +1,162 @@
 package main
 
 import (
+	"booking_togo/internal/app"
+	"booking_togo/internal/certs"
 	"booking_togo/internal/config"
 	deliveryHttp "booking_togo/internal/delivery/http"
-	"booking_togo/internal/middleware"
-	"booking_togo/internal/repository"
-	"booking_togo/internal/usecase"
+	"booking_togo/internal/lifecycle"
+	"booking_togo/internal/metrics"
+	"booking_togo/internal/migration"
+	"booking_togo/internal/tracing"
 	"context"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
-
-	"github.com/gorilla/handlers"
-	"github.com/gorilla/mux"
 )
 
 func main() {
-	cfg := config.Load()
-	config.InitLogger()
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := runSeed(os.Args[2:]); err != nil {
+			log.Fatalf("seed failed: %v", err)
+		}
+		return
+	}
+
+	cfg, cfgErr := config.Load(os.Args[1:])
+	if errors.Is(cfgErr, flag.ErrHelp) {
+		return
+	}
+	if cfgErr != nil {
+		log.Fatalf("failed to load config: %v", cfgErr)
+	}
+	config.InitLogger(cfg)
+
+	// stop hooks run in reverse registration order on shutdown
+	lc := lifecycle.New()
+
+	// tracing
+	shutdownTracing, tracingErr := tracing.Init(context.Background(), cfg.TracingExporter)
+	if tracingErr != nil {
+		log.Fatalf("failed to init tracing: %v", tracingErr)
+	}
+	lc.OnStop("tracing", shutdownTracing)
+
 	// connection DB with PGX
 	pgxPool, pgxPoolErr := config.NewPool(cfg)
 	if pgxPoolErr != nil {
 		log.Fatalf("failed to connect to database: %v", pgxPoolErr)
 	}
+	lc.OnStop("database pool", func(context.Context) error {
+		pgxPool.Close()
+		return nil
+	})
 
-	// repository
-	repo := repository.NewUserRepository(pgxPool)
-
-	// usecase
-	usecaseUser := usecase.NewUserUsecase(repo)
-
-	// handlers
-	h := deliveryHttp.NewUserFamilyHandler(usecaseUser)
-
-	// router
-	r := mux.NewRouter()
-	r.Use(middleware.LoggingMiddleware)
+	// schema migrations
+	if cfg.MigrateOnStart {
+		if err := migration.Up(context.Background(), pgxPool); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
 
-	// CORS configuration
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
-	originsOk := handlers.AllowedOrigins([]string{"*"}) // or specific origins: {"http://localhost:3000", "https://example.com"}
-	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(pgxPool))
 
-	api := r.PathPrefix("/api/v1").Subrouter()
-	h.RegisterRoutes(api)
+	// repository, usecase, handlers and router
+	application, appErr := app.New(cfg, pgxPool)
+	if appErr != nil {
+		log.Fatalf("failed to build application: %v", appErr)
+	}
+	if cfg.Document.EncryptionKey == "" {
+		log.Println("warning: DOCUMENT_ENCRYPTION_KEY is not set, travel document numbers stored now cannot be read after a restart")
+	}
 
-	handler := handlers.CORS(originsOk, headersOk, methodsOk)(r)
+	// runtime reload on SIGHUP and POST /admin/reload
+	reloader := newRuntimeReloader(os.Args[1:], cfg, application.Limiter, application.Handler)
+	adminHandler := deliveryHttp.NewAdminHandler(cfg.AdminToken.Reveal(), reloader.Reload)
+	adminHandler.RegisterRoutes(application.Router)
 
 	srv := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: handler,
+		Addr:              ":" + cfg.Port,
+		Handler:           application.Handler,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// HTTPS with hot-reloaded certificates and optional client certificates
+	if cfg.TLS.Enabled() {
+		certReloader, err := certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			log.Fatalf("failed to load TLS certificate: %v", err)
+		}
+		tlsConfig, err := certs.ServerConfig(certReloader, cfg.TLS.ClientAuth, cfg.TLS.ClientCAFile)
+		if err != nil {
+			log.Fatalf("failed to configure TLS: %v", err)
+		}
+		srv.TLSConfig = tlsConfig
+		lc.Go("tls certificate reloader", func(ctx context.Context) {
+			certReloader.Watch(ctx, cfg.TLS.ReloadInterval)
+		})
 	}
+	lc.Go("sighup reloader", reloader.WatchSignals)
+	lc.Go("seat hold reaper", func(ctx context.Context) {
+		application.Bookings.WatchExpiredHolds(ctx, cfg.Booking.HoldReaperInterval)
+	})
+	lc.Go("payment reconciler", func(ctx context.Context) {
+		application.Payments.WatchPayments(ctx, cfg.Payment.ReconcileInterval, cfg.Payment.PendingTimeout)
+	})
+	lc.OnStop("background workers", lc.StopWorkers)
 
+	serverErr := make(chan error, 1)
 	go func() {
-		log.Printf("server starting on %s\n", srv.Addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+		var err error
+		if cfg.TLS.Enabled() {
+			log.Printf("server starting with TLS on %s\n", srv.Addr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("server starting on %s\n", srv.Addr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			serverErr <- err
 		}
 	}()
+	lc.OnStop("http server", srv.Shutdown)
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-	log.Println("shutting down server...")
+	// Graceful shutdown on Ctrl-C (SIGINT) and on SIGTERM from Docker/Kubernetes
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case <-sigCtx.Done():
+		// a second signal now terminates the process immediately
+		stopSignals()
+		log.Println("shutting down server...")
+
+		// fail readiness first so no new traffic is routed to this instance
+		application.Checker.SetDraining(true)
+		log.Printf("draining for %s\n", cfg.HTTP.DrainPeriod)
+		time.Sleep(cfg.HTTP.DrainPeriod)
+	case err := <-serverErr:
+		stopSignals()
+		log.Printf("listen: %s\n", err)
+		exitCode = 1
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	// http server, background workers, database pool, then tracing
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	if err := lc.Shutdown(ctx); err != nil {
+		log.Printf("shutdown incomplete: %v", err)
+		exitCode = 1
 	}
+	cancel()
 
 	log.Println("server stopped")
+	os.Exit(exitCode)
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"booking_togo/internal/app"
+	"booking_togo/internal/config"
+	"booking_togo/internal/middleware"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runtimeReloader re-reads the configuration on SIGHUP or POST /admin/reload
+// and swaps the parts that can change without a restart: log level and
+// format, rate limits and the CORS policy. Anything else that changed is
+// only reported, since it needs a restart to take effect.
+type runtimeReloader struct {
+	args    []string
+	limiter *middleware.RateLimiter
+	cors    *middleware.CORSHandler
+
+	mu      sync.Mutex
+	current *config.Config
+}
+
+func newRuntimeReloader(args []string, cfg *config.Config, limiter *middleware.RateLimiter, cors *middleware.CORSHandler) *runtimeReloader {
+	return &runtimeReloader{
+		args:    args,
+		limiter: limiter,
+		cors:    cors,
+		current: cfg,
+	}
+}
+
+// Reload loads and validates the new configuration before touching anything,
+// so an invalid file or env leaves the running settings untouched.
+func (rr *runtimeReloader) Reload(ctx context.Context) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	next, err := config.Load(rr.args)
+	if err != nil {
+		log.Error("Config reload rejected: ", err)
+		return err
+	}
+
+	if err := rr.cors.Update(app.CORSPolicy(next.CORS)); err != nil {
+		log.Error("Config reload rejected: ", err)
+		return fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	rr.limiter.SetLimit(next.RateLimit.RequestsPerSecond, next.RateLimit.Burst)
+	config.InitLogger(next)
+
+	for _, name := range restartRequired(rr.current, next) {
+		log.Warnf("Config reload: %s changed but requires a restart", name)
+	}
+
+	// keep the startup-only settings so later diffs stay relative to what runs
+	applied := *rr.current
+	applied.Log = next.Log
+	applied.RateLimit = next.RateLimit
+	applied.CORS = next.CORS
+	rr.current = &applied
+
+	log.Info("✅ configuration reloaded")
+	return nil
+}
+
+// WatchSignals reloads on every SIGHUP until ctx is done.
+func (rr *runtimeReloader) WatchSignals(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("SIGHUP received, reloading configuration")
+			rr.Reload(ctx)
+		}
+	}
+}
+
+func restartRequired(current, next *config.Config) []string {
+	a, b := *current, *next
+	a.Log, b.Log = config.LogConfig{}, config.LogConfig{}
+	a.RateLimit, b.RateLimit = config.RateLimitConfig{}, config.RateLimitConfig{}
+	a.CORS, b.CORS = config.CORSConfig{}, config.CORSConfig{}
+
+	var changed []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
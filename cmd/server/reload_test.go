@@ -0,0 +1,76 @@
+package main
+
+import (
+	"booking_togo/internal/app"
+	"booking_togo/internal/config"
+	"booking_togo/internal/middleware"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRuntimeReloaderRejectsInvalidConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DATABASE_URL", "postgres://localhost/booking")
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("rate_limit: {requests_per_second: 1, burst: 1}\n")
+
+	args := []string{"-config", file}
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := middleware.NewRateLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	cors, err := middleware.NewCORSHandler(app.CORSPolicy(cfg.CORS), http.NotFoundHandler())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader := newRuntimeReloader(args, cfg, limiter, cors)
+
+	write("rate_limit: {requests_per_second: 5, burst: 10}\nport: \"9090\"\n")
+	if err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("valid reload: %v", err)
+	}
+	if reloader.current.RateLimit.Burst != 10 || reloader.current.Port != cfg.Port {
+		t.Fatalf("applied %+v on port %s, want the new limits and the startup port", reloader.current.RateLimit, reloader.current.Port)
+	}
+
+	for name, content := range map[string]string{
+		"invalid config":      "rate_limit: {requests_per_second: -1}\n",
+		"invalid CORS origin": "cors: {allowed_origins: [\"not an origin\"]}\n",
+	} {
+		write(content)
+		if err := reloader.Reload(context.Background()); err == nil {
+			t.Errorf("%s: reload accepted", name)
+		}
+		if reloader.current.RateLimit.Burst != 10 || !slices.Equal(reloader.current.CORS.AllowedOrigins, []string{"*"}) {
+			t.Errorf("%s: running settings changed to %+v", name, reloader.current)
+		}
+	}
+
+	// the CORS policy in effect is still the wildcard one
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Origin", "https://anywhere.test")
+	w := httptest.NewRecorder()
+	cors.ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q after a rejected reload, want *", got)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	current := &config.Config{Port: "8080", Log: config.LogConfig{Level: "info"}}
+	next := &config.Config{Port: "9090", Log: config.LogConfig{Level: "debug"}}
+	if got := restartRequired(current, next); !slices.Equal(got, []string{"Port"}) {
+		t.Errorf("restartRequired = %v, want [Port]", got)
+	}
+}
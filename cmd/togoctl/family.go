@@ -0,0 +1,141 @@
+package main
+
+import (
+	"booking_togo/internal/model"
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+func newFamilyCommand(c *cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "family",
+		Short: "Add and remove family members of a customer",
+	}
+	cmd.AddCommand(newFamilyAddCommand(c), newFamilyRemoveCommand(c))
+	return cmd
+}
+
+func newFamilyAddCommand(c *cli) *cobra.Command {
+	var (
+		family   model.Family
+		guardian int
+	)
+	cmd := &cobra.Command{
+		Use:   "add USER_ID --name NAME --dob YYYY-MM-DD [--guardian FAMILY_ID]",
+		Short: "Add a family member to a customer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("guardian") {
+				family.Guardian = &model.Guardian{FamilyID: guardian}
+			}
+			return c.familyAdd(cmd, args, family)
+		},
+	}
+	cmd.Flags().StringVar(&family.Name, "name", "", "family member name")
+	cmd.Flags().StringVar(&family.Dob, "dob", "", "family member date of birth (YYYY-MM-DD)")
+	cmd.Flags().IntVar(&guardian, "guardian", 0, "family ID of the member's guardian, 0 for the customer; required to book members under 18")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("dob")
+	return cmd
+}
+
+func newFamilyRemoveCommand(c *cli) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "remove USER_ID FAMILY_ID",
+		Short: "Remove a family member from a customer",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.familyRemove(cmd, args, yes)
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
+
+// familyAdd goes through UserUsecase.Update, the same path as PUT
+// /api/v1/user/{id}, so the member is validated like an API request. The
+// customer fields, emergency contacts included, are sent back unchanged.
+func (c *cli) familyAdd(cmd *cobra.Command, args []string, family model.Family) error {
+	userID, err := parseID("USER_ID", args[0])
+	if err != nil {
+		return err
+	}
+
+	user, err := c.detail(cmd, userID)
+	if err != nil {
+		return err
+	}
+
+	family.UserID = userID
+	update := &model.User{
+		UserID:        user.UserID,
+		Name:          user.Name,
+		Dob:           user.Dob,
+		NationalityID: user.NationalityID,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Address:       user.Address,
+		Families:      []model.Family{family},
+
+		EmergencyContacts: user.EmergencyContacts,
+	}
+
+	ctx, cancel := c.context(cmd)
+	defer cancel()
+	if err := c.users.Update(ctx, update); err != nil {
+		return err
+	}
+
+	updated, err := c.detail(cmd, userID)
+	if err != nil {
+		return err
+	}
+	if c.printer.json() {
+		return c.printer.writeJSON(updated.Families)
+	}
+	c.printer.message("Added %s to customer %d", family.Name, userID)
+	return c.printFamilies(updated.Families)
+}
+
+func (c *cli) familyRemove(cmd *cobra.Command, args []string, yes bool) error {
+	userID, err := parseID("USER_ID", args[0])
+	if err != nil {
+		return err
+	}
+	familyID, err := parseID("FAMILY_ID", args[1])
+	if err != nil {
+		return err
+	}
+
+	user, err := c.detail(cmd, userID)
+	if err != nil {
+		return err
+	}
+	var member *model.Family
+	for i := range user.Families {
+		if user.Families[i].FamilyID == familyID {
+			member = &user.Families[i]
+		}
+	}
+	if member == nil {
+		return errors.New("family member not found for this customer")
+	}
+	if !confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), yes,
+		"Remove %s from customer %d (%s)?", member.Name, user.UserID, user.Name) {
+		return errors.New("aborted")
+	}
+
+	ctx, cancel := c.context(cmd)
+	defer cancel()
+	if err := c.users.DeleteFamily(ctx, userID, familyID); err != nil {
+		return err
+	}
+
+	if c.printer.json() {
+		return c.printer.writeJSON(map[string]any{"deleted": true, "user_id": userID, "family_id": familyID})
+	}
+	c.printer.message("Removed family member %d from customer %d", familyID, userID)
+	return nil
+}
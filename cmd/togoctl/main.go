@@ -0,0 +1,114 @@
+// togoctl is the support CLI for looking up and fixing customers directly
+// against the database, going through the same usecases as the HTTP API.
+package main
+
+import (
+	"booking_togo/internal/config"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/usecase"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// cli holds the global flags and the dependencies built from them.
+type cli struct {
+	configFile  string
+	databaseURL string
+	output      string
+	timeout     time.Duration
+	verbose     bool
+
+	pool          *pgxpool.Pool
+	users         usecase.IUserUsecase
+	nationalities usecase.INationalityUsecase
+	printer       *printer
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	c := &cli{}
+
+	root := &cobra.Command{
+		Use:          "togoctl",
+		Short:        "Inspect and repair booking_togo customers",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.setup(cmd)
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if c.pool != nil {
+				c.pool.Close()
+			}
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&c.configFile, "config", "", "path to a YAML or TOML config file (default $CONFIG_FILE)")
+	flags.StringVar(&c.databaseURL, "database-url", "", "postgres connection URL, overrides the config (default $DATABASE_URL)")
+	flags.StringVarP(&c.output, "output", "o", "table", "output format: table or json")
+	flags.DurationVar(&c.timeout, "timeout", 30*time.Second, "timeout for each command")
+	flags.BoolVarP(&c.verbose, "verbose", "v", false, "write application logs to stderr")
+
+	root.AddCommand(
+		newUserCommand(c),
+		newFamilyCommand(c),
+		newNationalityCommand(c),
+	)
+	return root
+}
+
+// setup loads the server configuration, so togoctl reaches the same database
+// as the service, and wires the usecases.
+func (c *cli) setup(cmd *cobra.Command) error {
+	printer, err := newPrinter(c.output, cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+	c.printer = printer
+
+	var args []string
+	if c.configFile != "" {
+		args = append(args, "-config", c.configFile)
+	}
+	if c.databaseURL != "" {
+		args = append(args, "-database-url", c.databaseURL)
+	}
+	cfg, err := config.Load(args)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// logs would interleave with the command output
+	config.InitLogger(cfg)
+	if c.verbose {
+		log.SetOutput(cmd.ErrOrStderr())
+	} else {
+		log.SetOutput(io.Discard)
+	}
+
+	c.pool, err = config.NewPool(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	nationalityRepo := repository.NewNationalityRepository(c.pool)
+	c.users = usecase.NewUserUsecase(repository.NewUserRepository(c.pool), nationalityRepo)
+	c.nationalities = usecase.NewNationalityUsecase(nationalityRepo)
+	return nil
+}
+
+func (c *cli) context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), c.timeout)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newNationalityCommand(c *cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nationality",
+		Short: "Browse reference nationalities",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List nationalities and their IDs",
+		Args:  cobra.NoArgs,
+		RunE:  c.nationalityList,
+	})
+	return cmd
+}
+
+func (c *cli) nationalityList(cmd *cobra.Command, args []string) error {
+	ctx, cancel := c.context(cmd)
+	defer cancel()
+
+	nationalities, err := c.nationalities.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if c.printer.json() {
+		return c.printer.writeJSON(nationalities)
+	}
+
+	rows := make([][]string, 0, len(nationalities))
+	for _, nationality := range nationalities {
+		rows = append(rows, []string{strconv.Itoa(nationality.NationalityID), nationality.NationalityCode, nationality.NationalityName})
+	}
+	return c.printer.table([]string{"ID", "CODE", "NAME"}, rows)
+}
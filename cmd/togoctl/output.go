@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer renders command results either as aligned tables for people or as
+// JSON for scripts.
+type printer struct {
+	format string
+	out    io.Writer
+}
+
+func newPrinter(format string, out io.Writer) (*printer, error) {
+	switch format {
+	case outputTable, outputJSON:
+		return &printer{format: format, out: out}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (use %s or %s)", format, outputTable, outputJSON)
+	}
+}
+
+func (p *printer) json() bool {
+	return p.format == outputJSON
+}
+
+func (p *printer) writeJSON(v any) error {
+	encoder := json.NewEncoder(p.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// table writes a header and rows as tab-aligned columns.
+func (p *printer) table(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) message(format string, args ...any) {
+	if p.json() {
+		return
+	}
+	fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+// confirm asks on in/out unless yes is already set.
+func confirm(in io.Reader, out io.Writer, yes bool, format string, args ...any) bool {
+	if yes {
+		return true
+	}
+	fmt.Fprintf(out, format+" [y/N]: ", args...)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"booking_togo/internal/model"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+)
+
+func newUserCommand(c *cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Look up and delete customers",
+	}
+	cmd.AddCommand(
+		newUserListCommand(c),
+		&cobra.Command{
+			Use:   "get USER_ID",
+			Short: "Show a customer with nationality and family",
+			Args:  cobra.ExactArgs(1),
+			RunE:  c.userGet,
+		},
+		newUserDeleteCommand(c),
+	)
+	return cmd
+}
+
+func newUserListCommand(c *cli) *cobra.Command {
+	var search model.UserSearch
+	cmd := &cobra.Command{
+		Use:   "list [--email EMAIL] [--phone PHONE]",
+		Short: "List customers, or find them by email or phone",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.userList(cmd, search)
+		},
+	}
+	cmd.Flags().StringVar(&search.Email, "email", "", "only the customer with this email")
+	cmd.Flags().StringVar(&search.Phone, "phone", "", "only customers with this phone, country code included")
+	return cmd
+}
+
+func newUserDeleteCommand(c *cli) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "delete USER_ID",
+		Short: "Delete a customer and their family members",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.userDelete(cmd, args, yes)
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
+
+func (c *cli) userList(cmd *cobra.Command, search model.UserSearch) error {
+	ctx, cancel := c.context(cmd)
+	defer cancel()
+
+	var (
+		users []*model.UserDetailResponse
+		err   error
+	)
+	if search.Email != "" || search.Phone != "" {
+		users, err = c.users.Search(ctx, search)
+	} else {
+		users, err = c.users.GetAll(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if c.printer.json() {
+		return c.printer.writeJSON(users)
+	}
+
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, []string{
+			strconv.Itoa(user.UserID), user.Name, user.Dob,
+			user.Nationality.NationalityCode, strconv.Itoa(len(user.Families)),
+		})
+	}
+	return c.printer.table([]string{"ID", "NAME", "DOB", "NATIONALITY", "FAMILY"}, rows)
+}
+
+func (c *cli) userGet(cmd *cobra.Command, args []string) error {
+	userID, err := parseID("USER_ID", args[0])
+	if err != nil {
+		return err
+	}
+
+	user, err := c.detail(cmd, userID)
+	if err != nil {
+		return err
+	}
+	if c.printer.json() {
+		return c.printer.writeJSON(user)
+	}
+
+	if err := c.printer.table([]string{"ID", "NAME", "DOB", "NATIONALITY", "EMAIL", "PHONE"}, [][]string{{
+		strconv.Itoa(user.UserID), user.Name, user.Dob,
+		fmt.Sprintf("%s (%s)", user.Nationality.NationalityName, user.Nationality.NationalityCode),
+		user.Email, user.Phone,
+	}}); err != nil {
+		return err
+	}
+	c.printer.message("")
+	return c.printFamilies(user.Families)
+}
+
+func (c *cli) userDelete(cmd *cobra.Command, args []string, yes bool) error {
+	userID, err := parseID("USER_ID", args[0])
+	if err != nil {
+		return err
+	}
+
+	// the repository delete succeeds silently for unknown IDs, so look first
+	user, err := c.detail(cmd, userID)
+	if err != nil {
+		return err
+	}
+	if !confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), yes,
+		"Delete customer %d (%s) and %d family members?", user.UserID, user.Name, len(user.Families)) {
+		return errors.New("aborted")
+	}
+
+	ctx, cancel := c.context(cmd)
+	defer cancel()
+	if err := c.users.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	if c.printer.json() {
+		return c.printer.writeJSON(map[string]any{"deleted": true, "user_id": userID})
+	}
+	c.printer.message("Deleted customer %d", userID)
+	return nil
+}
+
+// detail loads a customer, turning a missing row into a readable error.
+func (c *cli) detail(cmd *cobra.Command, userID int) (*model.UserDetailResponse, error) {
+	ctx, cancel := c.context(cmd)
+	defer cancel()
+
+	user, err := c.users.Detail(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("customer %d not found", userID)
+	}
+	return user, err
+}
+
+func (c *cli) printFamilies(families []model.Family) error {
+	rows := make([][]string, 0, len(families))
+	for _, family := range families {
+		guardian := "-"
+		switch {
+		case family.Guardian == nil:
+		case family.Guardian.FamilyID == 0:
+			guardian = "customer"
+		default:
+			guardian = strconv.Itoa(family.Guardian.FamilyID)
+		}
+		rows = append(rows, []string{strconv.Itoa(family.FamilyID), family.Name, family.Dob, guardian})
+	}
+	return c.printer.table([]string{"FAMILY_ID", "NAME", "DOB", "GUARDIAN"}, rows)
+}
+
+func parseID(name, raw string) (int, error) {
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, raw)
+	}
+	return id, nil
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"booking_togo/internal/config"
+	deliveryHttp "booking_togo/internal/delivery/http"
+	"booking_togo/internal/health"
+	"booking_togo/internal/itinerary"
+	"booking_togo/internal/metrics"
+	"booking_togo/internal/middleware"
+	"booking_togo/internal/payment"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/secretbox"
+	"booking_togo/internal/tracing"
+	"booking_togo/internal/usecase"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+)
+
+// App is the fully wired HTTP application. cmd/server and the end-to-end
+// tests both build it through New so they exercise the same stack.
+type App struct {
+	Router  *mux.Router
+	Handler *middleware.CORSHandler
+	Checker *health.Checker
+	Limiter *middleware.RateLimiter
+	// Bookings runs the background release of expired seat holds.
+	Bookings *usecase.BookingUsecase
+	// Payments runs the background payment reconciliation.
+	Payments *usecase.PaymentUsecase
+}
+
+func New(cfg *config.Config, pgxPool *pgxpool.Pool) (*App, error) {
+	// repository
+	repo := repository.NewUserRepository(pgxPool)
+	bookingRepo := repository.NewBookingRepository(pgxPool)
+	tripRepo := repository.NewTripRepository(pgxPool)
+	paymentRepo := repository.NewPaymentRepository(pgxPool)
+	documentRepo := repository.NewDocumentRepository(pgxPool)
+	nationalityRepo := repository.NewNationalityRepository(pgxPool)
+
+	// payment gateway
+	gateway, err := NewPaymentGateway(cfg.Payment)
+	if err != nil {
+		return nil, err
+	}
+
+	refundPolicy, err := usecase.ParseRefundPolicy(cfg.Refund.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REFUND_RULES: %w", err)
+	}
+
+	documentBox, err := secretbox.NewFromBase64(cfg.Document.EncryptionKey.Reveal())
+	if err != nil {
+		return nil, fmt.Errorf("invalid DOCUMENT_ENCRYPTION_KEY: %w", err)
+	}
+
+	renderer, err := itinerary.New()
+	if err != nil {
+		return nil, err
+	}
+
+	// usecase
+	usecaseUser := usecase.NewUserUsecase(repo, nationalityRepo)
+	usecaseBooking := usecase.NewBookingUsecase(bookingRepo, repo, tripRepo, paymentRepo, documentRepo, cfg.Booking.HoldTTL)
+	usecaseTrip := usecase.NewTripUsecase(tripRepo)
+	usecaseQuote := usecase.NewQuoteUsecase(repo, tripRepo, FareRules(cfg.Pricing))
+	usecasePayment := usecase.NewPaymentUsecase(paymentRepo, tripRepo, usecaseBooking, gateway, FareRules(cfg.Pricing))
+	usecaseCancellation := usecase.NewCancellationUsecase(bookingRepo, paymentRepo, tripRepo, usecaseBooking, usecasePayment, refundPolicy)
+	usecaseDocument := usecase.NewDocumentUsecase(documentRepo, repo, nationalityRepo, documentBox)
+	usecaseConfirmation := usecase.NewConfirmationUsecase(bookingRepo, repo, tripRepo, paymentRepo, documentRepo, FareRules(cfg.Pricing))
+
+	// handlers
+	h := deliveryHttp.NewUserFamilyHandler(usecaseUser, cfg.HTTP.HandlerTimeout)
+	bookingHandler := deliveryHttp.NewBookingHandler(usecaseBooking, cfg.HTTP.HandlerTimeout)
+	tripHandler := deliveryHttp.NewTripHandler(usecaseTrip, cfg.HTTP.HandlerTimeout)
+	quoteHandler := deliveryHttp.NewQuoteHandler(usecaseQuote, cfg.HTTP.HandlerTimeout)
+	paymentHandler := deliveryHttp.NewPaymentHandler(usecasePayment, cfg.HTTP.HandlerTimeout)
+	cancellationHandler := deliveryHttp.NewCancellationHandler(usecaseCancellation, cfg.HTTP.HandlerTimeout)
+	documentHandler := deliveryHttp.NewDocumentHandler(usecaseDocument, cfg.HTTP.HandlerTimeout)
+	confirmationHandler := deliveryHttp.NewConfirmationHandler(usecaseConfirmation, renderer, cfg.HTTP.HandlerTimeout)
+
+	// health checks
+	checker := health.NewChecker(cfg.HTTP.HealthTimeout)
+	checker.Register("database", health.DatabaseCheck(pgxPool))
+	checker.Register("migrations", health.MigrationCheck(pgxPool))
+	healthHandler := deliveryHttp.NewHealthHandler(checker)
+
+	// client IP resolution, only trusting forwarding headers from known proxies
+	clientIP, err := middleware.NewClientIPResolver(cfg.TrustedProxies, cfg.ForwardedHeader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES or FORWARDED_HEADER: %w", err)
+	}
+
+	// router
+	r := mux.NewRouter()
+	r.Use(otelmux.Middleware(tracing.ServiceName))
+	r.Use(clientIP.Middleware)
+	r.Use(middleware.RequestIDMiddleware)
+	r.Use(middleware.LoggingMiddleware)
+
+	r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	healthHandler.RegisterRoutes(r)
+
+	// rate limiting per client IP, API routes only so probes are never throttled
+	limiter := middleware.NewRateLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(limiter.Middleware)
+	h.RegisterRoutes(api)
+	bookingHandler.RegisterRoutes(api)
+	tripHandler.RegisterRoutes(api)
+	quoteHandler.RegisterRoutes(api)
+	paymentHandler.RegisterRoutes(api)
+	cancellationHandler.RegisterRoutes(api)
+	documentHandler.RegisterRoutes(api)
+	confirmationHandler.RegisterRoutes(api)
+
+	// CORS configuration
+	handler, err := middleware.NewCORSHandler(CORSPolicy(cfg.CORS), middleware.MetricsMiddleware(r))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+
+	return &App{
+		Router:   r,
+		Handler:  handler,
+		Checker:  checker,
+		Limiter:  limiter,
+		Bookings: usecaseBooking,
+		Payments: usecasePayment,
+	}, nil
+}
+
+// CORSPolicy maps the CORS configuration section to the middleware policy.
+func CORSPolicy(cfg config.CORSConfig) middleware.CORSPolicy {
+	return middleware.CORSPolicy{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+}
+
+// NewPaymentGateway builds the configured payment gateway.
+func NewPaymentGateway(cfg config.PaymentConfig) (payment.Gateway, error) {
+	switch cfg.Gateway {
+	case "fake":
+		return payment.NewFakeGateway(cfg.WebhookSecret.Reveal(), cfg.WebhookTolerance), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", cfg.Gateway)
+	}
+}
+
+// FareRules maps the pricing configuration section to the quote fare rules.
+func FareRules(cfg config.PricingConfig) usecase.FareRules {
+	return usecase.FareRules{
+		Currency:             cfg.Currency,
+		InfantMaxAge:         cfg.InfantMaxAge,
+		ChildMaxAge:          cfg.ChildMaxAge,
+		InfantPercent:        cfg.InfantPercent,
+		ChildPercent:         cfg.ChildPercent,
+		GroupMinPassengers:   cfg.GroupMinPassengers,
+		GroupDiscountPercent: cfg.GroupDiscountPercent,
+		EarlyBookingDays:     cfg.EarlyBookingDays,
+		EarlyBookingPercent:  cfg.EarlyBookingPercent,
+	}
+}
@@ -0,0 +1,1518 @@
+package app
+
+import (
+	"booking_togo/internal/config"
+	"booking_togo/internal/migration"
+	"booking_togo/internal/model"
+	"booking_togo/internal/pgtest"
+	"booking_togo/internal/repository"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	log "github.com/sirupsen/logrus"
+)
+
+// rollbackFamilyName makes the test-only trigger below fail the family
+// insert, after the customer row was already written in the same transaction.
+const rollbackFamilyName = "Rollback Trigger"
+
+const rollbackTrigger = `
+CREATE OR REPLACE FUNCTION e2e_reject_family() RETURNS trigger AS $$
+BEGIN
+	IF NEW.fl_name = '` + rollbackFamilyName + `' THEN
+		RAISE EXCEPTION 'e2e: rejected family member';
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER e2e_reject_family BEFORE INSERT OR UPDATE ON family_list
+	FOR EACH ROW EXECUTE PROCEDURE e2e_reject_family();
+`
+
+var (
+	e2ePool    *pgxpool.Pool
+	e2eApp     *App
+	e2eServer  *httptest.Server
+	e2eSkipped string
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runE2E(m))
+}
+
+func runE2E(m *testing.M) int {
+	log.SetOutput(io.Discard)
+
+	server, err := pgtest.Start()
+	if errors.Is(err, pgtest.ErrUnavailable) {
+		e2eSkipped = err.Error()
+		return m.Run()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+	defer server.Stop()
+
+	ctx := context.Background()
+	databaseURL, err := server.CreateDatabase(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+
+	// configured the way cmd/server is, only pointed at the test database
+	cfg, err := config.Load([]string{"-database-url", databaseURL})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+
+	e2ePool, err = config.NewPool(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+	defer e2ePool.Close()
+
+	if err := migration.Up(ctx, e2ePool); err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+	if _, err := e2ePool.Exec(ctx, rollbackTrigger); err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+
+	e2eApp, err = New(cfg, e2ePool)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e: ", err)
+		return 1
+	}
+	e2eServer = httptest.NewServer(e2eApp.Handler)
+	defer e2eServer.Close()
+
+	return m.Run()
+}
+
+func requireE2E(t *testing.T) {
+	t.Helper()
+	if e2eSkipped != "" {
+		t.Skip(e2eSkipped)
+	}
+}
+
+// seed is a customer created straight through the repository.
+type seed struct {
+	nationalityID int
+	userID        int
+	familyIDs     []int
+}
+
+func seedCustomer(t *testing.T, name string, families ...string) seed {
+	t.Helper()
+	ctx := context.Background()
+
+	var nationalityID int
+	err := e2ePool.QueryRow(ctx,
+		`INSERT INTO nationality (nationality_name, nationality_code) VALUES ('Indonesia', 'ID') RETURNING nationality_id`,
+	).Scan(&nationalityID)
+	if err != nil {
+		t.Fatalf("seed nationality: %v", err)
+	}
+
+	user := &model.User{Name: name, Dob: "1990-05-15", NationalityID: nationalityID}
+	for _, family := range families {
+		user.Families = append(user.Families, model.Family{Name: family, Dob: "2012-03-04", Guardian: &model.Guardian{FamilyID: 0}})
+	}
+
+	repo := repository.NewUserRepository(e2ePool)
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("seed customer: %v", err)
+	}
+	detail, err := repo.GetUserDetail(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("seed detail: %v", err)
+	}
+
+	s := seed{nationalityID: nationalityID, userID: user.UserID}
+	for _, family := range detail.Families {
+		s.familyIDs = append(s.familyIDs, family.FamilyID)
+	}
+	return s
+}
+
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (r apiResponse) decode(t *testing.T, v any) {
+	t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("decode %s: %v", r.body, err)
+	}
+}
+
+func call(t *testing.T, method, path string, body any) apiResponse {
+	t.Helper()
+	return callWithHeader(t, method, path, body, nil)
+}
+
+// callWithHeader is call with extra request headers.
+func callWithHeader(t *testing.T, method, path string, body any, header http.Header) apiResponse {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		payload, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, e2eServer.URL+path, reader)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := e2eServer.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return apiResponse{status: resp.StatusCode, header: resp.Header, body: respBody}
+}
+
+func userPath(id int) string {
+	return "/api/v1/user/" + strconv.Itoa(id)
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	requireE2E(t)
+
+	if resp := call(t, http.MethodGet, "/healthz", nil); resp.status != http.StatusOK {
+		t.Fatalf("/healthz status = %d", resp.status)
+	}
+
+	resp := call(t, http.MethodGet, "/readyz", nil)
+	if resp.status != http.StatusOK {
+		t.Fatalf("/readyz status = %d: %s", resp.status, resp.body)
+	}
+	var report struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	resp.decode(t, &report)
+	for _, name := range []string{"database", "migrations"} {
+		if report.Checks[name].Status != "ok" {
+			t.Fatalf("check %s = %+v", name, report.Checks[name])
+		}
+	}
+}
+
+func TestUserRoutes(t *testing.T) {
+	requireE2E(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       func(s seed) string
+		body       func(s seed) any
+		wantStatus int
+		check      func(t *testing.T, s seed, resp apiResponse)
+	}{
+		{
+			name:   "create customer with family",
+			method: http.MethodPost,
+			path:   func(seed) string { return "/api/v1/user" },
+			body: func(s seed) any {
+				return model.User{Name: "Created Customer", Dob: "1985-12-01", NationalityID: s.nationalityID,
+					Families: []model.Family{{Name: "Created Child", Dob: "2015-01-01"}}}
+			},
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				var count int
+				e2ePool.QueryRow(context.Background(),
+					`SELECT count(*) FROM family_list fl JOIN customer c ON c.customer_id = fl.cst_id WHERE c.cst_name = 'Created Customer'`,
+				).Scan(&count)
+				if count != 1 {
+					t.Fatalf("family rows = %d, want 1", count)
+				}
+			},
+		},
+		{
+			name:       "create rejects malformed json",
+			method:     http.MethodPost,
+			path:       func(seed) string { return "/api/v1/user" },
+			body:       func(seed) any { return `{"name":` },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create rejects invalid customer with request id",
+			method: http.MethodPost,
+			path:   func(seed) string { return "/api/v1/user" },
+			body: func(s seed) any {
+				return model.User{Name: "Bob", Dob: "1985-12-01", NationalityID: s.nationalityID}
+			},
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				var body map[string]string
+				resp.decode(t, &body)
+				if body["error"] == "" || body["request_id"] != resp.header.Get("X-Request-ID") {
+					t.Fatalf("error body = %v, X-Request-ID = %q", body, resp.header.Get("X-Request-ID"))
+				}
+			},
+		},
+		{
+			name:       "list customers",
+			method:     http.MethodGet,
+			path:       func(seed) string { return "/api/v1/user" },
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				var users []model.UserDetailResponse
+				resp.decode(t, &users)
+				for _, user := range users {
+					if user.UserID == s.userID {
+						return
+					}
+				}
+				t.Fatalf("seeded customer %d missing from %s", s.userID, resp.body)
+			},
+		},
+		{
+			name:       "customer detail",
+			method:     http.MethodGet,
+			path:       func(s seed) string { return userPath(s.userID) },
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				var user model.UserDetailResponse
+				resp.decode(t, &user)
+				if user.Name != "Seeded Customer" || user.Nationality.NationalityCode != "ID" || len(user.Families) != 2 {
+					t.Fatalf("unexpected detail: %+v", user)
+				}
+			},
+		},
+		{
+			name:       "customer detail with invalid id",
+			method:     http.MethodGet,
+			path:       func(seed) string { return "/api/v1/user/abc" },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "customer detail not found",
+			method:     http.MethodGet,
+			path:       func(seed) string { return userPath(999999) },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "update customer and upsert family",
+			method: http.MethodPut,
+			path:   func(s seed) string { return userPath(s.userID) },
+			body: func(s seed) any {
+				return model.User{Name: "Updated Customer", Dob: "1990-05-16", NationalityID: s.nationalityID,
+					Families: []model.Family{
+						{FamilyID: s.familyIDs[0], UserID: s.userID, Name: "Renamed Child", Dob: "2012-03-05"},
+						{UserID: s.userID, Name: "Newborn Child", Dob: "2024-01-01"},
+					}}
+			},
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				detail := call(t, http.MethodGet, userPath(s.userID), nil)
+				var user model.UserDetailResponse
+				detail.decode(t, &user)
+				if user.Name != "Updated Customer" || len(user.Families) != 3 || user.Families[0].Name != "Renamed Child" {
+					t.Fatalf("unexpected detail after update: %+v", user)
+				}
+			},
+		},
+		{
+			name:   "update rejects invalid family",
+			method: http.MethodPut,
+			path:   func(s seed) string { return userPath(s.userID) },
+			body: func(s seed) any {
+				return model.User{Name: "Updated Customer", Dob: "1990-05-16", NationalityID: s.nationalityID,
+					Families: []model.Family{{Name: "No Owner Child", Dob: "2012-03-05"}}}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update with invalid id",
+			method:     http.MethodPut,
+			path:       func(seed) string { return "/api/v1/user/abc" },
+			body:       func(seed) any { return model.User{} },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "delete family member",
+			method:     http.MethodDelete,
+			path:       func(s seed) string { return fmt.Sprintf("%s/family/%d", userPath(s.userID), s.familyIDs[1]) },
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				var user model.UserDetailResponse
+				call(t, http.MethodGet, userPath(s.userID), nil).decode(t, &user)
+				if len(user.Families) != 1 || user.Families[0].FamilyID != s.familyIDs[0] {
+					t.Fatalf("families after delete = %+v", user.Families)
+				}
+			},
+		},
+		{
+			name:       "delete family with invalid id",
+			method:     http.MethodDelete,
+			path:       func(s seed) string { return userPath(s.userID) + "/family/abc" },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "delete customer",
+			method:     http.MethodDelete,
+			path:       func(s seed) string { return userPath(s.userID) },
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, s seed, resp apiResponse) {
+				if detail := call(t, http.MethodGet, userPath(s.userID), nil); detail.status != http.StatusBadRequest {
+					t.Fatalf("detail after delete status = %d", detail.status)
+				}
+				var families int
+				e2ePool.QueryRow(context.Background(), `SELECT count(*) FROM family_list WHERE cst_id = $1`, s.userID).Scan(&families)
+				if families != 0 {
+					t.Fatalf("family rows after delete = %d", families)
+				}
+			},
+		},
+		{
+			name:       "delete customer with invalid id",
+			method:     http.MethodDelete,
+			path:       func(seed) string { return "/api/v1/user/abc" },
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := seedCustomer(t, "Seeded Customer", "Seeded Child", "Seeded Spouse")
+
+			var body any
+			if tt.body != nil {
+				body = tt.body(s)
+			}
+			resp := call(t, tt.method, tt.path(s), body)
+			if resp.status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", resp.status, tt.wantStatus, resp.body)
+			}
+			if tt.check != nil {
+				tt.check(t, s, resp)
+			}
+		})
+	}
+}
+
+func TestUserContactRoutes(t *testing.T) {
+	requireE2E(t)
+	s := seedCustomer(t, "Contact Seed")
+	email := fmt.Sprintf("contact-%d@example.com", os.Getpid())
+
+	resp := call(t, http.MethodPost, "/api/v1/user", model.User{
+		Name: "Contact Customer", Dob: "1985-12-01", NationalityID: s.nationalityID,
+		Email: email, Phone: "0812-3456-7890",
+		Address: &model.Address{Line1: "Jl. Sudirman 1", City: "Jakarta", PostalCode: "10220", CountryCode: "ID"},
+	})
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", resp.status, resp.body)
+	}
+
+	var found []model.UserDetailResponse
+	call(t, http.MethodGet, "/api/v1/user?email="+strings.ToUpper(email), nil).decode(t, &found)
+	if len(found) != 1 || found[0].Phone != "+6281234567890" || found[0].Address == nil || found[0].Address.City != "Jakarta" {
+		t.Fatalf("search by email = %+v", found)
+	}
+	customer := found[0]
+
+	call(t, http.MethodGet, "/api/v1/user?phone=%2B62812-3456-7890", nil).decode(t, &found)
+	if len(found) != 1 || found[0].UserID != customer.UserID {
+		t.Fatalf("search by phone = %+v", found)
+	}
+
+	if resp := call(t, http.MethodGet, "/api/v1/user?phone=0812", nil); resp.status != http.StatusBadRequest {
+		t.Fatalf("search by national phone: status = %d, want 400", resp.status)
+	}
+
+	duplicate := model.User{Name: "Duplicate Customer", Dob: "1985-12-01", NationalityID: s.nationalityID, Email: strings.ToUpper(email)}
+	if resp := call(t, http.MethodPost, "/api/v1/user", duplicate); resp.status != http.StatusConflict {
+		t.Fatalf("create with a used email: status = %d, want 409: %s", resp.status, resp.body)
+	}
+	if resp := call(t, http.MethodPut, userPath(s.userID), duplicate); resp.status != http.StatusConflict {
+		t.Fatalf("update to a used email: status = %d, want 409: %s", resp.status, resp.body)
+	}
+
+	invalid := model.User{Name: "Invalid Contact", Dob: "1985-12-01", NationalityID: s.nationalityID, Email: "not-an-email"}
+	if resp := call(t, http.MethodPost, "/api/v1/user", invalid); resp.status != http.StatusBadRequest {
+		t.Fatalf("create with an invalid email: status = %d, want 400", resp.status)
+	}
+}
+
+func TestMinorsNeedAGuardianToBook(t *testing.T) {
+	requireE2E(t)
+	s := seedCustomer(t, "Guardian Seed")
+	trip := createTrip(t, 5)
+	email := fmt.Sprintf("guardian-%d@example.com", os.Getpid())
+
+	resp := call(t, http.MethodPost, "/api/v1/user", model.User{
+		Name: "Guardian Customer", Dob: "1980-01-01", NationalityID: s.nationalityID, Email: email,
+		EmergencyContacts: []model.EmergencyContact{{Name: "Siti Aminah", Relationship: "sister", Phone: "0812-3456-7890"}},
+		Families:          []model.Family{{Name: "Unguarded Child", Dob: "2015-01-01"}},
+	})
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", resp.status, resp.body)
+	}
+	var found []model.UserDetailResponse
+	call(t, http.MethodGet, "/api/v1/user?email="+email, nil).decode(t, &found)
+	if len(found) != 1 || len(found[0].Families) != 1 || len(found[0].EmergencyContacts) != 1 ||
+		found[0].EmergencyContacts[0].Phone != "+6281234567890" {
+		t.Fatalf("created customer = %+v", found)
+	}
+	customer, child := found[0], found[0].Families[0]
+
+	request := model.BookingRequest{UserID: customer.UserID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: []int{child.FamilyID}}
+	if resp := call(t, http.MethodPost, "/api/v1/booking", request); resp.status != http.StatusConflict {
+		t.Fatalf("booking a minor without guardian: status = %d, want 409: %s", resp.status, resp.body)
+	}
+
+	child.Guardian = &model.Guardian{FamilyID: 0}
+	resp = call(t, http.MethodPut, userPath(customer.UserID), model.User{
+		Name: customer.Name, Dob: customer.Dob, NationalityID: customer.NationalityID, Email: customer.Email,
+		EmergencyContacts: customer.EmergencyContacts, Families: []model.Family{child},
+	})
+	if resp.status != http.StatusOK {
+		t.Fatalf("assign guardian status = %d: %s", resp.status, resp.body)
+	}
+	createBooking(t, request)
+}
+
+func TestCreateRollsBackWhenFamilyInsertFails(t *testing.T) {
+	requireE2E(t)
+	s := seedCustomer(t, "Rollback Seed")
+
+	resp := call(t, http.MethodPost, "/api/v1/user", model.User{
+		Name: "Rolled Back Customer", Dob: "1980-01-01", NationalityID: s.nationalityID,
+		Families: []model.Family{{Name: rollbackFamilyName, Dob: "2010-01-01"}},
+	})
+	if resp.status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", resp.status, http.StatusBadRequest, resp.body)
+	}
+
+	var count int
+	e2ePool.QueryRow(context.Background(), `SELECT count(*) FROM customer WHERE cst_name = 'Rolled Back Customer'`).Scan(&count)
+	if count != 0 {
+		t.Fatalf("customer rows = %d, want the insert rolled back", count)
+	}
+}
+
+func TestUpdateRollsBackWhenFamilyUpsertFails(t *testing.T) {
+	requireE2E(t)
+	s := seedCustomer(t, "Original Customer", "Original Child")
+
+	resp := call(t, http.MethodPut, userPath(s.userID), model.User{
+		Name: "Renamed Customer", Dob: "1980-01-01", NationalityID: s.nationalityID,
+		Families: []model.Family{
+			{FamilyID: s.familyIDs[0], UserID: s.userID, Name: "Renamed Child", Dob: "2012-03-05"},
+			{UserID: s.userID, Name: rollbackFamilyName, Dob: "2010-01-01"},
+		},
+	})
+	if resp.status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", resp.status, http.StatusBadRequest, resp.body)
+	}
+
+	var user model.UserDetailResponse
+	call(t, http.MethodGet, userPath(s.userID), nil).decode(t, &user)
+	if user.Name != "Original Customer" || len(user.Families) != 1 || user.Families[0].Name != "Original Child" {
+		t.Fatalf("update not rolled back: %+v", user)
+	}
+}
+
+func TestBookedCustomersCannotBeDeleted(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Booked Owner", "Booked Child", "Drafted Child")
+	trip := createTrip(t, 5)
+
+	held := createBooking(t, model.BookingRequest{UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true,
+		FamilyIDs: owner.familyIDs[:1]})
+	if resp := call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(held.BookingID)+"/hold", model.TransitionRequest{}); resp.status != http.StatusOK {
+		t.Fatalf("hold: status = %d: %s", resp.status, resp.body)
+	}
+	draft := createBooking(t, model.BookingRequest{UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true,
+		FamilyIDs: owner.familyIDs[1:]})
+
+	familyPath := func(familyID int) string { return userPath(owner.userID) + "/family/" + strconv.Itoa(familyID) }
+	if resp := call(t, http.MethodDelete, familyPath(owner.familyIDs[0]), nil); resp.status != http.StatusConflict {
+		t.Fatalf("delete booked family member: status = %d, want 409: %s", resp.status, resp.body)
+	}
+	if resp := call(t, http.MethodDelete, userPath(owner.userID), nil); resp.status != http.StatusConflict {
+		t.Fatalf("delete booked customer: status = %d, want 409: %s", resp.status, resp.body)
+	}
+
+	// a family member only on a draft simply leaves it
+	if resp := call(t, http.MethodDelete, familyPath(owner.familyIDs[1]), nil); resp.status != http.StatusOK {
+		t.Fatalf("delete drafted family member: status = %d: %s", resp.status, resp.body)
+	}
+	var current model.Booking
+	call(t, http.MethodGet, "/api/v1/booking/"+strconv.Itoa(draft.BookingID), nil).decode(t, &current)
+	if len(current.Passengers) != 1 || !current.Passengers[0].IsCustomer {
+		t.Fatalf("draft passengers = %+v, want the customer only", current.Passengers)
+	}
+
+	var available int
+	e2ePool.QueryRow(context.Background(), `SELECT available FROM trip WHERE trip_id = $1`, trip.TripID).Scan(&available)
+	if available != 3 {
+		t.Fatalf("available = %d, want the held seats still taken", available)
+	}
+}
+
+func TestBookingRoutes(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Booking Owner", "Owner Child", "Owner Spouse")
+	other := seedCustomer(t, "Other Customer", "Other Child")
+
+	resp := call(t, http.MethodPost, "/api/v1/booking", model.BookingRequest{
+		UserID: owner.userID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+	})
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", resp.status, resp.body)
+	}
+	var booking model.Booking
+	resp.decode(t, &booking)
+	if len(booking.Passengers) != 3 || !booking.Passengers[0].IsCustomer || booking.Passengers[0].Name != "Booking Owner" {
+		t.Fatalf("unexpected booking: %+v", booking)
+	}
+	bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+
+	t.Run("family member of another customer is rejected", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/v1/booking", model.BookingRequest{
+			UserID: owner.userID, FamilyIDs: []int{owner.familyIDs[0], other.familyIDs[0]},
+		})
+		if resp.status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400: %s", resp.status, resp.body)
+		}
+
+		resp = call(t, http.MethodPut, bookingPath+"/passengers", model.BookingRequest{FamilyIDs: other.familyIDs})
+		if resp.status != http.StatusBadRequest {
+			t.Fatalf("replace status = %d, want 400: %s", resp.status, resp.body)
+		}
+	})
+
+	t.Run("schema refuses a foreign passenger", func(t *testing.T) {
+		_, err := e2ePool.Exec(context.Background(),
+			`INSERT INTO booking_passenger (booking_id, cst_id, fl_id) VALUES ($1, $2, $3)`,
+			booking.BookingID, owner.userID, other.familyIDs[0])
+		if err == nil {
+			t.Fatal("inserted a family member of another customer")
+		}
+	})
+
+	t.Run("booked family member cannot be moved to another customer", func(t *testing.T) {
+		resp := call(t, http.MethodPut, userPath(other.userID), model.User{
+			Name: "Other Customer", Dob: "1990-05-15", NationalityID: other.nationalityID,
+			Families: []model.Family{{FamilyID: owner.familyIDs[0], UserID: other.userID, Name: "Stolen Child", Dob: "2012-03-04"}},
+		})
+		if resp.status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400: %s", resp.status, resp.body)
+		}
+	})
+
+	t.Run("replace passengers", func(t *testing.T) {
+		resp := call(t, http.MethodPut, bookingPath+"/passengers", model.BookingRequest{FamilyIDs: owner.familyIDs[:1]})
+		if resp.status != http.StatusOK {
+			t.Fatalf("status = %d: %s", resp.status, resp.body)
+		}
+		var updated model.Booking
+		resp.decode(t, &updated)
+		if len(updated.Passengers) != 1 || updated.Passengers[0].FamilyID != owner.familyIDs[0] || updated.UpdatedAt == nil {
+			t.Fatalf("unexpected booking: %+v", updated)
+		}
+	})
+
+	t.Run("list by customer", func(t *testing.T) {
+		var bookings []model.Booking
+		call(t, http.MethodGet, "/api/v1/booking?user_id="+strconv.Itoa(owner.userID), nil).decode(t, &bookings)
+		if len(bookings) != 1 || bookings[0].BookingID != booking.BookingID {
+			t.Fatalf("bookings = %+v", bookings)
+		}
+	})
+
+	t.Run("missing booking", func(t *testing.T) {
+		if resp := call(t, http.MethodGet, "/api/v1/booking/999999", nil); resp.status != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", resp.status)
+		}
+	})
+
+	t.Run("deleting the customer removes the booking", func(t *testing.T) {
+		if resp := call(t, http.MethodDelete, userPath(owner.userID), nil); resp.status != http.StatusOK {
+			t.Fatalf("delete customer status = %d", resp.status)
+		}
+		if resp := call(t, http.MethodGet, bookingPath, nil); resp.status != http.StatusNotFound {
+			t.Fatalf("booking status = %d, want 404", resp.status)
+		}
+	})
+}
+
+func TestBookingLifecycle(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Lifecycle Owner", "Lifecycle Child")
+
+	trip := createTrip(t, 10)
+
+	var booking model.Booking
+	call(t, http.MethodPost, "/api/v1/booking", model.BookingRequest{UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true}).decode(t, &booking)
+	bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+	if booking.Status != model.BookingDraft {
+		t.Fatalf("new booking status = %s", booking.Status)
+	}
+
+	steps := []struct {
+		action     string
+		wantStatus int
+		wantState  model.BookingStatus
+	}{
+		{"confirm", http.StatusConflict, model.BookingDraft},
+		{"hold", http.StatusOK, model.BookingHeld},
+		{"hold", http.StatusConflict, model.BookingHeld},
+		{"confirm", http.StatusConflict, model.BookingHeld},
+		{"pay", http.StatusOK, model.BookingConfirmed},
+		{"release", http.StatusConflict, model.BookingConfirmed},
+		{"ticket", http.StatusOK, model.BookingTicketed},
+		{"refund", http.StatusNotFound, model.BookingTicketed},
+		{"cancel", http.StatusConflict, model.BookingTicketed},
+		{"cancellation", http.StatusOK, model.BookingCancelled},
+		{"refund", http.StatusNotFound, model.BookingCancelled},
+		{"cancel", http.StatusConflict, model.BookingCancelled},
+	}
+	for _, step := range steps {
+		var resp apiResponse
+		switch step.action {
+		case "pay":
+			payBooking(t, booking.BookingID)
+			resp.status = http.StatusOK
+		case "cancellation":
+			// paid bookings are cancelled through the refund policy; a
+			// standard fare the day before departure refunds nothing
+			resp = call(t, http.MethodPost, bookingPath+"/cancellation", model.CancellationRequest{Reason: "e2e cancellation"})
+		default:
+			resp = call(t, http.MethodPost, bookingPath+"/"+step.action, model.TransitionRequest{Reason: "e2e " + step.action})
+		}
+		if resp.status != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", step.action, resp.status, step.wantStatus, resp.body)
+		}
+
+		var current model.Booking
+		call(t, http.MethodGet, bookingPath, nil).decode(t, &current)
+		if current.Status != step.wantState {
+			t.Fatalf("after %s: status = %s, want %s", step.action, current.Status, step.wantState)
+		}
+	}
+
+	var current model.Booking
+	call(t, http.MethodGet, bookingPath, nil).decode(t, &current)
+	for name, stamp := range map[string]any{
+		"held_at": current.HeldAt, "confirmed_at": current.ConfirmedAt, "ticketed_at": current.TicketedAt,
+		"cancelled_at": current.CancelledAt,
+	} {
+		if stamp == nil {
+			t.Errorf("%s not recorded", name)
+		}
+	}
+	if current.RefundedAt != nil {
+		t.Errorf("refunded_at recorded without a refund")
+	}
+
+	var history []model.BookingStatusChange
+	call(t, http.MethodGet, bookingPath+"/history", nil).decode(t, &history)
+	want := []model.BookingStatus{model.BookingDraft, model.BookingHeld, model.BookingConfirmed,
+		model.BookingTicketed, model.BookingCancelled}
+	if len(history) != len(want) {
+		t.Fatalf("history = %+v", history)
+	}
+	for i, change := range history {
+		if change.ToStatus != want[i] || (i > 0 && change.FromStatus != want[i-1]) {
+			t.Fatalf("history[%d] = %+v", i, change)
+		}
+	}
+
+	if resp := call(t, http.MethodPut, bookingPath+"/passengers", model.BookingRequest{IncludeCustomer: true}); resp.status != http.StatusConflict {
+		t.Fatalf("editing a cancelled booking: status = %d, want 409", resp.status)
+	}
+	if resp := call(t, http.MethodDelete, bookingPath, nil); resp.status != http.StatusConflict {
+		t.Fatalf("deleting a cancelled booking: status = %d, want 409", resp.status)
+	}
+}
+
+var tripSeq atomic.Int64
+
+// createTrip creates a trip departing tomorrow with an adult fare of
+// 1,000,000 through the API.
+func createTrip(t *testing.T, capacity int) model.Trip {
+	t.Helper()
+	resp := call(t, http.MethodPost, "/api/v1/trip", model.Trip{
+		TripCode:    fmt.Sprintf("E2E-%d-%d", os.Getpid()%10000, tripSeq.Add(1)),
+		Origin:      "Jakarta",
+		Destination: "Denpasar",
+		DepartureAt: time.Now().Add(24 * time.Hour),
+		Capacity:    capacity,
+		Fare:        1_000_000,
+	})
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create trip status = %d: %s", resp.status, resp.body)
+	}
+	var trip model.Trip
+	resp.decode(t, &trip)
+	return trip
+}
+
+func tripAvailable(t *testing.T, tripID int) int {
+	t.Helper()
+	var trip model.Trip
+	call(t, http.MethodGet, "/api/v1/trip/"+strconv.Itoa(tripID), nil).decode(t, &trip)
+	return trip.Available
+}
+
+func TestDuplicateTripCodeConflicts(t *testing.T) {
+	requireE2E(t)
+	trip := createTrip(t, 3)
+
+	duplicate := trip
+	duplicate.TripID = 0
+	resp := call(t, http.MethodPost, "/api/v1/trip", duplicate)
+	if resp.status != http.StatusConflict {
+		t.Fatalf("duplicate trip code: status = %d, want 409: %s", resp.status, resp.body)
+	}
+}
+
+func createBooking(t *testing.T, request model.BookingRequest) model.Booking {
+	t.Helper()
+	resp := call(t, http.MethodPost, "/api/v1/booking", request)
+	if resp.status != http.StatusCreated {
+		t.Fatalf("create booking status = %d: %s", resp.status, resp.body)
+	}
+	var booking model.Booking
+	resp.decode(t, &booking)
+	return booking
+}
+
+func TestConcurrentHoldsNeverOversell(t *testing.T) {
+	requireE2E(t)
+
+	tests := []struct {
+		name       string
+		capacity   int
+		bookings   int
+		families   int
+		wantHeld   int
+		wantRemain int
+	}{
+		{name: "one seat per booking", capacity: 5, bookings: 20, wantHeld: 5, wantRemain: 0},
+		{name: "three seats per booking", capacity: 10, bookings: 12, families: 2, wantHeld: 3, wantRemain: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := createTrip(t, tt.capacity)
+
+			familyNames := []string{}
+			for i := 0; i < tt.families; i++ {
+				familyNames = append(familyNames, fmt.Sprintf("Family Member %d", i+1))
+			}
+			paths := make([]string, tt.bookings)
+			for i := range paths {
+				owner := seedCustomer(t, fmt.Sprintf("Racing Customer %d", i), familyNames...)
+				booking := createBooking(t, model.BookingRequest{
+					UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+				})
+				paths[i] = "/api/v1/booking/" + strconv.Itoa(booking.BookingID) + "/hold"
+			}
+
+			// call fails the test with t.Fatalf, which only the test
+			// goroutine may do, so the racers report errors back instead
+			var (
+				wg            sync.WaitGroup
+				held, soldOut atomic.Int64
+				start         = make(chan struct{})
+				errs          = make(chan error, len(paths))
+			)
+			for _, path := range paths {
+				wg.Add(1)
+				go func(path string) {
+					defer wg.Done()
+					<-start
+					resp, err := e2eServer.Client().Post(e2eServer.URL+path, "application/json", nil)
+					if err != nil {
+						errs <- err
+						return
+					}
+					resp.Body.Close()
+					switch resp.StatusCode {
+					case http.StatusOK:
+						held.Add(1)
+					case http.StatusConflict:
+						soldOut.Add(1)
+					default:
+						errs <- fmt.Errorf("unexpected hold status %d", resp.StatusCode)
+					}
+				}(path)
+			}
+			close(start)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Errorf("POST hold: %v", err)
+			}
+			if held.Load() != int64(tt.wantHeld) || soldOut.Load() != int64(tt.bookings-tt.wantHeld) {
+				t.Fatalf("held = %d, sold out = %d, want %d held", held.Load(), soldOut.Load(), tt.wantHeld)
+			}
+			if available := tripAvailable(t, trip.TripID); available != tt.wantRemain {
+				t.Fatalf("available = %d, want %d", available, tt.wantRemain)
+			}
+		})
+	}
+}
+
+func TestSeatHoldLifecycle(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Holding Customer", "Holding Child")
+	trip := createTrip(t, 4)
+
+	booking := createBooking(t, model.BookingRequest{
+		UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+	})
+	bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+
+	expire := func() {
+		t.Helper()
+		_, err := e2ePool.Exec(context.Background(),
+			`UPDATE seat_hold SET expires_at = CURRENT_TIMESTAMP - interval '1 second' WHERE booking_id = $1 AND status = 'active'`,
+			booking.BookingID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var held model.Booking
+	call(t, http.MethodPost, bookingPath+"/hold", nil).decode(t, &held)
+	if held.Status != model.BookingHeld || held.HoldExpiresAt == nil || tripAvailable(t, trip.TripID) != 2 {
+		t.Fatalf("after hold: %+v, available %d", held, tripAvailable(t, trip.TripID))
+	}
+
+	t.Run("expired hold cannot be confirmed", func(t *testing.T) {
+		expire()
+		if resp := call(t, http.MethodPost, bookingPath+"/confirm", nil); resp.status != http.StatusConflict {
+			t.Fatalf("confirm status = %d, want 409: %s", resp.status, resp.body)
+		}
+	})
+
+	t.Run("reaper releases expired holds", func(t *testing.T) {
+		released, err := e2eApp.Bookings.ReleaseExpiredHolds(context.Background())
+		if err != nil || released < 1 {
+			t.Fatalf("released = %d, err = %v", released, err)
+		}
+
+		var current model.Booking
+		call(t, http.MethodGet, bookingPath, nil).decode(t, &current)
+		if current.Status != model.BookingDraft || current.HoldExpiresAt != nil {
+			t.Fatalf("after reaping: %+v", current)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 4 {
+			t.Fatalf("available = %d, want 4", available)
+		}
+
+		var history []model.BookingStatusChange
+		call(t, http.MethodGet, bookingPath+"/history", nil).decode(t, &history)
+		if last := history[len(history)-1]; last.ToStatus != model.BookingDraft || last.Reason != "hold expired" {
+			t.Fatalf("last history entry = %+v", last)
+		}
+	})
+
+	t.Run("confirmed seats stay taken until cancelled", func(t *testing.T) {
+		if resp := call(t, http.MethodPost, bookingPath+"/hold", nil); resp.status != http.StatusOK {
+			t.Fatalf("hold status = %d: %s", resp.status, resp.body)
+		}
+		payBooking(t, booking.BookingID)
+		expire()
+		if released, _ := e2eApp.Bookings.ReleaseExpiredHolds(context.Background()); released != 0 {
+			t.Fatalf("reaper released %d confirmed holds", released)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 2 {
+			t.Fatalf("available = %d, want 2", available)
+		}
+
+		if resp := call(t, http.MethodPost, bookingPath+"/cancellation", nil); resp.status != http.StatusOK {
+			t.Fatalf("cancel status = %d: %s", resp.status, resp.body)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 4 {
+			t.Fatalf("available after cancel = %d, want 4", available)
+		}
+	})
+
+	t.Run("booking without a trip cannot be held", func(t *testing.T) {
+		tripless := createBooking(t, model.BookingRequest{UserID: owner.userID, IncludeCustomer: true})
+		resp := call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(tripless.BookingID)+"/hold", nil)
+		if resp.status != http.StatusConflict {
+			t.Fatalf("status = %d, want 409", resp.status)
+		}
+	})
+}
+
+func TestQuoteRoutes(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Quoting Customer", "Quoting Child", "Quoting Infant")
+	other := seedCustomer(t, "Other Quoting Customer", "Other Child")
+	trip := createTrip(t, 10)
+
+	// ages are taken on the departure date, tomorrow
+	for i, dob := range []time.Time{time.Now().AddDate(-5, 0, 0), time.Now().AddDate(0, -6, 0)} {
+		_, err := e2ePool.Exec(context.Background(), `UPDATE family_list SET fl_dob = $1 WHERE fl_id = $2`,
+			dob.Format("2006-01-02"), owner.familyIDs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		request    model.BookingRequest
+		wantStatus int
+	}{
+		{"missing trip", model.BookingRequest{UserID: owner.userID, IncludeCustomer: true}, http.StatusBadRequest},
+		{"unknown trip", model.BookingRequest{UserID: owner.userID, TripID: 999999, IncludeCustomer: true}, http.StatusBadRequest},
+		{"no passengers", model.BookingRequest{UserID: owner.userID, TripID: trip.TripID}, http.StatusBadRequest},
+		{"family of another customer", model.BookingRequest{UserID: owner.userID, TripID: trip.TripID, FamilyIDs: other.familyIDs}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := call(t, http.MethodPost, "/api/v1/quote", tt.request); resp.status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", resp.status, tt.wantStatus, resp.body)
+			}
+		})
+	}
+
+	t.Run("itemized by age band", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/v1/quote", model.BookingRequest{
+			UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+		})
+		if resp.status != http.StatusOK {
+			t.Fatalf("status = %d: %s", resp.status, resp.body)
+		}
+		var quote model.Quote
+		resp.decode(t, &quote)
+
+		wantBands := []model.AgeBand{model.AgeBandAdult, model.AgeBandChild, model.AgeBandInfant}
+		if len(quote.Items) != len(wantBands) {
+			t.Fatalf("items = %+v", quote.Items)
+		}
+		for i, item := range quote.Items {
+			if item.Band != wantBands[i] {
+				t.Errorf("%s: band = %s, want %s", item.Name, item.Band, wantBands[i])
+			}
+		}
+		if quote.Subtotal != 1_850_000 || quote.Total != 1_850_000 || quote.Currency != "IDR" {
+			t.Fatalf("quote = %+v", quote)
+		}
+	})
+}
+
+// payBooking pays a held booking in full through the fake gateway, which
+// confirms it.
+func payBooking(t *testing.T, bookingID int) model.Payment {
+	t.Helper()
+	payment := startPayment(t, bookingID)
+	return settlePayment(t, payment.PaymentID, model.PaymentSucceeded)
+}
+
+func startPayment(t *testing.T, bookingID int) model.Payment {
+	t.Helper()
+	resp := call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(bookingID)+"/payments", nil)
+	if resp.status != http.StatusCreated {
+		t.Fatalf("pay booking %d: status = %d: %s", bookingID, resp.status, resp.body)
+	}
+	var payment model.Payment
+	resp.decode(t, &payment)
+	return payment
+}
+
+func settlePayment(t *testing.T, paymentID int, status model.PaymentStatus) model.Payment {
+	t.Helper()
+	resp := call(t, http.MethodPost, "/api/v1/payment/"+strconv.Itoa(paymentID)+"/simulate", model.SimulatePaymentRequest{Status: status})
+	if resp.status != http.StatusOK {
+		t.Fatalf("simulate payment %d: status = %d: %s", paymentID, resp.status, resp.body)
+	}
+	var payment model.Payment
+	resp.decode(t, &payment)
+	return payment
+}
+
+func bookingStatus(t *testing.T, bookingID int) model.BookingStatus {
+	t.Helper()
+	var booking model.Booking
+	call(t, http.MethodGet, "/api/v1/booking/"+strconv.Itoa(bookingID), nil).decode(t, &booking)
+	return booking.Status
+}
+
+func TestPaymentRoutes(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Paying Customer", "Paying Partner")
+
+	expireHold := func(t *testing.T, bookingID int) {
+		t.Helper()
+		_, err := e2ePool.Exec(context.Background(),
+			`UPDATE seat_hold SET expires_at = CURRENT_TIMESTAMP - interval '1 second' WHERE booking_id = $1 AND status = 'active'`,
+			bookingID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	heldBooking := func(t *testing.T, trip model.Trip) model.Booking {
+		t.Helper()
+		booking := createBooking(t, model.BookingRequest{
+			UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+		})
+		if resp := call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(booking.BookingID)+"/hold", nil); resp.status != http.StatusOK {
+			t.Fatalf("hold status = %d: %s", resp.status, resp.body)
+		}
+		return booking
+	}
+
+	t.Run("pay, fail and retry", func(t *testing.T) {
+		trip := createTrip(t, 10)
+		booking := createBooking(t, model.BookingRequest{UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs})
+		paymentsPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID) + "/payments"
+
+		if resp := call(t, http.MethodPost, paymentsPath, nil); resp.status != http.StatusConflict {
+			t.Fatalf("paying a draft booking: status = %d, want 409", resp.status)
+		}
+		call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(booking.BookingID)+"/hold", nil)
+
+		first := startPayment(t, booking.BookingID)
+		if first.Status != model.PaymentPending || first.Amount != 2_000_000 || first.ClientSecret == "" {
+			t.Fatalf("payment = %+v", first)
+		}
+		if again := startPayment(t, booking.BookingID); again.PaymentID != first.PaymentID || again.ClientSecret != first.ClientSecret {
+			t.Fatalf("second request started payment %d, want %d again", again.PaymentID, first.PaymentID)
+		}
+		if resp := call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(booking.BookingID)+"/confirm", nil); resp.status != http.StatusConflict {
+			t.Fatalf("confirm before paying: status = %d, want 409", resp.status)
+		}
+
+		if failed := settlePayment(t, first.PaymentID, model.PaymentFailed); failed.Status != model.PaymentFailed {
+			t.Fatalf("failed payment = %+v", failed)
+		}
+		if status := bookingStatus(t, booking.BookingID); status != model.BookingHeld {
+			t.Fatalf("after a failed payment booking is %s", status)
+		}
+
+		second := payBooking(t, booking.BookingID)
+		if second.PaymentID == first.PaymentID || second.Status != model.PaymentSucceeded || second.PaidAt == nil {
+			t.Fatalf("retried payment = %+v", second)
+		}
+		if status := bookingStatus(t, booking.BookingID); status != model.BookingConfirmed {
+			t.Fatalf("after paying booking is %s", status)
+		}
+		if resp := call(t, http.MethodPost, paymentsPath, nil); resp.status != http.StatusConflict {
+			t.Fatalf("paying twice: status = %d, want 409", resp.status)
+		}
+
+		var payments []model.Payment
+		call(t, http.MethodGet, paymentsPath, nil).decode(t, &payments)
+		if len(payments) != 2 || payments[0].Status != model.PaymentFailed || payments[1].Status != model.PaymentSucceeded {
+			t.Fatalf("payments = %+v", payments)
+		}
+	})
+
+	t.Run("forged webhook", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/v1/payment/webhook", model.PaymentEvent{EventID: "evt_forged", Status: model.PaymentSucceeded})
+		if resp.status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", resp.status)
+		}
+	})
+
+	t.Run("late payment holds the seats again", func(t *testing.T) {
+		trip := createTrip(t, 10)
+		booking := heldBooking(t, trip)
+		payment := startPayment(t, booking.BookingID)
+		expireHold(t, booking.BookingID)
+
+		settlePayment(t, payment.PaymentID, model.PaymentSucceeded)
+		if status := bookingStatus(t, booking.BookingID); status != model.BookingConfirmed {
+			t.Fatalf("booking is %s, want confirmed", status)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 8 {
+			t.Fatalf("available = %d, want 8", available)
+		}
+	})
+
+	t.Run("late payment for sold out seats is refunded", func(t *testing.T) {
+		trip := createTrip(t, 2)
+		late := heldBooking(t, trip)
+		payment := startPayment(t, late.BookingID)
+		expireHold(t, late.BookingID)
+		if _, err := e2eApp.Bookings.ReleaseExpiredHolds(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		heldBooking(t, trip)
+
+		if settled := settlePayment(t, payment.PaymentID, model.PaymentSucceeded); settled.Status != model.PaymentRefunded {
+			t.Fatalf("payment = %+v, want refunded", settled)
+		}
+		if status := bookingStatus(t, late.BookingID); status != model.BookingDraft {
+			t.Fatalf("booking is %s, want draft", status)
+		}
+	})
+
+	t.Run("nothing to pay", func(t *testing.T) {
+		trip := createTrip(t, 10)
+		if _, err := e2ePool.Exec(context.Background(), `UPDATE trip SET fare = 0 WHERE trip_id = $1`, trip.TripID); err != nil {
+			t.Fatal(err)
+		}
+		booking := heldBooking(t, trip)
+
+		if payment := startPayment(t, booking.BookingID); payment.Status != model.PaymentSucceeded || payment.Amount != 0 {
+			t.Fatalf("payment = %+v", payment)
+		}
+		if status := bookingStatus(t, booking.BookingID); status != model.BookingConfirmed {
+			t.Fatalf("booking is %s, want confirmed", status)
+		}
+	})
+
+	t.Run("payment records outlive draft edits", func(t *testing.T) {
+		trip := createTrip(t, 10)
+		booking := heldBooking(t, trip)
+		bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+		settlePayment(t, startPayment(t, booking.BookingID).PaymentID, model.PaymentFailed)
+		if resp := call(t, http.MethodPost, bookingPath+"/release", nil); resp.status != http.StatusOK {
+			t.Fatalf("release: status = %d: %s", resp.status, resp.body)
+		}
+
+		if resp := call(t, http.MethodPut, bookingPath+"/passengers", model.BookingRequest{IncludeCustomer: true}); resp.status != http.StatusConflict {
+			t.Fatalf("removing a paid-for passenger: status = %d, want 409: %s", resp.status, resp.body)
+		}
+		if resp := call(t, http.MethodDelete, bookingPath, nil); resp.status != http.StatusConflict {
+			t.Fatalf("deleting a booking with payments: status = %d, want 409: %s", resp.status, resp.body)
+		}
+
+		before := bookingPassengers(t, booking.BookingID)
+		request := model.BookingRequest{IncludeCustomer: true, FamilyIDs: owner.familyIDs}
+		if resp := call(t, http.MethodPut, bookingPath+"/passengers", request); resp.status != http.StatusOK {
+			t.Fatalf("keeping every passenger: status = %d: %s", resp.status, resp.body)
+		}
+		if after := bookingPassengers(t, booking.BookingID); !slices.Equal(after, before) {
+			t.Fatalf("passenger IDs changed from %v to %v", before, after)
+		}
+
+		var payments []model.Payment
+		call(t, http.MethodGet, bookingPath+"/payments", nil).decode(t, &payments)
+		if len(payments) != 1 || payments[0].Status != model.PaymentFailed {
+			t.Fatalf("payments = %+v", payments)
+		}
+	})
+}
+
+// bookingPassengers returns the passenger IDs of a booking.
+func bookingPassengers(t *testing.T, bookingID int) []int {
+	t.Helper()
+	var booking model.Booking
+	call(t, http.MethodGet, "/api/v1/booking/"+strconv.Itoa(bookingID), nil).decode(t, &booking)
+	ids := []int{}
+	for _, passenger := range booking.Passengers {
+		ids = append(ids, passenger.PassengerID)
+	}
+	return ids
+}
+
+func TestCancellationRoutes(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Cancelling Customer", "Cancelling Partner")
+
+	// paidBooking books the customer and partner, both adults, on trip and
+	// pays 2,000,000 for them.
+	paidBooking := func(t *testing.T, trip model.Trip, class model.FareClass) model.Booking {
+		t.Helper()
+		booking := createBooking(t, model.BookingRequest{
+			UserID: owner.userID, TripID: trip.TripID, FareClass: class, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+		})
+		if resp := call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(booking.BookingID)+"/hold", nil); resp.status != http.StatusOK {
+			t.Fatalf("hold status = %d: %s", resp.status, resp.body)
+		}
+		payBooking(t, booking.BookingID)
+		call(t, http.MethodGet, "/api/v1/booking/"+strconv.Itoa(booking.BookingID), nil).decode(t, &booking)
+		return booking
+	}
+
+	t.Run("partial then full cancellation of a flex fare", func(t *testing.T) {
+		trip := createTrip(t, 10)
+		booking := paidBooking(t, trip, model.FareClassFlex)
+		bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+		partner := booking.Passengers[1].PassengerID
+
+		// departing tomorrow is less than a full day ahead: flex:any:0:50
+		var preview model.Cancellation
+		call(t, http.MethodGet, bookingPath+"/cancellation?passenger_ids="+strconv.Itoa(partner), nil).decode(t, &preview)
+		if preview.Status != model.BookingConfirmed || preview.RefundTotal != 500_000 || len(preview.Items) != 1 ||
+			preview.Items[0].Rule != "flex:any:0:50" || preview.Items[0].Paid != 1_000_000 {
+			t.Fatalf("preview = %+v", preview)
+		}
+
+		var partial model.Cancellation
+		resp := call(t, http.MethodPost, bookingPath+"/cancellation", model.CancellationRequest{PassengerIDs: []int{partner}, Reason: "plans changed"})
+		resp.decode(t, &partial)
+		if resp.status != http.StatusOK || partial.Status != model.BookingConfirmed ||
+			partial.Refund == nil || partial.Refund.Status != model.RefundSucceeded || partial.Refund.Amount != 500_000 {
+			t.Fatalf("partial cancellation: status = %d: %s", resp.status, resp.body)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 9 {
+			t.Fatalf("available = %d, want 9", available)
+		}
+		if resp := call(t, http.MethodPost, bookingPath+"/cancellation", model.CancellationRequest{PassengerIDs: []int{partner}}); resp.status != http.StatusConflict {
+			t.Fatalf("cancelling a passenger twice: status = %d, want 409", resp.status)
+		}
+
+		var full model.Cancellation
+		call(t, http.MethodPost, bookingPath+"/cancellation", model.CancellationRequest{Reason: "trip off"}).decode(t, &full)
+		if full.Status != model.BookingRefunded || len(full.Items) != 1 || full.RefundTotal != 500_000 {
+			t.Fatalf("full cancellation = %+v", full)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 10 {
+			t.Fatalf("available = %d, want 10", available)
+		}
+
+		var refunds []model.Refund
+		call(t, http.MethodGet, bookingPath+"/refunds", nil).decode(t, &refunds)
+		if len(refunds) != 2 || refunds[0].Items[0].PassengerID != partner || refunds[0].Items[0].Band != model.AgeBandAdult {
+			t.Fatalf("refunds = %+v", refunds)
+		}
+		for _, refund := range refunds {
+			if refund.Status != model.RefundSucceeded || refund.GatewayRefundID == "" {
+				t.Fatalf("refund = %+v", refund)
+			}
+		}
+	})
+
+	t.Run("standard fare the day before departure refunds nothing", func(t *testing.T) {
+		booking := paidBooking(t, createTrip(t, 10), model.FareClassStandard)
+
+		var cancellation model.Cancellation
+		call(t, http.MethodPost, "/api/v1/booking/"+strconv.Itoa(booking.BookingID)+"/cancellation", nil).decode(t, &cancellation)
+		if cancellation.Status != model.BookingCancelled || cancellation.RefundTotal != 0 || cancellation.Refund != nil {
+			t.Fatalf("cancellation = %+v", cancellation)
+		}
+	})
+
+	t.Run("unpaid bookings are cancelled whole", func(t *testing.T) {
+		trip := createTrip(t, 10)
+		booking := createBooking(t, model.BookingRequest{UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs})
+		bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+		call(t, http.MethodPost, bookingPath+"/hold", nil)
+
+		partial := model.CancellationRequest{PassengerIDs: []int{booking.Passengers[0].PassengerID}}
+		if resp := call(t, http.MethodPost, bookingPath+"/cancellation", partial); resp.status != http.StatusConflict {
+			t.Fatalf("partial cancellation of a held booking: status = %d, want 409", resp.status)
+		}
+		if resp := call(t, http.MethodPost, bookingPath+"/cancellation", nil); resp.status != http.StatusOK {
+			t.Fatalf("cancellation status = %d: %s", resp.status, resp.body)
+		}
+		if available := tripAvailable(t, trip.TripID); available != 10 {
+			t.Fatalf("available = %d, want 10", available)
+		}
+	})
+
+	t.Run("invalid fare class", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/v1/booking", model.BookingRequest{UserID: owner.userID, FareClass: "business", IncludeCustomer: true})
+		if resp.status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", resp.status)
+		}
+	})
+}
+
+func TestDocumentRoutes(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Travelling Customer", "Travelling Partner")
+	documentsPath := userPath(owner.userID) + "/documents"
+	nextYear := time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+
+	var created model.TravelDocument
+	t.Run("create and read", func(t *testing.T) {
+		resp := call(t, http.MethodPost, documentsPath, model.DocumentRequest{
+			Type: model.DocumentPassport, Number: "a12-345 678", IssuingNationalityID: owner.nationalityID, ExpiresOn: nextYear,
+		})
+		resp.decode(t, &created)
+		if resp.status != http.StatusCreated || created.Number != "A12345678" || created.NumberMasked != "****5678" ||
+			created.FamilyID != 0 || created.IssuingCountry.NationalityCode != "ID" || created.ExpiresOn != nextYear {
+			t.Fatalf("create status = %d: %s", resp.status, resp.body)
+		}
+
+		var stored []byte
+		err := e2ePool.QueryRow(context.Background(),
+			`SELECT number_encrypted FROM travel_document WHERE document_id = $1`, created.DocumentID).Scan(&stored)
+		if err != nil {
+			t.Fatalf("read stored number: %v", err)
+		}
+		if bytes.Contains(stored, []byte("A12345678")) {
+			t.Fatal("document number is stored in clear")
+		}
+
+		var documents []model.TravelDocument
+		call(t, http.MethodGet, documentsPath, nil).decode(t, &documents)
+		if len(documents) != 1 || documents[0].Number != "" || documents[0].NumberMasked != "****5678" {
+			t.Fatalf("documents = %+v", documents)
+		}
+	})
+
+	t.Run("update keeps the owner", func(t *testing.T) {
+		path := documentsPath + "/" + strconv.Itoa(created.DocumentID)
+		var updated model.TravelDocument
+		resp := call(t, http.MethodPut, path, model.DocumentRequest{
+			FamilyID: owner.familyIDs[0], Type: model.DocumentPassport, Number: "B7654321",
+			IssuingNationalityID: owner.nationalityID, ExpiresOn: nextYear,
+		})
+		resp.decode(t, &updated)
+		if resp.status != http.StatusOK || updated.Number != "B7654321" || updated.FamilyID != 0 || updated.UpdatedAt == nil {
+			t.Fatalf("update status = %d: %s", resp.status, resp.body)
+		}
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		invalid := []model.DocumentRequest{
+			{Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: owner.nationalityID, ExpiresOn: "2000-01-01"},
+			{Type: "visa", Number: "A1234567", IssuingNationalityID: owner.nationalityID, ExpiresOn: nextYear},
+			{Type: model.DocumentPassport, Number: "A12#4567", IssuingNationalityID: owner.nationalityID, ExpiresOn: nextYear},
+			{Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: 999999, ExpiresOn: nextYear},
+			{FamilyID: 999999, Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: owner.nationalityID, ExpiresOn: nextYear},
+		}
+		for _, request := range invalid {
+			if resp := call(t, http.MethodPost, documentsPath, request); resp.status != http.StatusBadRequest {
+				t.Errorf("%+v: status = %d, want 400: %s", request, resp.status, resp.body)
+			}
+		}
+		if resp := call(t, http.MethodGet, userPath(999999)+"/documents", nil); resp.status != http.StatusNotFound {
+			t.Errorf("unknown customer: status = %d, want 404", resp.status)
+		}
+	})
+
+	t.Run("international trips need passports", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/v1/trip", model.Trip{
+			TripCode:      fmt.Sprintf("E2E-%d-%d", os.Getpid()%10000, tripSeq.Add(1)),
+			Origin:        "Jakarta",
+			Destination:   "Singapore",
+			DepartureAt:   time.Now().Add(24 * time.Hour),
+			Capacity:      10,
+			Fare:          1_000_000,
+			International: true,
+		})
+		var trip model.Trip
+		resp.decode(t, &trip)
+		if resp.status != http.StatusCreated || !trip.International {
+			t.Fatalf("create trip status = %d: %s", resp.status, resp.body)
+		}
+
+		booking := createBooking(t, model.BookingRequest{
+			UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+		})
+		holdPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID) + "/hold"
+		if resp := call(t, http.MethodPost, holdPath, nil); resp.status != http.StatusConflict {
+			t.Fatalf("hold without the partner's passport: status = %d, want 409: %s", resp.status, resp.body)
+		}
+
+		resp = call(t, http.MethodPost, documentsPath, model.DocumentRequest{
+			FamilyID: owner.familyIDs[0], Type: model.DocumentPassport, Number: "C1234567",
+			IssuingNationalityID: owner.nationalityID, ExpiresOn: nextYear,
+		})
+		if resp.status != http.StatusCreated {
+			t.Fatalf("create partner passport status = %d: %s", resp.status, resp.body)
+		}
+		if resp := call(t, http.MethodPost, holdPath, nil); resp.status != http.StatusOK {
+			t.Fatalf("hold status = %d: %s", resp.status, resp.body)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		path := documentsPath + "/" + strconv.Itoa(created.DocumentID)
+		if resp := call(t, http.MethodDelete, path, nil); resp.status != http.StatusOK {
+			t.Fatalf("delete status = %d: %s", resp.status, resp.body)
+		}
+		if resp := call(t, http.MethodGet, path, nil); resp.status != http.StatusNotFound {
+			t.Fatalf("get deleted status = %d, want 404", resp.status)
+		}
+	})
+}
+
+func TestBookingConfirmation(t *testing.T) {
+	requireE2E(t)
+	owner := seedCustomer(t, "Confirmed Customer", "Confirmed Child")
+	trip := createTrip(t, 5)
+	booking := createBooking(t, model.BookingRequest{
+		UserID: owner.userID, TripID: trip.TripID, IncludeCustomer: true, FamilyIDs: owner.familyIDs,
+	})
+	bookingPath := "/api/v1/booking/" + strconv.Itoa(booking.BookingID)
+	if resp := call(t, http.MethodPost, bookingPath+"/hold", nil); resp.status != http.StatusOK {
+		t.Fatalf("hold status = %d: %s", resp.status, resp.body)
+	}
+	payment := payBooking(t, booking.BookingID)
+
+	accept := func(mediaRange string) http.Header { return http.Header{"Accept": {mediaRange}} }
+	confirmationPath := bookingPath + "/confirmation"
+
+	resp := callWithHeader(t, http.MethodGet, confirmationPath, nil, accept("application/json"))
+	var confirmation model.Confirmation
+	resp.decode(t, &confirmation)
+	if resp.status != http.StatusOK || confirmation.Booking.Status != model.BookingConfirmed || len(confirmation.Passengers) != 2 ||
+		confirmation.Passengers[1].Nationality.NationalityCode != "ID" || confirmation.Quote == nil ||
+		confirmation.Quote.Total != payment.Amount {
+		t.Fatalf("JSON confirmation status = %d: %s", resp.status, resp.body)
+	}
+
+	resp = callWithHeader(t, http.MethodGet, confirmationPath, nil, accept("text/html,application/xhtml+xml,*/*;q=0.8"))
+	if resp.status != http.StatusOK || !strings.HasPrefix(resp.header.Get("Content-Type"), "text/html") ||
+		!bytes.Contains(resp.body, []byte("Confirmed Child")) || !bytes.Contains(resp.body, []byte(trip.TripCode)) {
+		t.Fatalf("HTML confirmation status = %d, type %q: %s", resp.status, resp.header.Get("Content-Type"), resp.body)
+	}
+
+	resp = callWithHeader(t, http.MethodGet, confirmationPath, nil, accept("text/html;q=0.5, application/pdf"))
+	if resp.status != http.StatusOK || resp.header.Get("Content-Type") != "application/pdf" ||
+		!bytes.HasPrefix(resp.body, []byte("%PDF-")) || !bytes.Contains(resp.body, []byte("Confirmed Child")) {
+		t.Fatalf("PDF confirmation status = %d, type %q", resp.status, resp.header.Get("Content-Type"))
+	}
+
+	if resp := callWithHeader(t, http.MethodGet, confirmationPath, nil, accept("image/png")); resp.status != http.StatusNotAcceptable {
+		t.Fatalf("unacceptable type: status = %d, want 406", resp.status)
+	}
+	if resp := call(t, http.MethodGet, "/api/v1/booking/999999/confirmation", nil); resp.status != http.StatusNotFound {
+		t.Fatalf("missing booking: status = %d, want 404", resp.status)
+	}
+}
@@ -0,0 +1,206 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newCert issues a certificate for name, signed by parent or self-signed
+// when parent is nil.
+func newCert(t *testing.T, name string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// write stores the certificate and its key in dir and returns both paths.
+func (c *testCert) write(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, c.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestReloaderPicksUpRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := newCert(t, "first.test", false, nil).write(t, dir)
+
+	reloader, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	commonName := func() string {
+		cert, _ := reloader.GetCertificate(nil)
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed.Subject.CommonName
+	}
+	if got := commonName(); got != "first.test" {
+		t.Fatalf("serving %s, want first.test", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	// a broken rotation keeps the previous pair
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(certFile, later, later)
+	time.Sleep(50 * time.Millisecond)
+	if got := commonName(); got != "first.test" {
+		t.Fatalf("serving %s after a broken rotation, want first.test", got)
+	}
+
+	newCert(t, "second.test", false, nil).write(t, dir)
+	later = later.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+	deadline := time.Now().Add(2 * time.Second)
+	for commonName() != "second.test" {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClientAuthType(t *testing.T) {
+	tests := map[string]tls.ClientAuthType{
+		"":                   tls.NoClientCert,
+		"none":               tls.NoClientCert,
+		"request":            tls.RequestClientCert,
+		"require":            tls.RequireAndVerifyClientCert,
+		"verify_if_given":    tls.VerifyClientCertIfGiven,
+		"require_and_verify": tls.RequireAndVerifyClientCert,
+	}
+	for mode, want := range tests {
+		if got, err := ClientAuthType(mode); err != nil || got != want {
+			t.Errorf("ClientAuthType(%q) = %v, %v, want %v", mode, got, err, want)
+		}
+	}
+	if _, err := ClientAuthType("optional"); err == nil {
+		t.Error("accepted an unknown mode")
+	}
+}
+
+func TestServerConfigRequireVerifiesClients(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCert(t, "ca.test", true, nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := newCert(t, "server.test", false, ca)
+	certFile, keyFile := server.write(t, dir)
+	reloader, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig, err := ServerConfig(reloader, "require", caFile)
+	if err != nil {
+		t.Fatalf("ServerConfig: %v", err)
+	}
+
+	handshake := func(client *testCert) error {
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		defer serverConn.Close()
+
+		clientConfig := &tls.Config{RootCAs: x509.NewCertPool(), ServerName: "server.test"}
+		clientConfig.RootCAs.AddCert(ca.cert)
+		if client != nil {
+			clientConfig.Certificates = []tls.Certificate{client.tlsCertificate()}
+		}
+		go func() {
+			// closing after the handshake unblocks the server's alert write
+			tls.Client(clientConn, clientConfig).Handshake()
+			clientConn.Close()
+		}()
+
+		conn := tls.Server(serverConn, serverConfig)
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		return conn.Handshake()
+	}
+
+	if err := handshake(newCert(t, "client.test", false, ca)); err != nil {
+		t.Errorf("client signed by the CA was rejected: %v", err)
+	}
+	if err := handshake(newCert(t, "rogue.test", false, nil)); err == nil {
+		t.Error("self-signed client certificate was accepted")
+	}
+	if err := handshake(nil); err == nil {
+		t.Error("client without a certificate was accepted")
+	}
+}
+
+func TestLoadCertPoolRejectsEmptyBundle(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(file, []byte("no pem here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCertPool(file); err == nil {
+		t.Error("accepted a bundle without certificates")
+	}
+}
@@ -1,46 +1,382 @@
 package config
 
 import (
+	"booking_togo/internal/usecase"
+	"encoding/base64"
+	"fmt"
+	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	log "github.com/sirupsen/logrus"
 )
 
+// Config is assembled by Load from, in increasing precedence: the `default`
+// tags, a YAML/TOML file, environment variables and command-line flags.
+// The json tag is the key used in config files (nested by section). Every
+// env var can also be read from a file named by the same var with a _FILE
+// suffix, e.g. DB_PASSWORD_FILE=/run/secrets/db_password.
 type Config struct {
-	Port       string
-	DbName     string
-	DbHost     string
-	DbPort     string
-	DbUser     string
-	DbPassword string
+	Env  string `json:"env" env:"ENV" flag:"env" default:"development" usage:"environment name (development, production)"`
+	Port string `json:"port" env:"PORT" flag:"port" default:"8080" usage:"HTTP listen port"`
+
+	// DatabaseURL, when set, takes precedence over the individual Db* fields.
+	DatabaseURL Secret `json:"database_url" env:"DATABASE_URL" flag:"database-url" usage:"postgres connection URL"`
+	DbName      string `json:"db_name" env:"DB_NAME" flag:"db-name" usage:"database name"`
+	DbHost      string `json:"db_host" env:"DB_HOST" flag:"db-host" usage:"database host"`
+	DbPort      string `json:"db_port" env:"DB_PORT" flag:"db-port" default:"5432" usage:"database port"`
+	DbUser      string `json:"db_user" env:"DB_USER" flag:"db-user" usage:"database user"`
+	DbPassword  Secret `json:"db_password" env:"DB_PASSWORD" flag:"db-password" usage:"database password"`
+	// DbSSLMode is one of disable, allow, prefer, require, verify-ca or verify-full.
+	DbSSLMode     string `json:"db_sslmode" env:"DB_SSLMODE" flag:"db-sslmode" default:"disable" usage:"postgres sslmode"`
+	DbSSLRootCert string `json:"db_sslrootcert" env:"DB_SSLROOTCERT" flag:"db-sslrootcert" usage:"CA bundle used to verify the postgres server"`
+	DbSSLCert     string `json:"db_sslcert" env:"DB_SSLCERT" flag:"db-sslcert" usage:"client certificate presented to postgres"`
+	DbSSLKey      string `json:"db_sslkey" env:"DB_SSLKEY" flag:"db-sslkey" usage:"client key presented to postgres"`
+
+	Pool PoolConfig `json:"pool"`
+	HTTP HTTPConfig `json:"http"`
+	CORS CORSConfig `json:"cors"`
+	Log  LogConfig  `json:"log"`
+	TLS  TLSConfig  `json:"tls"`
+
+	// TrustedProxies lists the CIDRs allowed to set ForwardedHeader, the only
+	// header the client address is read from.
+	TrustedProxies  []string `json:"trusted_proxies" env:"TRUSTED_PROXIES" flag:"trusted-proxies" usage:"comma-separated trusted proxy CIDRs"`
+	ForwardedHeader string   `json:"forwarded_header" env:"FORWARDED_HEADER" flag:"forwarded-header" default:"X-Forwarded-For" usage:"header trusted proxies append the client address to (X-Forwarded-For, Forwarded)"`
+	// TracingExporter selects the span exporter: none, stdout or otlp.
+	TracingExporter string `json:"tracing_exporter" env:"OTEL_TRACES_EXPORTER" flag:"tracing-exporter" default:"none" usage:"trace exporter (none, stdout, otlp)"`
+	MigrateOnStart  bool   `json:"migrate_on_start" env:"MIGRATE_ON_START" flag:"migrate-on-start" default:"true" usage:"apply schema migrations at startup"`
+
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	Booking   BookingConfig   `json:"booking"`
+	Pricing   PricingConfig   `json:"pricing"`
+	Payment   PaymentConfig   `json:"payment"`
+	Refund    RefundConfig    `json:"refund"`
+	Document  DocumentConfig  `json:"document"`
+	// AdminToken enables POST /admin/reload for bearers of this token.
+	AdminToken Secret `json:"admin_token" env:"ADMIN_TOKEN" flag:"admin-token" usage:"bearer token for admin endpoints (disabled when empty)"`
+}
+
+// RateLimitConfig is a per-client-IP token bucket; a zero rate disables it.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second" env:"RATE_LIMIT_RPS" flag:"rate-limit-rps" default:"0" usage:"requests per second allowed per client IP (0 disables)"`
+	Burst             int     `json:"burst" env:"RATE_LIMIT_BURST" flag:"rate-limit-burst" default:"20" usage:"burst size per client IP"`
+}
+
+// BookingConfig controls seat holds: how long a hold reserves seats and how
+// often expired holds are released.
+type BookingConfig struct {
+	HoldTTL            time.Duration `json:"hold_ttl" env:"BOOKING_HOLD_TTL" flag:"booking-hold-ttl" default:"10m" usage:"how long a hold keeps seats reserved"`
+	HoldReaperInterval time.Duration `json:"hold_reaper_interval" env:"BOOKING_HOLD_REAPER_INTERVAL" flag:"booking-hold-reaper-interval" default:"30s" usage:"how often expired holds are released"`
+}
+
+// PricingConfig holds the fare rules. Travellers up to InfantMaxAge pay
+// InfantPercent of the adult fare, those up to ChildMaxAge ChildPercent.
+// A discount applies when its threshold is reached; a zero percent disables
+// it.
+type PricingConfig struct {
+	Currency             string `json:"currency" env:"PRICING_CURRENCY" flag:"pricing-currency" default:"IDR" usage:"ISO 4217 currency of trip fares"`
+	InfantMaxAge         int    `json:"infant_max_age" env:"PRICING_INFANT_MAX_AGE" flag:"pricing-infant-max-age" default:"1" usage:"oldest age priced as an infant"`
+	ChildMaxAge          int    `json:"child_max_age" env:"PRICING_CHILD_MAX_AGE" flag:"pricing-child-max-age" default:"11" usage:"oldest age priced as a child"`
+	InfantPercent        int    `json:"infant_percent" env:"PRICING_INFANT_PERCENT" flag:"pricing-infant-percent" default:"10" usage:"infant fare as a percentage of the adult fare"`
+	ChildPercent         int    `json:"child_percent" env:"PRICING_CHILD_PERCENT" flag:"pricing-child-percent" default:"75" usage:"child fare as a percentage of the adult fare"`
+	GroupMinPassengers   int    `json:"group_min_passengers" env:"PRICING_GROUP_MIN_PASSENGERS" flag:"pricing-group-min-passengers" default:"5" usage:"passengers needed for the group discount"`
+	GroupDiscountPercent int    `json:"group_discount_percent" env:"PRICING_GROUP_DISCOUNT_PERCENT" flag:"pricing-group-discount-percent" default:"0" usage:"group discount percentage (0 disables)"`
+	EarlyBookingDays     int    `json:"early_booking_days" env:"PRICING_EARLY_BOOKING_DAYS" flag:"pricing-early-booking-days" default:"30" usage:"days before departure that earn the early booking discount"`
+	EarlyBookingPercent  int    `json:"early_booking_percent" env:"PRICING_EARLY_BOOKING_PERCENT" flag:"pricing-early-booking-percent" default:"0" usage:"early booking discount percentage (0 disables)"`
+}
+
+// PaymentConfig selects the payment gateway. Only the in-process fake
+// gateway exists so far; it signs webhooks with WebhookSecret, or with a
+// random secret when that is empty, and is refused in production.
+type PaymentConfig struct {
+	Gateway           string        `json:"gateway" env:"PAYMENT_GATEWAY" flag:"payment-gateway" default:"fake" usage:"payment gateway (fake)"`
+	WebhookSecret     Secret        `json:"webhook_secret" env:"PAYMENT_WEBHOOK_SECRET" flag:"payment-webhook-secret" usage:"secret verifying payment webhook signatures"`
+	WebhookTolerance  time.Duration `json:"webhook_tolerance" env:"PAYMENT_WEBHOOK_TOLERANCE" flag:"payment-webhook-tolerance" default:"5m" usage:"maximum age of a payment webhook"`
+	ReconcileInterval time.Duration `json:"reconcile_interval" env:"PAYMENT_RECONCILE_INTERVAL" flag:"payment-reconcile-interval" default:"1m" usage:"how often payments are reconciled with bookings"`
+	PendingTimeout    time.Duration `json:"pending_timeout" env:"PAYMENT_PENDING_TIMEOUT" flag:"payment-pending-timeout" default:"5m" usage:"age after which pending payments are checked at the gateway"`
+}
+
+// RefundConfig is the refund policy for cancelled passengers. Each rule is
+// "<fare class>:<passenger type>:<min days>:<percent>" and refunds percent of
+// what the passenger paid when cancelled at least min days before
+// departure; class and type may be "any". The first matching rule applies,
+// no match refunds nothing.
+type RefundConfig struct {
+	Rules []string `json:"rules" env:"REFUND_RULES" flag:"refund-rules" default:"flex:any:1:100,flex:any:0:50,standard:infant:0:100,standard:any:14:75,standard:any:3:25" usage:"comma-separated refund rules, first match wins"`
+}
+
+// DocumentConfig holds the key travel document numbers are encrypted with:
+// 32 random bytes, base64 encoded. Without one a random key is used and
+// stored numbers cannot be read after a restart, so production requires it.
+type DocumentConfig struct {
+	EncryptionKey Secret `json:"encryption_key" env:"DOCUMENT_ENCRYPTION_KEY" flag:"document-encryption-key" usage:"base64 AES-256 key encrypting travel document numbers"`
+}
+
+type PoolConfig struct {
+	MaxConns          int32         `json:"max_conns" env:"DB_MAX_CONNS" flag:"db-max-conns" default:"25" usage:"maximum pool connections"`
+	MinConns          int32         `json:"min_conns" env:"DB_MIN_CONNS" flag:"db-min-conns" default:"5" usage:"minimum pool connections"`
+	MaxConnLifetime   time.Duration `json:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME" flag:"db-max-conn-lifetime" default:"1h" usage:"maximum lifetime of a connection"`
+	MaxConnIdleTime   time.Duration `json:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME" flag:"db-max-conn-idle-time" default:"30m" usage:"maximum idle time of a connection"`
+	HealthCheckPeriod time.Duration `json:"health_check_period" env:"DB_HEALTH_CHECK_PERIOD" flag:"db-health-check-period" default:"1m" usage:"pool health check period"`
+	ConnectTimeout    time.Duration `json:"connect_timeout" env:"DB_CONNECT_TIMEOUT" flag:"db-connect-timeout" default:"5s" usage:"startup ping timeout"`
+}
+
+type HTTPConfig struct {
+	HandlerTimeout    time.Duration `json:"handler_timeout" env:"HTTP_HANDLER_TIMEOUT" flag:"http-handler-timeout" default:"10s" usage:"per-request usecase timeout"`
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" flag:"http-read-header-timeout" default:"5s" usage:"server read header timeout"`
+	ReadTimeout       time.Duration `json:"read_timeout" env:"HTTP_READ_TIMEOUT" flag:"http-read-timeout" default:"15s" usage:"server read timeout"`
+	WriteTimeout      time.Duration `json:"write_timeout" env:"HTTP_WRITE_TIMEOUT" flag:"http-write-timeout" default:"15s" usage:"server write timeout"`
+	IdleTimeout       time.Duration `json:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" flag:"http-idle-timeout" default:"60s" usage:"server keep-alive idle timeout"`
+	ShutdownTimeout   time.Duration `json:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" flag:"http-shutdown-timeout" default:"10s" usage:"time allowed for in-flight requests and cleanup after draining"`
+	// DrainPeriod is how long readiness fails before the listener closes, so
+	// load balancers stop routing new requests first.
+	DrainPeriod   time.Duration `json:"drain_period" env:"SHUTDOWN_DRAIN_PERIOD" flag:"shutdown-drain-period" default:"5s" usage:"time readiness fails before the server stops accepting requests"`
+	HealthTimeout time.Duration `json:"health_timeout" env:"HEALTH_CHECK_TIMEOUT" flag:"health-check-timeout" default:"2s" usage:"readiness check timeout"`
+}
+
+// CORSConfig is the browser access policy. Origins may be exact
+// ("https://booking.example.com"), wildcard subdomains ("https://*.example.com")
+// or "*", which cannot be combined with credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string      `json:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins" default:"*" usage:"comma-separated allowed origins"`
+	AllowedMethods   []string      `json:"allowed_methods" env:"CORS_ALLOWED_METHODS" flag:"cors-allowed-methods" default:"GET,HEAD,POST,PUT,DELETE,OPTIONS" usage:"comma-separated allowed methods"`
+	AllowedHeaders   []string      `json:"allowed_headers" env:"CORS_ALLOWED_HEADERS" flag:"cors-allowed-headers" default:"X-Requested-With,Content-Type,Authorization,X-Request-ID" usage:"comma-separated allowed request headers"`
+	ExposedHeaders   []string      `json:"exposed_headers" env:"CORS_EXPOSED_HEADERS" flag:"cors-exposed-headers" default:"ETag,X-Request-ID" usage:"comma-separated response headers readable by browsers"`
+	AllowCredentials bool          `json:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" flag:"cors-allow-credentials" default:"false" usage:"allow cookies and auth headers on cross-origin requests"`
+	MaxAge           time.Duration `json:"max_age" env:"CORS_MAX_AGE" flag:"cors-max-age" default:"10m" usage:"how long browsers may cache preflight responses (capped at 10m)"`
+}
+
+// TLSConfig enables HTTPS when CertFile and KeyFile are set.
+type TLSConfig struct {
+	CertFile       string        `json:"cert_file" env:"TLS_CERT_FILE" flag:"tls-cert-file" usage:"server certificate (enables HTTPS)"`
+	KeyFile        string        `json:"key_file" env:"TLS_KEY_FILE" flag:"tls-key-file" usage:"server private key"`
+	ReloadInterval time.Duration `json:"reload_interval" env:"TLS_RELOAD_INTERVAL" flag:"tls-reload-interval" default:"1m" usage:"how often certificate files are checked for rotation"`
+	// ClientAuth is one of none, request, require, verify_if_given or
+	// require_and_verify; require is an alias of require_and_verify.
+	ClientAuth   string `json:"client_auth" env:"TLS_CLIENT_AUTH" flag:"tls-client-auth" default:"none" usage:"client certificate policy"`
+	ClientCAFile string `json:"client_ca_file" env:"TLS_CLIENT_CA_FILE" flag:"tls-client-ca-file" usage:"CA bundle used to verify client certificates"`
+}
+
+// Enabled reports whether the server should listen with HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+type LogConfig struct {
+	Level string `json:"level" env:"LOG_LEVEL" flag:"log-level" default:"info" usage:"log level (debug, info, warn, error)"`
+	// Format defaults to json in production and text elsewhere.
+	Format string `json:"format" env:"LOG_FORMAT" flag:"log-format" usage:"log format (text, json)"`
+}
+
+// Load builds the configuration from defaults, the file named by -config or
+// CONFIG_FILE, the environment and args, then validates it. It returns
+// flag.ErrHelp, unwrapped, when args ask for help.
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	if err := newLoader(cfg).load(args); err != nil {
+		return nil, err
+	}
+
+	if cfg.Log.Format == "" {
+		cfg.Log.Format = "text"
+		if cfg.Env == "production" {
+			cfg.Log.Format = "json"
+		}
+	}
+
+	// Header names are case-insensitive; the resolver accepts any case, so
+	// validation must too.
+	cfg.ForwardedHeader = http.CanonicalHeaderKey(cfg.ForwardedHeader)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
 }
 
-func Load() *Config {
+func (c Config) Validate() error {
+	needsParts := c.DatabaseURL == ""
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Port, validation.Required, validation.By(validatePort)),
+		validation.Field(&c.DbHost, validation.When(needsParts, validation.Required.Error("is required unless DATABASE_URL is set"))),
+		validation.Field(&c.DbPort, validation.When(needsParts, validation.Required, validation.By(validatePort))),
+		validation.Field(&c.DbName, validation.When(needsParts, validation.Required.Error("is required unless DATABASE_URL is set"))),
+		validation.Field(&c.DbUser, validation.When(needsParts, validation.Required.Error("is required unless DATABASE_URL is set"))),
+		validation.Field(&c.DbSSLMode, validation.In("disable", "allow", "prefer", "require", "verify-ca", "verify-full")),
+		validation.Field(&c.DbSSLRootCert, validation.When(c.DbSSLMode == "verify-ca", validation.Required.Error("is required for sslmode verify-ca"))),
+		validation.Field(&c.DbSSLKey, validation.When(c.DbSSLCert != "", validation.Required.Error("is required when db_sslcert is set"))),
+		validation.Field(&c.Pool),
+		validation.Field(&c.HTTP),
+		validation.Field(&c.CORS),
+		validation.Field(&c.Log),
+		validation.Field(&c.TLS),
+		validation.Field(&c.RateLimit),
+		validation.Field(&c.Booking),
+		validation.Field(&c.Pricing),
+		validation.Field(&c.Payment, validation.When(c.Env == "production", validation.By(rejectFakeGateway))),
+		validation.Field(&c.Refund),
+		validation.Field(&c.Document, validation.When(c.Env == "production", validation.By(requireEncryptionKey))),
+		validation.Field(&c.ForwardedHeader, validation.In("X-Forwarded-For", "Forwarded")),
+		validation.Field(&c.TracingExporter, validation.In("none", "stdout", "otlp")),
+	)
+}
+
+func (p PoolConfig) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.MaxConns, validation.Required, validation.Min(int32(1))),
+		validation.Field(&p.MinConns, validation.Min(int32(0)), validation.Max(p.MaxConns).Error("must not exceed max_conns")),
+		validation.Field(&p.MaxConnLifetime, validation.Required),
+		validation.Field(&p.MaxConnIdleTime, validation.Required),
+		validation.Field(&p.HealthCheckPeriod, validation.Required),
+		validation.Field(&p.ConnectTimeout, validation.Required),
+	)
+}
+
+func (h HTTPConfig) Validate() error {
+	return validation.ValidateStruct(&h,
+		validation.Field(&h.HandlerTimeout, validation.Required),
+		validation.Field(&h.ReadHeaderTimeout, validation.Required),
+		validation.Field(&h.ShutdownTimeout, validation.Required),
+		validation.Field(&h.DrainPeriod, validation.Min(time.Duration(0))),
+		validation.Field(&h.HealthTimeout, validation.Required),
+	)
+}
+
+func (c CORSConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.AllowedOrigins, validation.Required),
+		validation.Field(&c.AllowedMethods, validation.Required),
+		validation.Field(&c.AllowCredentials, validation.When(slices.Contains(c.AllowedOrigins, "*"),
+			validation.Empty.Error("cannot be enabled while allowed_origins contains \"*\""))),
+		validation.Field(&c.MaxAge, validation.Min(time.Duration(0))),
+	)
+}
+
+func (t TLSConfig) Validate() error {
+	verifiesClients := t.ClientAuth == "require" || t.ClientAuth == "verify_if_given" || t.ClientAuth == "require_and_verify"
+
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.KeyFile, validation.When(t.CertFile != "", validation.Required.Error("is required when cert_file is set"))),
+		validation.Field(&t.CertFile, validation.When(t.KeyFile != "" || t.ClientAuth != "none", validation.Required.Error("is required when TLS options are set"))),
+		validation.Field(&t.ReloadInterval, validation.Required),
+		validation.Field(&t.ClientAuth, validation.In("none", "request", "require", "verify_if_given", "require_and_verify")),
+		validation.Field(&t.ClientCAFile, validation.When(verifiesClients, validation.Required.Error("is required to verify client certificates"))),
+	)
+}
+
+func (r RateLimitConfig) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.RequestsPerSecond, validation.Min(0.0)),
+		validation.Field(&r.Burst, validation.When(r.RequestsPerSecond > 0, validation.Required, validation.Min(1))),
+	)
+}
+
+func (b BookingConfig) Validate() error {
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.HoldTTL, validation.Required, validation.Min(time.Second)),
+		validation.Field(&b.HoldReaperInterval, validation.Required, validation.Min(time.Second)),
+	)
+}
+
+func (p PricingConfig) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Currency, validation.Required, validation.Match(regexp.MustCompile(`^[A-Z]{3}$`)).Error("must be an ISO 4217 code")),
+		validation.Field(&p.InfantMaxAge, validation.Min(0)),
+		validation.Field(&p.ChildMaxAge, validation.Min(p.InfantMaxAge+1).Error("must be greater than infant_max_age")),
+		validation.Field(&p.InfantPercent, validation.Min(0), validation.Max(100)),
+		validation.Field(&p.ChildPercent, validation.Min(0), validation.Max(100)),
+		validation.Field(&p.GroupMinPassengers, validation.When(p.GroupDiscountPercent > 0, validation.Min(2))),
+		validation.Field(&p.GroupDiscountPercent, validation.Min(0), validation.Max(100)),
+		validation.Field(&p.EarlyBookingDays, validation.When(p.EarlyBookingPercent > 0, validation.Min(1))),
+		validation.Field(&p.EarlyBookingPercent, validation.Min(0), validation.Max(100)),
+	)
+}
+
+func (p PaymentConfig) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Gateway, validation.Required, validation.In("fake")),
+		validation.Field(&p.WebhookTolerance, validation.Required, validation.Min(time.Second)),
+		validation.Field(&p.ReconcileInterval, validation.Required, validation.Min(time.Second)),
+		validation.Field(&p.PendingTimeout, validation.Required, validation.Min(time.Second)),
+	)
+}
+
+// rejectFakeGateway keeps the fake gateway, whose simulate endpoint lets
+// anyone mark a payment succeeded, out of production.
+func rejectFakeGateway(value interface{}) error {
+	if p, _ := value.(PaymentConfig); p.Gateway == "fake" {
+		return validation.NewError("validation_fake_gateway", "the fake gateway cannot be used in production")
 	}
+	return nil
+}
+
+func (r RefundConfig) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Rules, validation.Each(validation.By(validateRefundRule))),
+	)
+}
+
+// validateRefundRule checks a rule with the parser the refund policy is
+// built with, so the two cannot disagree.
+func validateRefundRule(value interface{}) error {
+	s, _ := value.(string)
+	_, err := usecase.ParseRefundRule(s)
+	return err
+}
+
+func (d DocumentConfig) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.EncryptionKey, validation.By(validateEncryptionKey)),
+	)
+}
+
+func requireEncryptionKey(value interface{}) error {
+	if d, _ := value.(DocumentConfig); d.EncryptionKey == "" {
+		return validation.NewError("validation_encryption_key_required", "encryption_key is required in production")
+	}
+	return nil
+}
 
-	dbName := os.Getenv("DB_NAME")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-
-	return &Config{
-		Port:       port,
-		DbName:     dbName,
-		DbHost:     dbHost,
-		DbPort:     dbPort,
-		DbUser:     dbUser,
-		DbPassword: dbPassword,
+func validateEncryptionKey(value interface{}) error {
+	key, _ := value.(Secret)
+	if key == "" {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key.Reveal())
+	if err != nil || len(decoded) != 32 {
+		return validation.NewError("validation_encryption_key", "must be 32 bytes, base64 encoded")
 	}
+	return nil
 }
 
-func InitLogger() {
+func (l LogConfig) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Level, validation.Required, validation.By(func(value interface{}) error {
+			_, err := log.ParseLevel(value.(string))
+			return err
+		})),
+		validation.Field(&l.Format, validation.Required, validation.In("text", "json")),
+	)
+}
+
+func validatePort(value interface{}) error {
+	port, err := strconv.Atoi(value.(string))
+	if err != nil || port < 1 || port > 65535 {
+		return validation.NewError("validation_invalid_port", "must be a port number between 1 and 65535")
+	}
+	return nil
+}
+
+func InitLogger(cfg *Config) {
 	// Set log format to JSON for production, text for development
-	if os.Getenv("ENV") == "production" {
+	if cfg.Log.Format == "json" {
 		log.SetFormatter(&log.JSONFormatter{})
 	} else {
 		log.SetFormatter(&log.TextFormatter{
@@ -50,13 +386,8 @@ func InitLogger() {
 		})
 	}
 
-	// Set log level
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-
-	level, err := log.ParseLevel(logLevel)
+	// Set log level, already validated by Load
+	level, err := log.ParseLevel(cfg.Log.Level)
 	if err != nil {
 		log.SetLevel(log.InfoLevel)
 	} else {
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable Load reads, so the tests do not depend on
+// the environment they run in.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, f := range newLoader(&Config{}).fields {
+		if f.env == "" {
+			continue
+		}
+		for _, name := range []string{f.env, f.env + "_FILE"} {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	file := writeFile(t, "config.yaml", `
+database_url: postgres://file
+port: "8081"
+http:
+  handler_timeout: 3s
+log:
+  level: warn
+trusted_proxies: [10.0.0.0/8, 192.168.0.0/16]
+`)
+	t.Setenv("PORT", "8082")
+	t.Setenv("LOG_LEVEL", "")
+
+	cfg, err := Load([]string{"-config", file, "-port", "8083"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Port != "8083" {
+		t.Errorf("port = %q, want the flag value 8083", cfg.Port)
+	}
+	if cfg.HTTP.HandlerTimeout != 3*time.Second {
+		t.Errorf("handler timeout = %v, want the file value 3s", cfg.HTTP.HandlerTimeout)
+	}
+	if cfg.Log.Level != "warn" {
+		t.Errorf("log level = %q, want the file value, as the env var is empty", cfg.Log.Level)
+	}
+	if cfg.DbPort != "5432" || cfg.ForwardedHeader != "X-Forwarded-For" {
+		t.Errorf("defaults not applied: db port %q, forwarded header %q", cfg.DbPort, cfg.ForwardedHeader)
+	}
+	if strings.Join(cfg.TrustedProxies, ",") != "10.0.0.0/8,192.168.0.0/16" {
+		t.Errorf("trusted proxies = %v", cfg.TrustedProxies)
+	}
+	if cfg.Log.Format != "text" {
+		t.Errorf("log format = %q, want text outside production", cfg.Log.Format)
+	}
+
+	t.Setenv("LOG_LEVEL", "debug")
+	if cfg, err = Load([]string{"-config", file}); err != nil || cfg.Log.Level != "debug" || cfg.Port != "8082" {
+		t.Fatalf("env does not override the file: %+v, %v", cfg, err)
+	}
+	if cfg, err = Load([]string{"-config", file, "-forwarded-header", "forwarded"}); err != nil || cfg.ForwardedHeader != "Forwarded" {
+		t.Fatalf("lowercase forwarded header not canonicalised: %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	clearEnv(t)
+	secret := writeFile(t, "database_url", "postgres://from-file\n")
+	t.Setenv("DATABASE_URL_FILE", secret)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DatabaseURL.Reveal() != "postgres://from-file" {
+		t.Errorf("database url = %q", cfg.DatabaseURL.Reveal())
+	}
+
+	t.Setenv("DATABASE_URL", "postgres://env")
+	if _, err := Load(nil); err == nil {
+		t.Error("Load accepted both DATABASE_URL and DATABASE_URL_FILE")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://env")
+
+	if _, err := Load([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("-h: err = %v, want flag.ErrHelp", err)
+	}
+	if _, err := Load([]string{"-config", writeFile(t, "config.json", "{}")}); err == nil {
+		t.Error("accepted a .json config file")
+	}
+	if _, err := Load([]string{"-http-handler-timeout", "soon"}); err == nil {
+		t.Error("accepted an invalid duration")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://localhost/booking")
+	base := func(t *testing.T) *Config {
+		t.Helper()
+		cfg, err := Load(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "defaults with a database url", modify: func(cfg *Config) {}},
+		{name: "database parts instead of a url", modify: func(cfg *Config) {
+			cfg.DatabaseURL, cfg.DbHost, cfg.DbName, cfg.DbUser = "", "localhost", "booking", "postgres"
+		}},
+		{name: "missing database host", modify: func(cfg *Config) { cfg.DatabaseURL, cfg.DbName, cfg.DbUser = "", "booking", "postgres" }, wantErr: "db_host"},
+		{name: "invalid port", modify: func(cfg *Config) { cfg.Port = "http" }, wantErr: "port"},
+		{name: "refund percent over 100", modify: func(cfg *Config) { cfg.Refund.Rules = []string{"any:any:0:101"} }, wantErr: "percent"},
+		{name: "unknown forwarded header", modify: func(cfg *Config) { cfg.ForwardedHeader = "X-Real-IP" }, wantErr: "forwarded_header"},
+		{name: "required client certificates without a CA", modify: func(cfg *Config) {
+			cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientAuth = "tls.crt", "tls.key", "require"
+		}, wantErr: "client_ca_file"},
+		{name: "production without document key", modify: func(cfg *Config) { cfg.Env = "production" }, wantErr: "document"},
+		{name: "fake gateway in production", modify: func(cfg *Config) {
+			cfg.Env, cfg.Document.EncryptionKey = "production", Secret(strings.Repeat("A", 43)+"=")
+		}, wantErr: "fake gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base(t)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate err = %v, want one about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,46 +1,102 @@
 package config
 
 import (
+	"booking_togo/internal/tracing"
 	"context"
 	"fmt"
 	"log"
-	"time"
+	"net"
+	"net/url"
+	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func NewPool(cfg *Config) (*pgxpool.Pool, error) {
-	connString := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.DbUser, cfg.DbPassword, cfg.DbHost, cfg.DbPort, cfg.DbName, "disable",
-	)
+	connString := cfg.DatabaseURL.Reveal()
+	if connString == "" {
+		connString = connectionURL(cfg)
+	}
 
 	config, err := pgxpool.ParseConfig(connString)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+		return nil, redactError(cfg, "failed to parse config", err)
+	}
+	if cfg.DatabaseURL == "" {
+		// kept out of the URL so it can never surface in a parse error
+		config.ConnConfig.Password = cfg.DbPassword.Reveal()
 	}
 
 	// Connection pool settings
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = time.Minute * 30
-	config.HealthCheckPeriod = time.Minute
+	config.MaxConns = cfg.Pool.MaxConns
+	config.MinConns = cfg.Pool.MinConns
+	config.MaxConnLifetime = cfg.Pool.MaxConnLifetime
+	config.MaxConnIdleTime = cfg.Pool.MaxConnIdleTime
+	config.HealthCheckPeriod = cfg.Pool.HealthCheckPeriod
+
+	// Trace every acquire, query, batch and COPY
+	config.ConnConfig.Tracer = tracing.NewPgxTracer()
 
 	// Create connection pool
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+		return nil, redactError(cfg, "failed to create connection pool", err)
 	}
 
 	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Pool.ConnectTimeout)
 	defer cancel()
 
 	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, redactError(cfg, "failed to ping database", err)
 	}
 
 	log.Println("✅ PostgreSQL connection pool established")
 	return pool, nil
 }
+
+// connectionURL builds the postgres URL from the individual Db* settings,
+// escaping the user and adding the TLS parameters understood by pgx. The
+// password is deliberately left out, see NewPool.
+func connectionURL(cfg *Config) string {
+	params := url.Values{}
+	params.Set("sslmode", cfg.DbSSLMode)
+	if cfg.DbSSLRootCert != "" {
+		params.Set("sslrootcert", cfg.DbSSLRootCert)
+	}
+	if cfg.DbSSLCert != "" {
+		params.Set("sslcert", cfg.DbSSLCert)
+		params.Set("sslkey", cfg.DbSSLKey)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(cfg.DbUser),
+		Host:     net.JoinHostPort(cfg.DbHost, cfg.DbPort),
+		Path:     "/" + cfg.DbName,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// redactError flattens err with every secret replaced by a placeholder. The
+// original error is not wrapped, so the secret cannot be recovered from it.
+func redactError(cfg *Config, msg string, err error) error {
+	secrets := []Secret{cfg.DbPassword, cfg.DatabaseURL}
+	if u, parseErr := url.Parse(cfg.DatabaseURL.Reveal()); parseErr == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			secrets = append(secrets, Secret(password))
+		}
+	}
+
+	text := err.Error()
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret.Reveal(), secret.String())
+		}
+	}
+	if cfg.DbPassword != "" {
+		text = strings.ReplaceAll(text, url.QueryEscape(cfg.DbPassword.Reveal()), cfg.DbPassword.String())
+	}
+	return fmt.Errorf("%s: %s", msg, text)
+}
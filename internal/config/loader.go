@@ -0,0 +1,283 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// field is one leaf of the Config tree together with its source tags.
+type field struct {
+	path   []string
+	env    string
+	flag   string
+	def    string
+	usage  string
+	hasDef bool
+	value  reflect.Value
+}
+
+type loader struct {
+	fields []field
+}
+
+func newLoader(cfg *Config) *loader {
+	l := &loader{}
+	l.walk(reflect.ValueOf(cfg).Elem(), nil)
+	return l
+}
+
+func (l *loader) walk(v reflect.Value, path []string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fieldPath := append(append([]string{}, path...), key)
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			l.walk(v.Field(i), fieldPath)
+			continue
+		}
+
+		def, hasDef := sf.Tag.Lookup("default")
+		l.fields = append(l.fields, field{
+			path:   fieldPath,
+			env:    sf.Tag.Get("env"),
+			flag:   sf.Tag.Get("flag"),
+			def:    def,
+			usage:  sf.Tag.Get("usage"),
+			hasDef: hasDef,
+			value:  v.Field(i),
+		})
+	}
+}
+
+// load applies defaults, then the config file, then the environment, then
+// flags. Flags are parsed first only to learn -config; their values are
+// applied last so they win. Empty environment variables are ignored. -h
+// prints the usage and returns flag.ErrHelp.
+func (l *loader) load(args []string) error {
+	fs := flag.NewFlagSet("booking_togo", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+
+	flagValues := make(map[string]*flagValue, len(l.fields))
+	for _, f := range l.fields {
+		if f.flag == "" {
+			continue
+		}
+		fv := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		flagValues[f.flag] = fv
+		fs.Var(fv, f.flag, f.usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	for _, f := range l.fields {
+		if !f.hasDef {
+			continue
+		}
+		if err := setValue(f.value, f.def); err != nil {
+			return fmt.Errorf("default for %s: %w", strings.Join(f.path, "."), err)
+		}
+	}
+
+	if *configFile != "" {
+		if err := l.loadFile(*configFile); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range l.fields {
+		if f.env == "" {
+			continue
+		}
+		raw, ok, err := lookupEnv(f.env)
+		if err != nil {
+			return err
+		}
+		// an empty variable, as compose files leave behind, is unset
+		if !ok || raw == "" {
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			return fmt.Errorf("%s: %w", f.env, err)
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		fv, ok := flagValues[fl.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		for _, f := range l.fields {
+			if f.flag == fl.Name {
+				if err := setValue(f.value, fv.raw); err != nil {
+					flagErr = fmt.Errorf("-%s: %w", fl.Name, err)
+				}
+			}
+		}
+	})
+
+	return flagErr
+}
+
+// lookupEnv reads name, or the file named by name_FILE as mounted by Docker
+// and Kubernetes secrets. Setting both is rejected as ambiguous.
+func lookupEnv(name string) (string, bool, error) {
+	raw, ok := os.LookupEnv(name)
+	path, fileOK := os.LookupEnv(name + "_FILE")
+	if !fileOK || path == "" {
+		return raw, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("only one of %s and %s_FILE may be set", name, name)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+func (l *loader) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".toml":
+		err = toml.Unmarshal(content, &values)
+	default:
+		return fmt.Errorf("unsupported config file extension %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for _, f := range l.fields {
+		raw, ok := lookup(values, f.path)
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, strings.Join(f.path, "."), err)
+		}
+	}
+
+	return nil
+}
+
+// lookup walks a decoded file and renders the leaf as the same string form
+// accepted from env vars; lists become comma-separated.
+func lookup(values map[string]any, path []string) (string, bool) {
+	var current any = values
+	for _, key := range path {
+		section, ok := current.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if current, ok = section[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := current.(type) {
+	case map[string]any:
+		return "", false
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ","), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(Secret("")) {
+		// secrets are taken verbatim, surrounding spaces may be significant
+		v.SetString(raw)
+		return nil
+	}
+	raw = strings.TrimSpace(raw)
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+
+	return nil
+}
+
+// flagValue records the raw flag text so it can be applied after env vars.
+type flagValue struct {
+	raw    string
+	isBool bool
+}
+
+func (f *flagValue) String() string { return f.raw }
+
+func (f *flagValue) Set(raw string) error {
+	f.raw = raw
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
@@ -0,0 +1,31 @@
+package config
+
+// Secret holds a sensitive value. Printing or JSON-encoding it yields a
+// placeholder; only Reveal returns the real value.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+// Reveal returns the plain value. Call it only where the secret is consumed.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return `config.Secret("` + s.String() + `")`
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
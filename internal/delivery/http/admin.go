@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type AdminHandler struct {
+	token  string
+	reload func(ctx context.Context) error
+}
+
+// NewAdminHandler exposes operational actions guarded by a bearer token.
+// With an empty token no route is registered at all.
+func NewAdminHandler(token string, reload func(ctx context.Context) error) *AdminHandler {
+	return &AdminHandler{
+		token:  token,
+		reload: reload,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(r *mux.Router) {
+	if h.token == "" {
+		return
+	}
+	r.HandleFunc("/admin/reload", h.Reload).Methods(http.MethodPost)
+}
+
+// Reload re-reads the configuration and applies its reloadable parts. An
+// invalid configuration is rejected with 422 and the running one is kept.
+func (h *AdminHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeError(w, r, http.StatusUnauthorized, errors.New("invalid admin token"))
+		return
+	}
+
+	if err := h.reload(r.Context()); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, "configuration reloaded")
+}
+
+func (h *AdminHandler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
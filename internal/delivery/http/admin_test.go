@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAdminReload(t *testing.T) {
+	reloads := 0
+	var reloadErr error
+	router := mux.NewRouter()
+	NewAdminHandler("s3cret", func(ctx context.Context) error {
+		reloads++
+		return reloadErr
+	}).RegisterRoutes(router)
+
+	tests := []struct {
+		name          string
+		authorization string
+		reloadErr     error
+		want          int
+		wantReloads   int
+	}{
+		{name: "no token", want: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "token without bearer scheme", authorization: "s3cret", want: http.StatusUnauthorized},
+		{name: "valid token", authorization: "Bearer s3cret", want: http.StatusOK, wantReloads: 1},
+		{name: "rejected configuration", authorization: "Bearer s3cret", reloadErr: errors.New("invalid configuration"),
+			want: http.StatusUnprocessableEntity, wantReloads: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloads, reloadErr = 0, tt.reloadErr
+			r := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if reloads != tt.wantReloads {
+				t.Errorf("reloads = %d, want %d", reloads, tt.wantReloads)
+			}
+		})
+	}
+}
+
+func TestAdminRoutesDisabledWithoutToken(t *testing.T) {
+	router := mux.NewRouter()
+	NewAdminHandler("", func(ctx context.Context) error {
+		t.Error("reload called without a configured token")
+		return nil
+	}).RegisterRoutes(router)
+
+	r := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type BookingHandler struct {
+	usecaseBooking usecase.IBookingUsecase
+	timeout        time.Duration
+}
+
+func NewBookingHandler(usecaseBooking usecase.IBookingUsecase, timeout time.Duration) *BookingHandler {
+	return &BookingHandler{
+		usecaseBooking: usecaseBooking,
+		timeout:        timeout,
+	}
+}
+
+func (h *BookingHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/booking", h.GetAll).Methods(http.MethodGet)
+	r.HandleFunc("/booking", h.Create).Methods(http.MethodPost)
+	r.HandleFunc("/booking/{id}", h.Detail).Methods(http.MethodGet)
+	r.HandleFunc("/booking/{id}", h.Delete).Methods(http.MethodDelete)
+	r.HandleFunc("/booking/{id}/passengers", h.UpdatePassengers).Methods(http.MethodPut)
+	r.HandleFunc("/booking/{id}/history", h.History).Methods(http.MethodGet)
+	r.HandleFunc("/booking/{id}/{action:"+actionPattern()+"}", h.Transition).Methods(http.MethodPost)
+}
+
+// actionPattern is the mux pattern matching every transition action.
+func actionPattern() string {
+	actions := make([]string, 0, len(usecase.BookingActions))
+	for action := range usecase.BookingActions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return strings.Join(actions, "|")
+}
+
+// GetAll lists bookings, optionally only those of ?user_id=.
+func (h *BookingHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	userID := 0
+	if raw := r.URL.Query().Get("user_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		userID = id
+	}
+
+	bookings, err := h.usecaseBooking.GetAll(ctx, userID)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bookings)
+}
+
+func (h *BookingHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	var bookingPayload model.BookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&bookingPayload); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := h.usecaseBooking.Create(ctx, &bookingPayload)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, booking)
+}
+
+func (h *BookingHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := h.usecaseBooking.Detail(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, booking)
+}
+
+func (h *BookingHandler) UpdatePassengers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var bookingPayload model.BookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&bookingPayload); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := h.usecaseBooking.UpdatePassengers(ctx, id, &bookingPayload)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, booking)
+}
+
+func (h *BookingHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecaseBooking.Delete(ctx, id); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, "Booking deleted successfully")
+}
+
+// Transition handles POST /booking/{id}/{action}, e.g. /booking/7/confirm,
+// with an optional {"reason": "..."} body. Illegal moves answer 409.
+func (h *BookingHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var transitionPayload model.TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&transitionPayload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	to := usecase.BookingActions[mux.Vars(r)["action"]]
+	booking, err := h.usecaseBooking.Transition(ctx, id, to, transitionPayload.Reason)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, booking)
+}
+
+func (h *BookingHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	history, err := h.usecaseBooking.History(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type CancellationHandler struct {
+	usecaseCancellation usecase.ICancellationUsecase
+	timeout             time.Duration
+}
+
+func NewCancellationHandler(usecaseCancellation usecase.ICancellationUsecase, timeout time.Duration) *CancellationHandler {
+	return &CancellationHandler{
+		usecaseCancellation: usecaseCancellation,
+		timeout:             timeout,
+	}
+}
+
+func (h *CancellationHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/booking/{id}/cancellation", h.Preview).Methods(http.MethodGet)
+	r.HandleFunc("/booking/{id}/cancellation", h.Cancel).Methods(http.MethodPost)
+	r.HandleFunc("/booking/{id}/refunds", h.Refunds).Methods(http.MethodGet)
+}
+
+// Preview shows what cancelling would refund. passenger_ids, a
+// comma-separated list, limits it to some passengers.
+func (h *CancellationHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	request := model.CancellationRequest{}
+	if raw := r.URL.Query().Get("passenger_ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			passengerID, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, errors.New("passenger_ids must be comma-separated integers"))
+				return
+			}
+			request.PassengerIDs = append(request.PassengerIDs, passengerID)
+		}
+	}
+
+	cancellation, err := h.usecaseCancellation.Preview(ctx, id, &request)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cancellation)
+}
+
+// Cancel cancels the passengers listed in the body, or the whole booking
+// when there is no body or no passenger_ids.
+func (h *CancellationHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	cancellationPayload := model.CancellationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&cancellationPayload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	cancellation, err := h.usecaseCancellation.Cancel(ctx, id, &cancellationPayload)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cancellation)
+}
+
+func (h *CancellationHandler) Refunds(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	refunds, err := h.usecaseCancellation.Refunds(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, refunds)
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"booking_togo/internal/itinerary"
+	"booking_togo/internal/model"
+	"booking_togo/internal/usecase"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Media types a booking confirmation is served as, the default first.
+const (
+	mediaHTML = "text/html"
+	mediaPDF  = "application/pdf"
+	mediaJSON = "application/json"
+)
+
+type ConfirmationHandler struct {
+	usecaseConfirmation usecase.IConfirmationUsecase
+	renderer            *itinerary.Renderer
+	timeout             time.Duration
+}
+
+func NewConfirmationHandler(usecaseConfirmation usecase.IConfirmationUsecase, renderer *itinerary.Renderer, timeout time.Duration) *ConfirmationHandler {
+	return &ConfirmationHandler{
+		usecaseConfirmation: usecaseConfirmation,
+		renderer:            renderer,
+		timeout:             timeout,
+	}
+}
+
+func (h *ConfirmationHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/booking/{id}/confirmation", h.Confirmation).Methods(http.MethodGet)
+}
+
+// Confirmation serves the printable confirmation of a booking as HTML, PDF
+// or JSON, whichever the Accept header prefers; HTML when it has none.
+func (h *ConfirmationHandler) Confirmation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	mediaType := negotiate(r.Header.Get("Accept"), mediaHTML, mediaPDF, mediaJSON)
+	if mediaType == "" {
+		writeError(w, r, http.StatusNotAcceptable,
+			fmt.Errorf("confirmation is available as %s, %s or %s", mediaHTML, mediaPDF, mediaJSON))
+		return
+	}
+
+	confirmation, err := h.usecaseConfirmation.Confirmation(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	var render func(io.Writer, *model.Confirmation) error
+	switch mediaType {
+	case mediaJSON:
+		writeJSON(w, http.StatusOK, confirmation)
+		return
+	case mediaPDF:
+		render = h.renderer.PDF
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="booking-%d.pdf"`, id))
+	default:
+		render = h.renderer.HTML
+		mediaType += "; charset=utf-8"
+	}
+
+	// render fully first, so a template error is still a clean 500
+	var body bytes.Buffer
+	if err := render(&body, confirmation); err != nil {
+		writeUsecaseError(w, r, fmt.Errorf("render confirmation of booking %d: %w", id, err))
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	w.WriteHeader(http.StatusOK)
+	body.WriteTo(w)
+}
+
+// negotiate picks the offer the Accept header rates highest, the earlier
+// offer on a tie, or "" when none is acceptable. An empty header accepts
+// anything. Each offer is rated by the most specific media range matching
+// it.
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		offerType, _, _ := strings.Cut(offer, "/")
+		quality, specificity := 0.0, -1
+		for _, r := range ranges {
+			rangeSpecificity := -1
+			switch {
+			case r.mediaType == offer:
+				rangeSpecificity = 2
+			case r.mediaType == offerType+"/*":
+				rangeSpecificity = 1
+			case r.mediaType == "*/*":
+				rangeSpecificity = 0
+			}
+			if rangeSpecificity > specificity {
+				quality, specificity = r.quality, rangeSpecificity
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
@@ -0,0 +1,28 @@
+package http
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{mediaHTML, mediaPDF, mediaJSON}
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "no header", accept: "", want: mediaHTML},
+		{name: "exact type", accept: "application/pdf", want: mediaPDF},
+		{name: "browser default", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: mediaHTML},
+		{name: "highest quality wins", accept: "text/html;q=0.5, application/json", want: mediaJSON},
+		{name: "earlier offer on a tie", accept: "application/json, application/pdf", want: mediaPDF},
+		{name: "subtype wildcard", accept: "application/*", want: mediaPDF},
+		{name: "specific range overrides wildcard", accept: "application/*;q=0.9, application/pdf;q=0.1", want: mediaJSON},
+		{name: "excluded with q=0", accept: "*/*, text/html;q=0", want: mediaPDF},
+		{name: "malformed ranges skipped", accept: "text/html;q=high, ;;, application/json", want: mediaJSON},
+		{name: "nothing acceptable", accept: "image/png", want: ""},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, offers...); got != tt.want {
+			t.Errorf("%s: negotiate(%q) = %q, want %q", tt.name, tt.accept, got, tt.want)
+		}
+	}
+}
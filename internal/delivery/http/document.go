@@ -0,0 +1,154 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/usecase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type DocumentHandler struct {
+	usecaseDocument usecase.IDocumentUsecase
+	timeout         time.Duration
+}
+
+func NewDocumentHandler(usecaseDocument usecase.IDocumentUsecase, timeout time.Duration) *DocumentHandler {
+	return &DocumentHandler{
+		usecaseDocument: usecaseDocument,
+		timeout:         timeout,
+	}
+}
+
+func (h *DocumentHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/user/{id}/documents", h.GetByUser).Methods(http.MethodGet)
+	r.HandleFunc("/user/{id}/documents", h.Create).Methods(http.MethodPost)
+	r.HandleFunc("/user/{id}/documents/{document_id}", h.Detail).Methods(http.MethodGet)
+	r.HandleFunc("/user/{id}/documents/{document_id}", h.Update).Methods(http.MethodPut)
+	r.HandleFunc("/user/{id}/documents/{document_id}", h.Delete).Methods(http.MethodDelete)
+}
+
+// GetByUser lists the documents of a customer and their family with masked
+// numbers.
+func (h *DocumentHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	documents, err := h.usecaseDocument.GetByUser(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, documents)
+}
+
+func (h *DocumentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var documentPayload model.DocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&documentPayload); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	document, err := h.usecaseDocument.Create(ctx, id, &documentPayload)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, document)
+}
+
+// Detail returns one document with its full number.
+func (h *DocumentHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, documentID, err := documentVars(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	document, err := h.usecaseDocument.Detail(ctx, id, documentID)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, document)
+}
+
+func (h *DocumentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, documentID, err := documentVars(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var documentPayload model.DocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&documentPayload); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	document, err := h.usecaseDocument.Update(ctx, id, documentID, &documentPayload)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, document)
+}
+
+func (h *DocumentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, documentID, err := documentVars(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecaseDocument.Delete(ctx, id, documentID); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, "document deleted successfully")
+}
+
+func documentVars(r *http.Request) (int, int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, 0, err
+	}
+	documentID, err := strconv.Atoi(mux.Vars(r)["document_id"])
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, documentID, nil
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"errors"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// statusFor maps usecase errors to HTTP status codes: validation and domain
+// rule violations are the client's fault, anything unrecognised is ours.
+func statusFor(err error) int {
+	var (
+		validationErrors validation.Errors
+		validationError  validation.Error
+	)
+	switch {
+	case errors.As(err, &validationErrors), errors.As(err, &validationError),
+		errors.Is(err, model.ErrPassengerNotInFamily), errors.Is(err, model.ErrInvalidSignature):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeUsecaseError writes err with the status from statusFor. Internal
+// errors are logged and replaced by a generic message.
+func writeUsecaseError(w http.ResponseWriter, r *http.Request, err error) {
+	code := statusFor(err)
+	if code == http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Error("request failed: ", err)
+		err = errors.New(http.StatusText(code))
+	}
+	writeError(w, r, code, err)
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"booking_togo/internal/health"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+func (h *HealthHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/healthz", h.Liveness).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/readyz", h.Readiness).Methods(http.MethodGet, http.MethodHead)
+}
+
+// Liveness only reports that the process is serving HTTP; it never touches
+// dependencies so a database outage does not get the container restarted.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Readiness reports every dependency check and answers 503 when any fails or
+// the server is draining.
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Ready(r.Context())
+
+	code := http.StatusOK
+	if report.Status != health.StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/payment"
+	"booking_togo/internal/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxWebhookBytes bounds a webhook body, which is read whole to verify its
+// signature.
+const maxWebhookBytes = 1 << 20
+
+type PaymentHandler struct {
+	usecasePayment usecase.IPaymentUsecase
+	timeout        time.Duration
+}
+
+func NewPaymentHandler(usecasePayment usecase.IPaymentUsecase, timeout time.Duration) *PaymentHandler {
+	return &PaymentHandler{
+		usecasePayment: usecasePayment,
+		timeout:        timeout,
+	}
+}
+
+// RegisterRoutes registers the payment routes; the simulate route only when
+// the gateway is a development one.
+func (h *PaymentHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/booking/{id}/payments", h.GetByBooking).Methods(http.MethodGet)
+	r.HandleFunc("/booking/{id}/payments", h.Pay).Methods(http.MethodPost)
+	r.HandleFunc("/payment/webhook", h.Webhook).Methods(http.MethodPost)
+	r.HandleFunc("/payment/{id}", h.Detail).Methods(http.MethodGet)
+	if h.usecasePayment.CanSimulate() {
+		r.HandleFunc("/payment/{id}/simulate", h.Simulate).Methods(http.MethodPost)
+	}
+}
+
+func (h *PaymentHandler) GetByBooking(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payments, err := h.usecasePayment.GetByBooking(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payments)
+}
+
+// Pay starts paying a held booking and returns the payment with the client
+// secret to complete it.
+func (h *PaymentHandler) Pay(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payment, err := h.usecasePayment.Pay(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, payment)
+}
+
+func (h *PaymentHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payment, err := h.usecasePayment.Detail(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
+
+// Webhook receives gateway notifications. Applied and redelivered events are
+// acknowledged with 204 and forged ones rejected with 400; any other error
+// status makes the gateway retry.
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecasePayment.HandleWebhook(ctx, payload, r.Header.Get(payment.SignatureHeader)); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Simulate settles a pending payment on the development gateway, as if the
+// customer had paid ({"status": "succeeded"}) or the payment had failed.
+func (h *PaymentHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	simulatePayload := model.SimulatePaymentRequest{Status: model.PaymentSucceeded}
+	if err := json.NewDecoder(r.Body).Decode(&simulatePayload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	payment, err := h.usecasePayment.Simulate(ctx, id, simulatePayload.Status)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, payment)
+}
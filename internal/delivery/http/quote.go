@@ -0,0 +1,48 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/usecase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type QuoteHandler struct {
+	usecaseQuote usecase.IQuoteUsecase
+	timeout      time.Duration
+}
+
+func NewQuoteHandler(usecaseQuote usecase.IQuoteUsecase, timeout time.Duration) *QuoteHandler {
+	return &QuoteHandler{
+		usecaseQuote: usecaseQuote,
+		timeout:      timeout,
+	}
+}
+
+func (h *QuoteHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/quote", h.Quote).Methods(http.MethodPost)
+}
+
+// Quote prices a booking request without creating the booking.
+func (h *QuoteHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	var quotePayload model.BookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&quotePayload); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	quote, err := h.usecaseQuote.Quote(ctx, &quotePayload)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quote)
+}
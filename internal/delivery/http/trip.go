@@ -0,0 +1,81 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/usecase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type TripHandler struct {
+	usecaseTrip usecase.ITripUsecase
+	timeout     time.Duration
+}
+
+func NewTripHandler(usecaseTrip usecase.ITripUsecase, timeout time.Duration) *TripHandler {
+	return &TripHandler{
+		usecaseTrip: usecaseTrip,
+		timeout:     timeout,
+	}
+}
+
+func (h *TripHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/trip", h.GetAll).Methods(http.MethodGet)
+	r.HandleFunc("/trip", h.Create).Methods(http.MethodPost)
+	r.HandleFunc("/trip/{id}", h.Detail).Methods(http.MethodGet)
+}
+
+func (h *TripHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	trips, err := h.usecaseTrip.GetAll(ctx)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, trips)
+}
+
+func (h *TripHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	var tripPayload model.Trip
+	if err := json.NewDecoder(r.Body).Decode(&tripPayload); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.usecaseTrip.Create(ctx, &tripPayload); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tripPayload)
+}
+
+func (h *TripHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	trip, err := h.usecaseTrip.Detail(ctx, id)
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, trip)
+}
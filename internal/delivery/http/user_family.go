@@ -1,10 +1,12 @@
 package http
 
 import (
+	"booking_togo/internal/logger"
 	"booking_togo/internal/model"
 	"booking_togo/internal/usecase"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,11 +16,13 @@ import (
 
 type UserFamilyHandler struct {
 	usecaseuser usecase.IUserUsecase
+	timeout     time.Duration
 }
 
-func NewUserFamilyHandler(usecaseuser usecase.IUserUsecase) *UserFamilyHandler {
+func NewUserFamilyHandler(usecaseuser usecase.IUserUsecase, timeout time.Duration) *UserFamilyHandler {
 	return &UserFamilyHandler{
 		usecaseuser: usecaseuser,
+		timeout:     timeout,
 	}
 
 }
@@ -33,13 +37,24 @@ func (h *UserFamilyHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/user/{id}/family/{family_id}", h.FamilyDelete).Methods(http.MethodDelete)
 }
 
+// GetAll lists every customer, or searches them when an email or phone
+// query parameter is given.
 func (h *UserFamilyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
-	users, err := h.usecaseuser.GetAll(ctx)
+	var (
+		users []*model.UserDetailResponse
+		err   error
+	)
+	query := r.URL.Query()
+	if query.Has("email") || query.Has("phone") {
+		users, err = h.usecaseuser.Search(ctx, model.UserSearch{Email: query.Get("email"), Phone: query.Get("phone")})
+	} else {
+		users, err = h.usecaseuser.GetAll(ctx)
+	}
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -47,17 +62,17 @@ func (h *UserFamilyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserFamilyHandler) CreateUserFamily(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
 	var userFamilyPayload model.User
 	if err := json.NewDecoder(r.Body).Decode(&userFamilyPayload); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := h.usecaseuser.Create(ctx, &userFamilyPayload); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, userErrorStatus(err), err)
 		return
 	}
 
@@ -65,91 +80,111 @@ func (h *UserFamilyHandler) CreateUserFamily(w http.ResponseWriter, r *http.Requ
 }
 
 func (h *UserFamilyHandler) UserDetail(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	userDetail, userDetailErr := h.usecaseuser.Detail(ctx, id)
 	if userDetailErr != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": userDetailErr.Error()})
+		writeError(w, r, userErrorStatus(userDetailErr), userDetailErr)
 		return
 	}
 	writeJSON(w, http.StatusOK, userDetail)
 }
 
 func (h *UserFamilyHandler) UserUpdate(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	var userFamilyPayload model.User
 	if err := json.NewDecoder(r.Body).Decode(&userFamilyPayload); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	userFamilyPayload.UserID = id
 	userUpdatelErr := h.usecaseuser.Update(ctx, &userFamilyPayload)
 	if userUpdatelErr != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": userUpdatelErr.Error()})
+		writeError(w, r, userErrorStatus(userUpdatelErr), userUpdatelErr)
 		return
 	}
 	writeJSON(w, http.StatusOK, "User updated successfully")
 }
 
 func (h *UserFamilyHandler) UserDelete(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	userDetailErr := h.usecaseuser.Delete(ctx, id)
 	if userDetailErr != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": userDetailErr.Error()})
+		writeError(w, r, userErrorStatus(userDetailErr), userDetailErr)
 		return
 	}
 	writeJSON(w, http.StatusOK, "User deleted successfully")
 }
 
 func (h *UserFamilyHandler) FamilyDelete(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	familyID, err := strconv.Atoi(mux.Vars(r)["family_id"])
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	familyDeleteErr := h.usecaseuser.DeleteFamily(ctx, id, familyID)
 	if familyDeleteErr != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": familyDeleteErr.Error()})
+		writeError(w, r, userErrorStatus(familyDeleteErr), familyDeleteErr)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, "family deleted successfully")
 }
 
+// userErrorStatus is 409 for an email already in use or a booked customer
+// or family member and, as the customer routes always answered, 400 for
+// anything else.
+func userErrorStatus(err error) int {
+	if errors.Is(err, model.ErrConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// writeError writes the standard error body, tagged with the request ID so
+// clients can quote it when reporting a problem.
+func writeError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	body := map[string]string{"error": err.Error()}
+	if requestID := logger.RequestIDFromContext(r.Context()); requestID != "" {
+		body["request_id"] = requestID
+	}
+	writeJSON(w, code, body)
+}
+
 func writeJSON(w http.ResponseWriter, code int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/usecase"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// userRoutes serves the customer routes on an in-memory repository holding
+// one customer, Budi, with one family member.
+type userRoutes struct {
+	router        *mux.Router
+	nationalityID int
+	userID        int
+	familyID      int
+}
+
+func newUserRoutes(t *testing.T) *userRoutes {
+	t.Helper()
+	repo := repository.NewInMemoryUserRepository()
+	routes := &userRoutes{router: mux.NewRouter(), nationalityID: repo.AddNationality("Indonesia", "ID")}
+	NewUserFamilyHandler(usecase.NewUserUsecase(repo, repo.Nationalities()), time.Second).RegisterRoutes(routes.router)
+
+	if resp := routes.serve(t, http.MethodPost, "/user", routes.user("budi@example.com")); resp.Code != http.StatusCreated {
+		t.Fatalf("create customer: status = %d: %s", resp.Code, resp.Body)
+	}
+	var users []*model.UserDetailResponse
+	routes.decode(t, routes.serve(t, http.MethodGet, "/user", nil), &users)
+	routes.userID, routes.familyID = users[0].UserID, users[0].Families[0].FamilyID
+	return routes
+}
+
+func (routes *userRoutes) user(email string) model.User {
+	return model.User{Name: "Budi Santoso", Dob: "1980-01-01", NationalityID: routes.nationalityID, Email: email,
+		Families: []model.Family{{Name: "Siti Aminah", Dob: "1982-01-01"}}}
+}
+
+func (routes *userRoutes) serve(t *testing.T, method, path string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	switch payload := payload.(type) {
+	case nil:
+	case string:
+		body.WriteString(payload)
+	default:
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp := httptest.NewRecorder()
+	routes.router.ServeHTTP(resp, httptest.NewRequest(method, path, &body))
+	return resp
+}
+
+func (routes *userRoutes) decode(t *testing.T, resp *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", resp.Body, err)
+	}
+}
+
+// wantError checks for the standard error body with status code and a
+// message containing want.
+func wantError(t *testing.T, resp *httptest.ResponseRecorder, code int, want string) {
+	t.Helper()
+	if resp.Code != code {
+		t.Fatalf("status = %d, want %d: %s", resp.Code, code, resp.Body)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || !strings.Contains(body["error"], want) {
+		t.Fatalf("error body = %v (%v), want one about %q", body, err, want)
+	}
+}
+
+func TestUserFamilyCreate(t *testing.T) {
+	routes := newUserRoutes(t)
+
+	resp := routes.serve(t, http.MethodPost, "/user", routes.user("sari@example.com"))
+	if resp.Code != http.StatusCreated || !strings.Contains(resp.Body.String(), "User created successfully") {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body)
+	}
+
+	short := routes.user("")
+	short.Name = "Budi"
+	wantError(t, routes.serve(t, http.MethodPost, "/user", short), http.StatusBadRequest, "name")
+	wantError(t, routes.serve(t, http.MethodPost, "/user", routes.user("BUDI@example.com")), http.StatusConflict, "email")
+	wantError(t, routes.serve(t, http.MethodPost, "/user", `{"name": `), http.StatusBadRequest, "unexpected EOF")
+}
+
+func TestUserFamilyGetAll(t *testing.T) {
+	routes := newUserRoutes(t)
+	if resp := routes.serve(t, http.MethodPost, "/user", routes.user("sari@example.com")); resp.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", resp.Code, resp.Body)
+	}
+
+	var users []*model.UserDetailResponse
+	routes.decode(t, routes.serve(t, http.MethodGet, "/user", nil), &users)
+	if len(users) != 2 {
+		t.Fatalf("users = %+v, want 2", users)
+	}
+
+	routes.decode(t, routes.serve(t, http.MethodGet, "/user?email=Budi@Example.com", nil), &users)
+	if len(users) != 1 || users[0].UserID != routes.userID {
+		t.Fatalf("search by email = %+v, want customer %d", users, routes.userID)
+	}
+	routes.decode(t, routes.serve(t, http.MethodGet, "/user?email=nobody@example.com", nil), &users)
+	if len(users) != 0 {
+		t.Fatalf("search for an unknown email = %+v, want none", users)
+	}
+}
+
+func TestUserFamilyDetail(t *testing.T) {
+	routes := newUserRoutes(t)
+
+	var user model.UserDetailResponse
+	routes.decode(t, routes.serve(t, http.MethodGet, fmt.Sprintf("/user/%d", routes.userID), nil), &user)
+	if user.Name != "Budi Santoso" || user.Nationality.NationalityCode != "ID" || len(user.Families) != 1 {
+		t.Fatalf("detail = %+v", user)
+	}
+
+	wantError(t, routes.serve(t, http.MethodGet, fmt.Sprintf("/user/%d", 999), nil), http.StatusBadRequest, "no rows")
+	wantError(t, routes.serve(t, http.MethodGet, "/user/budi", nil), http.StatusBadRequest, "invalid syntax")
+}
+
+func TestUserFamilyUpdate(t *testing.T) {
+	routes := newUserRoutes(t)
+	path := fmt.Sprintf("/user/%d", routes.userID)
+
+	update := routes.user("budi.santoso@example.com")
+	update.Families = []model.Family{{FamilyID: routes.familyID, UserID: routes.userID, Name: "Siti Santoso", Dob: "1982-01-01"}}
+	resp := routes.serve(t, http.MethodPut, path, update)
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "User updated successfully") {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body)
+	}
+	var user model.UserDetailResponse
+	routes.decode(t, routes.serve(t, http.MethodGet, path, nil), &user)
+	if user.Email != "budi.santoso@example.com" || len(user.Families) != 1 || user.Families[0].Name != "Siti Santoso" {
+		t.Fatalf("after update = %+v", user)
+	}
+
+	invalid := routes.user("")
+	invalid.Dob = "1980-02-30"
+	wantError(t, routes.serve(t, http.MethodPut, path, invalid), http.StatusBadRequest, "date")
+	unknown := routes.user("")
+	unknown.Families = nil
+	wantError(t, routes.serve(t, http.MethodPut, fmt.Sprintf("/user/%d", 999), unknown), http.StatusBadRequest, "no rows")
+	wantError(t, routes.serve(t, http.MethodPut, path, `[]`), http.StatusBadRequest, "cannot unmarshal")
+}
+
+func TestUserFamilyDelete(t *testing.T) {
+	routes := newUserRoutes(t)
+	path := fmt.Sprintf("/user/%d", routes.userID)
+
+	resp := routes.serve(t, http.MethodDelete, fmt.Sprintf("/user/%d/family/%d", routes.userID, routes.familyID), nil)
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "family deleted successfully") {
+		t.Fatalf("delete family: status = %d: %s", resp.Code, resp.Body)
+	}
+	var user model.UserDetailResponse
+	routes.decode(t, routes.serve(t, http.MethodGet, path, nil), &user)
+	if len(user.Families) != 0 {
+		t.Fatalf("families after delete = %+v", user.Families)
+	}
+	wantError(t, routes.serve(t, http.MethodDelete, fmt.Sprintf("/user/%d/family/sari", routes.userID), nil), http.StatusBadRequest, "invalid syntax")
+
+	resp = routes.serve(t, http.MethodDelete, path, nil)
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "User deleted successfully") {
+		t.Fatalf("delete customer: status = %d: %s", resp.Code, resp.Body)
+	}
+	wantError(t, routes.serve(t, http.MethodGet, path, nil), http.StatusBadRequest, "no rows")
+	wantError(t, routes.serve(t, http.MethodDelete, "/user/budi", nil), http.StatusBadRequest, "invalid syntax")
+}
@@ -0,0 +1,149 @@
+package health
+
+import (
+	"booking_togo/internal/migration"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// CheckResult is the JSON detail reported for one dependency.
+type CheckResult struct {
+	Status   string         `json:"status"`
+	Duration string         `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// Report is the body returned by the readiness endpoint.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckFunc probes one dependency. Details are reported even on failure.
+type CheckFunc func(ctx context.Context) (details map[string]any, err error)
+
+// Checker runs the registered dependency checks and owns the drain flag that
+// fails readiness while the server shuts down.
+type Checker struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks map[string]CheckFunc
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  map[string]CheckFunc{},
+	}
+}
+
+// Register adds a named readiness check.
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// SetDraining flips readiness to failing so load balancers stop routing new
+// traffic before the HTTP server is shut down.
+func (c *Checker) SetDraining(draining bool) {
+	c.draining.Store(draining)
+}
+
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Ready runs every check concurrently, each bounded by the checker timeout.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]CheckFunc, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks)+1)}
+	if c.Draining() {
+		report.Status = StatusFail
+		report.Checks["shutdown"] = CheckResult{Status: StatusFail, Error: "server is draining"}
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			details, err := check(checkCtx)
+			result := CheckResult{Status: StatusOK, Duration: time.Since(start).String(), Details: details}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// DatabaseCheck pings the pool and reports its connection counts.
+func DatabaseCheck(db *pgxpool.Pool) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		stat := db.Stat()
+		details := map[string]any{
+			"total_conns":    stat.TotalConns(),
+			"idle_conns":     stat.IdleConns(),
+			"acquired_conns": stat.AcquiredConns(),
+		}
+		return details, db.Ping(ctx)
+	}
+}
+
+// MigrationCheck fails until the database schema is at the version embedded
+// in this binary.
+func MigrationCheck(db *pgxpool.Pool) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		expected := migration.LatestVersion()
+		details := map[string]any{"expected_version": expected}
+
+		current, err := migration.CurrentVersion(ctx, db)
+		if err != nil {
+			return details, err
+		}
+		details["current_version"] = current
+
+		if current != expected {
+			return details, fmt.Errorf("schema version %d, expected %d", current, expected)
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,89 @@
+// Package itinerary renders booking confirmations for customers to print,
+// as HTML or as PDF, from the templates in templates/.
+package itinerary
+
+import (
+	"booking_togo/internal/model"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Renderer renders model.Confirmation values. It is safe for concurrent use.
+type Renderer struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// New parses the embedded templates.
+func New() (*Renderer, error) {
+	html, err := htmltemplate.New("confirmation.html.tmpl").Funcs(htmltemplate.FuncMap(funcs)).
+		ParseFS(templates, "templates/confirmation.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("parse HTML template: %w", err)
+	}
+	text, err := texttemplate.New("confirmation.txt.tmpl").Funcs(texttemplate.FuncMap(funcs)).
+		ParseFS(templates, "templates/confirmation.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("parse text template: %w", err)
+	}
+	return &Renderer{html: html, text: text}, nil
+}
+
+// HTML writes confirmation as a standalone HTML page.
+func (r *Renderer) HTML(w io.Writer, confirmation *model.Confirmation) error {
+	return r.html.Execute(w, confirmation)
+}
+
+// PDF writes confirmation as an A4 PDF. The text template is laid out line
+// by line; lines starting with "# " are headings.
+func (r *Renderer) PDF(w io.Writer, confirmation *model.Confirmation) error {
+	var text bytes.Buffer
+	if err := r.text.Execute(&text, confirmation); err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(text.String(), "\n"), "\n")
+	title := fmt.Sprintf("Booking confirmation #%d", confirmation.Booking.BookingID)
+	return writePDF(w, title, lines)
+}
+
+var funcs = map[string]any{
+	"money":       formatMoney,
+	"datetime":    func(t time.Time) string { return t.UTC().Format("02 Jan 2006 15:04 UTC") },
+	"date":        func(t time.Time) string { return t.UTC().Format("02 Jan 2006") },
+	"nationality": formatNationality,
+}
+
+// formatMoney prints an amount in the smallest unit of currency with
+// thousands separators, e.g. "IDR 1,250,000".
+func formatMoney(currency string, amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return currency + " " + sign + grouped.String()
+}
+
+func formatNationality(nationality model.Nationality) string {
+	if nationality.NationalityName == "" {
+		return "-"
+	}
+	return nationality.NationalityName + " (" + nationality.NationalityCode + ")"
+}
@@ -0,0 +1,144 @@
+package itinerary
+
+import (
+	"booking_togo/internal/model"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleConfirmation() *model.Confirmation {
+	indonesia := model.Nationality{NationalityID: 1, NationalityName: "Indonesia", NationalityCode: "ID"}
+	departure := time.Date(2026, 7, 1, 8, 30, 0, 0, time.UTC)
+	cancelled := departure.Add(-72 * time.Hour)
+	paidAt := departure.Add(-30 * 24 * time.Hour)
+
+	return &model.Confirmation{
+		Booking: &model.Booking{BookingID: 42, UserID: 7, TripID: 3, FareClass: model.FareClassFlex, Status: model.BookingConfirmed},
+		Customer: &model.UserDetailResponse{UserID: 7, Name: "Budi <Santoso>", Dob: "1980-01-01", Nationality: indonesia,
+			Email: "budi@example.com"},
+		Passengers: []model.ConfirmationPassenger{
+			{Passenger: model.Passenger{PassengerID: 1, IsCustomer: true, Name: "Budi <Santoso>", Dob: "1980-01-01"}, Nationality: indonesia},
+			{Passenger: model.Passenger{PassengerID: 2, FamilyID: 9, Name: "Siti (Aminah)", Dob: "2015-01-01", CancelledAt: &cancelled},
+				Nationality: model.Nationality{NationalityName: "Japan", NationalityCode: "JP"}},
+		},
+		Trip: &model.Trip{TripID: 3, TripCode: "JKT-DPS-1", Origin: "Jakarta", Destination: "Denpasar", DepartureAt: departure},
+		Quote: &model.Quote{Currency: "IDR", Subtotal: 1500000, Total: 1350000,
+			Items: []model.QuoteItem{
+				{PassengerID: 1, Name: "Budi <Santoso>", Band: model.AgeBandAdult, BaseFare: 1000000, FarePercent: 100, Fare: 1000000},
+				{PassengerID: 2, Name: "Siti (Aminah)", Band: model.AgeBandChild, BaseFare: 1000000, FarePercent: 50, Fare: 500000},
+			},
+			Discounts: []model.QuoteDiscount{{Code: "early", Description: "Early booking", Percent: 10, Amount: 150000}},
+		},
+		Payment:  &model.Payment{Status: model.PaymentSucceeded, Amount: 1350000, Currency: "IDR", PaidAt: &paidAt},
+		IssuedAt: departure.Add(-24 * time.Hour),
+	}
+}
+
+func TestRendererHTML(t *testing.T) {
+	renderer, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := renderer.HTML(&out, sampleConfirmation()); err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	html := out.String()
+	for _, want := range []string{
+		"Booking confirmation #42",
+		"Budi &lt;Santoso&gt;",
+		"Japan (JP)",
+		"Cancelled 28 Jun 2026",
+		"JKT-DPS-1",
+		"01 Jul 2026 08:30 UTC",
+		"IDR 1,500,000",
+		"-IDR 150,000",
+		"IDR 1,350,000",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<Santoso>") {
+		t.Error("HTML does not escape the customer name")
+	}
+}
+
+func TestRendererPDF(t *testing.T) {
+	renderer, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmation := sampleConfirmation()
+	confirmation.Trip, confirmation.Quote, confirmation.Payment = nil, nil, nil
+	// two lines per cancelled passenger fill three pages
+	for i := 0; i < 60; i++ {
+		confirmation.Passengers = append(confirmation.Passengers, confirmation.Passengers[1])
+	}
+
+	var out bytes.Buffer
+	if err := renderer.PDF(&out, confirmation); err != nil {
+		t.Fatalf("PDF: %v", err)
+	}
+	pdf := out.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("not a PDF file")
+	}
+	for _, want := range []string{"(Budi <Santoso> \\(customer\\)", "\\(Aminah\\)", "No trip has been chosen", "/Count 3", "(Page 3 of 3)"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+
+	// every xref entry points at its object
+	xref, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)[1])
+	if err != nil || !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllStringSubmatch(pdf[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+	for _, line := range wrap([]string{long, "# " + long, strings.Repeat("x", 100)}) {
+		width := bodyColumns
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			width, line = headingColumns, heading
+		}
+		if len(line) > width {
+			t.Errorf("line of %d characters is wider than %d: %q", len(line), width, line)
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{0, "IDR 0"},
+		{999, "IDR 999"},
+		{1000, "IDR 1,000"},
+		{1250000, "IDR 1,250,000"},
+		{-150000, "IDR -150,000"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney("IDR", tt.amount); got != tt.want {
+			t.Errorf("formatMoney(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package itinerary
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page layout in PDF points: A4 with a 50pt margin. Body text is 10pt
+// Courier, whose glyphs are 6pt wide, so columns in the text template line
+// up; headings are 12pt Courier-Bold.
+const (
+	pageWidth      = 595
+	pageHeight     = 842
+	margin         = 50
+	leading        = 14
+	linesPerPage   = (pageHeight - 2*margin) / leading
+	bodyColumns    = (pageWidth - 2*margin) * 10 / 60
+	headingColumns = (pageWidth - 2*margin) * 10 / 72
+)
+
+// writePDF writes lines as a PDF document with the standard Courier fonts,
+// wrapping long lines and breaking pages as needed. Text outside Latin-1
+// is replaced by "?".
+func writePDF(w io.Writer, title string, lines []string) error {
+	pages := paginate(wrap(lines))
+
+	// objects 1-5 are fixed, then a page and its content stream per page
+	const (
+		catalogID = 1
+		pagesID   = 2
+		bodyFont  = 3
+		boldFont  = 4
+		infoID    = 5
+	)
+	pageID := func(i int) int { return 6 + 2*i }
+
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(id int, body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", id, body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageID(i))
+	}
+	object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %d %d] >>",
+		strings.Join(kids, " "), len(pages), pageWidth, pageHeight))
+	object(bodyFont, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object(boldFont, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	object(infoID, fmt.Sprintf("<< /Title (%s) /Producer (booking_togo) >>", pdfString(title)))
+
+	for i, page := range pages {
+		content := pageContent(page, i+1, len(pages))
+		object(pageID(i), fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+			pagesID, pageID(i)+1, bodyFont, boldFont))
+		object(pageID(i)+1, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalogID, infoID, xref)
+
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// pageContent draws the lines of one page top down, with its number in the
+// bottom margin.
+func pageContent(lines []string, number, total int) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n%d TL\n%d %d Td\n", leading, margin, pageHeight-margin-leading)
+	for _, line := range lines {
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			fmt.Fprintf(&content, "/F2 12 Tf (%s) Tj T*\n", pdfString(heading))
+		} else {
+			fmt.Fprintf(&content, "/F1 10 Tf (%s) Tj T*\n", pdfString(line))
+		}
+	}
+	content.WriteString("ET\n")
+	fmt.Fprintf(&content, "BT\n/F1 8 Tf %d %d Td (Page %d of %d) Tj\nET", margin, margin/2, number, total)
+	return content.String()
+}
+
+// wrap splits lines longer than a page is wide at spaces, or anywhere when
+// a word alone is too long. Headings stay headings.
+func wrap(lines []string) []string {
+	wrapped := []string{}
+	for _, line := range lines {
+		prefix, width := "", bodyColumns
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			prefix, width, line = "# ", headingColumns, heading
+		}
+		line = strings.TrimRight(line, " \r")
+		for len([]rune(line)) > width {
+			runes := []rune(line)
+			cut := strings.LastIndex(string(runes[:width+1]), " ")
+			if cut <= 0 {
+				cut = len(string(runes[:width]))
+			}
+			wrapped = append(wrapped, prefix+strings.TrimRight(line[:cut], " "))
+			line = strings.TrimLeft(line[cut:], " ")
+		}
+		wrapped = append(wrapped, prefix+line)
+	}
+	return wrapped
+}
+
+// paginate splits lines into pages, keeping a heading with the line after
+// it. There is always at least one page.
+func paginate(lines []string) [][]string {
+	pages := [][]string{{}}
+	for i, line := range lines {
+		page := pages[len(pages)-1]
+		room := linesPerPage - len(page)
+		if room == 0 || (room == 1 && strings.HasPrefix(line, "# ") && i+1 < len(lines)) {
+			pages = append(pages, []string{})
+		}
+		// a page does not start with blank lines
+		if len(pages[len(pages)-1]) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], line)
+	}
+	return pages
+}
+
+// winAnsi maps the characters of Windows-1252 outside Latin-1 to their
+// codes.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfString encodes s in WinAnsiEncoding and escapes it for a PDF literal
+// string.
+func pdfString(s string) string {
+	var encoded strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			encoded.WriteByte('\\')
+			encoded.WriteRune(r)
+		case r < 0x20:
+			encoded.WriteByte(' ')
+		case r < 0x7f:
+			encoded.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			encoded.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			encoded.WriteByte(winAnsi[r])
+		default:
+			encoded.WriteByte('?')
+		}
+	}
+	return encoded.String()
+}
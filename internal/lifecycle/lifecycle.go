@@ -0,0 +1,100 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Manager owns the shared context of background workers and the ordered list
+// of stop hooks run on shutdown. Hooks run in reverse registration order,
+// like defers, so a component is stopped before what it depends on.
+type Manager struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu    sync.Mutex
+	hooks []hook
+}
+
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Context is canceled when the workers are stopped.
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Go runs fn as a background worker. fn must return once ctx is done.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		fn(m.ctx)
+		log.Debugf("background worker %s stopped", name)
+	}()
+}
+
+// StopWorkers cancels the shared context and waits for every worker started
+// with Go, or for ctx to expire. Register it with OnStop to place it in the
+// shutdown order.
+func (m *Manager) StopWorkers(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background workers did not stop: %w", ctx.Err())
+	}
+}
+
+// OnStop registers a named stop hook.
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Shutdown runs every hook in reverse order, even after a failure, and
+// returns all errors joined. ctx bounds the whole sequence.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		start := time.Now()
+		if err := hooks[i].stop(ctx); err != nil {
+			log.Errorf("shutdown: %s failed after %s: %v", hooks[i].name, time.Since(start), err)
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+			continue
+		}
+		log.Infof("shutdown: %s stopped in %s", hooks[i].name, time.Since(start))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type entryKey struct{}
+type requestIDKey struct{}
+
+// WithEntry returns a copy of ctx carrying a request-scoped logrus entry.
+func WithEntry(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// FromContext returns the request-scoped entry stored in ctx, or an entry on
+// the standard logger when the context carries none (background jobs, tests).
+func FromContext(ctx context.Context) *log.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(entryKey{}).(*log.Entry); ok {
+			return entry
+		}
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// WithRequestID returns a copy of ctx carrying the request correlation ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the correlation ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// Forwarding headers a ClientIPResolver can read the client address from.
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderForwarded     = "Forwarded"
+)
+
+// ClientIPResolver resolves the real client address of a request, only
+// trusting forwarding headers when the direct peer is a known proxy.
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+	header         string
+}
+
+// NewClientIPResolver builds a resolver from a list of CIDRs (or bare IPs)
+// describing the proxies allowed to forward requests, and the one header,
+// HeaderXForwardedFor or HeaderForwarded, those proxies append the client
+// to. The other header is ignored: a proxy that does not set it passes
+// along whatever the client sent.
+func NewClientIPResolver(trustedProxies []string, header string) (*ClientIPResolver, error) {
+	if !strings.EqualFold(header, HeaderXForwardedFor) && !strings.EqualFold(header, HeaderForwarded) {
+		return nil, fmt.Errorf("forwarding header must be %s or %s, got %q", HeaderXForwardedFor, HeaderForwarded, header)
+	}
+	resolver := &ClientIPResolver{header: http.CanonicalHeaderKey(header)}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			proxy = fmt.Sprintf("%s/%d", ip.String(), bits)
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, network)
+	}
+
+	return resolver, nil
+}
+
+// Middleware resolves the client IP once and stores it in the request context
+// so that every middleware and handler down the chain sees the same value.
+func (c *ClientIPResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientIPKey{}, c.Resolve(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Resolve returns the client IP for r. The configured forwarding header is
+// walked right to left and the first hop that is not a trusted proxy is the
+// client.
+func (c *ClientIPResolver) Resolve(r *http.Request) string {
+	remoteIP := parseHost(r.RemoteAddr)
+	if remoteIP == nil {
+		return r.RemoteAddr
+	}
+
+	if !c.isTrusted(remoteIP) {
+		return remoteIP.String()
+	}
+
+	var hops []string
+	if c.header == HeaderForwarded {
+		hops = forwardedFor(r.Header.Values(HeaderForwarded))
+	} else {
+		hops = xForwardedFor(r.Header.Values(HeaderXForwardedFor))
+	}
+
+	last := remoteIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseHost(hops[i])
+		if ip == nil {
+			// A malformed hop cannot be trusted, so stop at the last good one.
+			break
+		}
+		last = ip
+		if !c.isTrusted(ip) {
+			break
+		}
+	}
+
+	return last.String()
+}
+
+func (c *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range c.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIPFromContext returns the IP stored by ClientIPResolver.Middleware.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// xForwardedFor flattens one or more X-Forwarded-For headers into hops,
+// ordered from the original client to the closest proxy.
+func xForwardedFor(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedFor extracts the for= parameters of RFC 7239 Forwarded headers.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				hops = append(hops, strings.Trim(val, `"`))
+			}
+		}
+	}
+	return hops
+}
+
+// parseHost parses an IP optionally carrying a port, including the bracketed
+// IPv6 form used by RemoteAddr and the Forwarded header ("[2001:db8::1]:443").
+func parseHost(value string) net.IP {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	return net.ParseIP(value)
+}
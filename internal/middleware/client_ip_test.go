@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer ignores forwarding headers",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "203.0.113.9:4000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "rightmost untrusted hop is the client",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.66, 198.51.100.1, 10.0.0.5"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "client-sent Forwarded is ignored behind an X-Forwarded-For proxy",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"Forwarded": "for=192.0.2.66", "X-Forwarded-For": "198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "client-sent X-Forwarded-For is ignored behind a Forwarded proxy",
+			header:     HeaderForwarded,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"Forwarded": `for="[2001:db8::1]:443";proto=https`, "X-Forwarded-For": "192.0.2.66"},
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "X-Real-IP is never trusted",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Real-IP": "192.0.2.66"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "malformed hop stops at the last valid one",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, not-an-ip, 10.0.0.5"},
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "only trusted hops gives the leftmost",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.7, 10.0.0.5"},
+			want:       "10.0.0.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"}, tt.header)
+			if err != nil {
+				t.Fatalf("NewClientIPResolver: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			if got := resolver.Resolve(r); got != tt.want {
+				t.Fatalf("Resolve = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientIPResolverRejectsBadInput(t *testing.T) {
+	if _, err := NewClientIPResolver([]string{"10.0.0.0/33"}, HeaderXForwardedFor); err == nil {
+		t.Error("accepted an invalid CIDR")
+	}
+	if _, err := NewClientIPResolver(nil, "X-Real-IP"); err == nil {
+		t.Error("accepted X-Real-IP as forwarding header")
+	}
+}
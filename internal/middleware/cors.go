@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/handlers"
+)
+
+// CORSPolicy describes which browser origins may call the API.
+// AllowedOrigins entries are either "*", an exact origin
+// ("https://booking.example.com") or a wildcard subdomain pattern
+// ("https://*.example.com", which does not match the apex domain).
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// NewCORS builds the CORS middleware for policy on top of gorilla/handlers.
+func NewCORS(policy CORSPolicy) (func(http.Handler) http.Handler, error) {
+	matcher, err := newOriginMatcher(policy.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	if matcher.any && policy.AllowCredentials {
+		return nil, fmt.Errorf("CORS credentials cannot be allowed for the wildcard origin \"*\"")
+	}
+
+	options := []handlers.CORSOption{
+		handlers.AllowedMethods(policy.AllowedMethods),
+		handlers.AllowedHeaders(policy.AllowedHeaders),
+		handlers.ExposedHeaders(policy.ExposedHeaders),
+		handlers.MaxAge(int(policy.MaxAge.Seconds())),
+	}
+	if policy.AllowCredentials {
+		options = append(options, handlers.AllowCredentials())
+	}
+
+	if matcher.any {
+		options = append(options, handlers.AllowedOrigins([]string{"*"}))
+		return handlers.CORS(options...), nil
+	}
+
+	options = append(options, handlers.AllowedOriginValidator(matcher.match))
+	cors := handlers.CORS(options...)
+
+	return func(next http.Handler) http.Handler {
+		corsHandler := cors(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the response depends on Origin whenever it is echoed back
+			w.Header().Add("Vary", "Origin")
+			corsHandler.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// CORSHandler applies a CORS policy that can be replaced while serving.
+type CORSHandler struct {
+	next    http.Handler
+	current atomic.Pointer[http.Handler]
+}
+
+func NewCORSHandler(policy CORSPolicy, next http.Handler) (*CORSHandler, error) {
+	h := &CORSHandler{next: next}
+	if err := h.Update(policy); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Update swaps in policy; an invalid policy is rejected and the current one
+// stays in effect.
+func (h *CORSHandler) Update(policy CORSPolicy) error {
+	cors, err := NewCORS(policy)
+	if err != nil {
+		return err
+	}
+	handler := cors(h.next)
+	h.current.Store(&handler)
+	return nil
+}
+
+func (h *CORSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.current.Load()).ServeHTTP(w, r)
+}
+
+type originMatcher struct {
+	any       bool
+	exact     map[string]bool
+	wildcards []wildcardOrigin
+}
+
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".example.com", with an optional ":port"
+}
+
+func newOriginMatcher(origins []string) (*originMatcher, error) {
+	m := &originMatcher{exact: map[string]bool{}}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "":
+			continue
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*.")
+			if scheme == "" || host == "" || strings.ContainsAny(host, "*/") {
+				return nil, fmt.Errorf("invalid CORS origin pattern %q", origin)
+			}
+			m.wildcards = append(m.wildcards, wildcardOrigin{scheme: scheme, suffix: "." + host})
+		default:
+			u, err := url.Parse(origin)
+			if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || strings.Contains(origin, "*") {
+				return nil, fmt.Errorf("invalid CORS origin %q", origin)
+			}
+			m.exact[u.Scheme+"://"+u.Host] = true
+		}
+	}
+	return m, nil
+}
+
+func (m *originMatcher) match(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if m.exact[origin] {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || host == "" {
+		return false
+	}
+	for _, wildcard := range m.wildcards {
+		if scheme != wildcard.scheme || !strings.HasSuffix(host, wildcard.suffix) {
+			continue
+		}
+		// at least one non-empty label before the suffix
+		if label := strings.TrimSuffix(host, wildcard.suffix); label != "" && !strings.HasSuffix(label, ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestCORS(t *testing.T, policy CORSPolicy) http.Handler {
+	t.Helper()
+
+	cors, err := NewCORS(policy)
+	if err != nil {
+		t.Fatalf("NewCORS: %v", err)
+	}
+	return cors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func preflight(origin, method, headers string) *http.Request {
+	r := httptest.NewRequest(http.MethodOptions, "/api/v1/user", nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		r.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	return r
+}
+
+func TestCORSPreflight(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://booking.example.com", "https://*.togo.test"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+		ExposedHeaders:   []string{"ETag", "X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           5 * time.Minute,
+	}
+	handler := newTestCORS(t, policy)
+
+	tests := []struct {
+		name        string
+		origin      string
+		method      string
+		headers     string
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+	}{
+		{
+			name:        "exact origin",
+			origin:      "https://booking.example.com",
+			method:      http.MethodPut,
+			headers:     "content-type, x-request-id",
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://booking.example.com",
+			wantMethods: http.MethodPut,
+		},
+		{
+			name:        "wildcard subdomain",
+			origin:      "https://agents.eu.togo.test",
+			method:      http.MethodDelete,
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://agents.eu.togo.test",
+			wantMethods: http.MethodDelete,
+		},
+		{
+			name:       "wildcard does not match apex",
+			origin:     "https://togo.test",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wildcard does not match other scheme",
+			origin:     "http://agents.togo.test",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "suffix lookalike rejected",
+			origin:     "https://eviltogo.test",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown origin",
+			origin:     "https://attacker.example",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "method not allowed",
+			origin:     "https://booking.example.com",
+			method:     http.MethodPatch,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "header not allowed",
+			origin:     "https://booking.example.com",
+			method:     http.MethodPost,
+			headers:    "X-Secret",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, preflight(tt.origin, tt.method, tt.headers))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Fatalf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := w.Header().Get("Vary"); got != "Origin" {
+				t.Fatalf("Vary = %q, want Origin", got)
+			}
+			if tt.wantOrigin == "" {
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Fatalf("Access-Control-Allow-Credentials = %q, want true", got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != "300" {
+				t.Fatalf("Access-Control-Max-Age = %q, want 300", got)
+			}
+		})
+	}
+}
+
+func TestCORSSimpleRequestExposesHeaders(t *testing.T) {
+	handler := newTestCORS(t, CORSPolicy{
+		AllowedOrigins: []string{"https://booking.example.com"},
+		AllowedMethods: []string{http.MethodGet},
+		ExposedHeaders: []string{"ETag", "X-Request-ID"},
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	r.Header.Set("Origin", "https://booking.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "Etag,X-Request-Id" {
+		t.Fatalf("Access-Control-Expose-Headers = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("Access-Control-Allow-Credentials = %q, want none", got)
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	handler := newTestCORS(t, CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, preflight("https://anything.example", http.MethodPost, ""))
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
+
+func TestNewCORSRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CORSPolicy
+	}{
+		{"credentials with wildcard", CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}},
+		{"origin without scheme", CORSPolicy{AllowedOrigins: []string{"booking.example.com"}}},
+		{"origin with path", CORSPolicy{AllowedOrigins: []string{"https://booking.example.com/app"}}},
+		{"wildcard in the middle", CORSPolicy{AllowedOrigins: []string{"https://api.*.example.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCORS(tt.policy); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
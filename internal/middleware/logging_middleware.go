@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"booking_togo/internal/logger"
 	"net/http"
 	"time"
 
@@ -56,7 +57,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Log with Logrus based on status code
-		logger := log.WithFields(log.Fields{
+		requestLogger := logger.FromContext(r.Context()).WithFields(log.Fields{
 			"method":      logEntry.Method,
 			"path":        logEntry.Path,
 			"query":       logEntry.Query,
@@ -70,24 +71,25 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		// Color-coded logging based on HTTP status
 		switch {
 		case logEntry.StatusCode >= 500:
-			logger.Error("Server error")
+			requestLogger.Error("Server error")
 		case logEntry.StatusCode >= 400:
-			logger.Error("Client error")
+			requestLogger.Error("Client error")
 		case logEntry.StatusCode >= 300:
-			logger.Info("Redirection")
+			requestLogger.Info("Redirection")
 		default:
-			logger.Info("Request completed")
+			requestLogger.Info("Request completed")
 		}
 	})
 }
 
+// getIPAddress prefers the IP resolved by ClientIPResolver and falls back to
+// the direct peer, never to client-controlled forwarding headers.
 func getIPAddress(r *http.Request) string {
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
+	if ip := ClientIPFromContext(r.Context()); ip != "" {
 		return ip
 	}
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
+	if ip := parseHost(r.RemoteAddr); ip != nil {
+		return ip.String()
 	}
 	return r.RemoteAddr
-
 }
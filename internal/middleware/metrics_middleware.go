@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"booking_togo/internal/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MetricsMiddleware records latency and status class per route template
+// ("/api/v1/user/{id}"), never per raw path, to keep label cardinality bounded.
+// It wraps the whole router rather than being installed with Use, which mux
+// only runs for matched routes, so that requests no route matches are counted
+// too, as "unmatched".
+func MetricsMiddleware(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		route := "unmatched"
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.Route != nil && match.MatchErr == nil {
+			if template, err := match.Route.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		wrapped := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     200,
+		}
+		router.ServeHTTP(wrapped, r)
+
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		metrics.HTTPResponses.WithLabelValues(route, r.Method, statusClass(wrapped.statusCode)).Inc()
+	})
+}
+
+func statusClass(code int) string {
+	return strconv.Itoa(code/100) + "xx"
+}
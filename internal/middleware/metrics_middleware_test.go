@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"booking_togo/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareCountsUnmatchedRequests(t *testing.T) {
+	router := mux.NewRouter()
+	api := router.PathPrefix("/metrics-test").Subrouter()
+	api.HandleFunc("/user/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+	handler := MetricsMiddleware(router)
+
+	tests := []struct {
+		method, path string
+		route, class string
+	}{
+		{method: http.MethodGet, path: "/metrics-test/user/1", route: "/metrics-test/user/{id}", class: "2xx"},
+		{method: http.MethodGet, path: "/metrics-test/nowhere", route: "unmatched", class: "4xx"},
+		{method: http.MethodDelete, path: "/metrics-test/user/1", route: "unmatched", class: "4xx"},
+	}
+	for _, tt := range tests {
+		counter := metrics.HTTPResponses.WithLabelValues(tt.route, tt.method, tt.class)
+		before := testutil.ToFloat64(counter)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if got := testutil.ToFloat64(counter) - before; got != 1 {
+			t.Errorf("%s %s: counted %v times as %s %s", tt.method, tt.path, got, tt.route, tt.class)
+		}
+	}
+}
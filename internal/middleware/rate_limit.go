@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"booking_togo/internal/logger"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleVisitorTTL is how long an unused per-IP bucket is kept.
+const idleVisitorTTL = 10 * time.Minute
+
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter applies a token bucket per client IP, as resolved by
+// ClientIPResolver. A non-positive rate disables limiting. Limits can be
+// changed at runtime with SetLimit.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	visitors  map[string]*visitor
+	lastSweep time.Time
+}
+
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	l := &RateLimiter{visitors: map[string]*visitor{}}
+	l.SetLimit(requestsPerSecond, burst)
+	return l
+}
+
+// SetLimit atomically replaces the limits. Existing buckets are dropped so
+// every client starts fresh under the new policy.
+func (l *RateLimiter) SetLimit(requestsPerSecond float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = rate.Limit(requestsPerSecond)
+	l.burst = burst
+	l.visitors = map[string]*visitor{}
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter := l.allow(getIPAddress(r))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeError(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeError answers with the JSON error body the API handlers use, so
+// clients see the same shape whether a middleware or a handler refused them.
+func writeError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	body := map[string]string{"error": message}
+	if requestID := logger.RequestIDFromContext(r.Context()); requestID != "" {
+		body["request_id"] = requestID
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (l *RateLimiter) allow(ip string) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > idleVisitorTTL {
+		for key, v := range l.visitors {
+			if now.Sub(v.lastSeen) > idleVisitorTTL {
+				delete(l.visitors, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	v, ok := l.visitors[ip]
+	if !ok {
+		v = &visitor{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.visitors[ip] = v
+	}
+	v.lastSeen = now
+
+	if v.limiter.AllowN(now, 1) {
+		return true, 0
+	}
+
+	retryAfter := int(time.Duration(float64(time.Second)/float64(l.limit)).Seconds()) + 1
+	return false, retryAfter
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func limitedHandler(limiter *RateLimiter) http.Handler {
+	return RequestIDMiddleware(limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+}
+
+func requestFrom(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+	r.RemoteAddr = remoteAddr
+	r.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimiterPerClient(t *testing.T) {
+	limiter := NewRateLimiter(0.5, 2)
+	handler := limitedHandler(limiter)
+
+	for i := 0; i < 2; i++ {
+		if w := requestFrom(handler, "198.51.100.1:4000"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d within the burst: status %d", i+1, w.Code)
+		}
+	}
+
+	w := requestFrom(handler, "198.51.100.1:4001")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode 429 body: %v", err)
+	}
+	if body["error"] == "" || body["request_id"] != "req-1" {
+		t.Errorf("429 body = %v, want an error and the request id", body)
+	}
+
+	if w := requestFrom(handler, "198.51.100.2:4000"); w.Code != http.StatusNoContent {
+		t.Errorf("another client was limited: status %d", w.Code)
+	}
+
+	limiter.SetLimit(0.5, 3)
+	if w := requestFrom(handler, "198.51.100.1:4000"); w.Code != http.StatusNoContent {
+		t.Errorf("SetLimit kept the exhausted bucket: status %d", w.Code)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	handler := limitedHandler(NewRateLimiter(0, 0))
+	for i := 0; i < 50; i++ {
+		if w := requestFrom(handler, "198.51.100.1:4000"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d with limiting disabled: status %d", i+1, w.Code)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"booking_togo/internal/logger"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header used to accept and echo correlation IDs.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware reuses the caller's X-Request-ID when it is sane or
+// generates a new one, echoes it on the response and stores a logrus entry
+// tagged with it in the request context.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := log.WithField("request_id", requestID)
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+			entry = entry.WithField("trace_id", spanContext.TraceID().String())
+		}
+		ctx := logger.WithRequestID(r.Context(), requestID)
+		ctx = logger.WithEntry(ctx, entry)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts visible ASCII only, so a client cannot inject
+// newlines or control characters into our logs through the header.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Error("RequestIDMiddleware - rand read error: ", err)
+	}
+	return hex.EncodeToString(b)
+}
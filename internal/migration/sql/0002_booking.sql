@@ -0,0 +1,37 @@
+-- Bookings belong to a customer; passengers are the customer (fl_id NULL) or
+-- one of the customer's own family members. The composite foreign keys pin
+-- each passenger's cst_id to both the booking and the family row, so a family
+-- member of another customer can never be attached, and a booked family
+-- member cannot be moved to another customer.
+ALTER TABLE public.family_list
+	ADD CONSTRAINT family_list_fl_id_cst_id_key UNIQUE (fl_id, cst_id);
+
+CREATE TABLE public.booking (
+	booking_id serial4 NOT NULL,
+	cst_id int4 NOT NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at timestamp NULL,
+	CONSTRAINT booking_pkey PRIMARY KEY (booking_id),
+	CONSTRAINT booking_booking_id_cst_id_key UNIQUE (booking_id, cst_id),
+	CONSTRAINT booking_cst_id_fkey FOREIGN KEY (cst_id)
+		REFERENCES public.customer (customer_id) ON DELETE CASCADE
+);
+
+CREATE INDEX booking_cst_id_idx ON public.booking (cst_id);
+
+CREATE TABLE public.booking_passenger (
+	passenger_id serial4 NOT NULL,
+	booking_id int4 NOT NULL,
+	cst_id int4 NOT NULL,
+	fl_id int4 NULL,
+	CONSTRAINT booking_passenger_pkey PRIMARY KEY (passenger_id),
+	CONSTRAINT booking_passenger_booking_fkey FOREIGN KEY (booking_id, cst_id)
+		REFERENCES public.booking (booking_id, cst_id) ON DELETE CASCADE,
+	CONSTRAINT booking_passenger_family_fkey FOREIGN KEY (fl_id, cst_id)
+		REFERENCES public.family_list (fl_id, cst_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX booking_passenger_family_key ON public.booking_passenger (booking_id, fl_id)
+	WHERE fl_id IS NOT NULL;
+CREATE UNIQUE INDEX booking_passenger_customer_key ON public.booking_passenger (booking_id)
+	WHERE fl_id IS NULL;
@@ -0,0 +1,31 @@
+-- Booking lifecycle: the current status, when each state was last entered,
+-- and an append-only history of every transition.
+ALTER TABLE public.booking
+	ADD COLUMN status varchar(20) DEFAULT 'draft' NOT NULL,
+	ADD COLUMN held_at timestamp NULL,
+	ADD COLUMN confirmed_at timestamp NULL,
+	ADD COLUMN ticketed_at timestamp NULL,
+	ADD COLUMN cancelled_at timestamp NULL,
+	ADD COLUMN refunded_at timestamp NULL,
+	ADD CONSTRAINT booking_status_check
+		CHECK (status IN ('draft', 'held', 'confirmed', 'ticketed', 'cancelled', 'refunded'));
+
+CREATE INDEX booking_status_idx ON public.booking (status);
+
+CREATE TABLE public.booking_status_history (
+	history_id serial4 NOT NULL,
+	booking_id int4 NOT NULL,
+	from_status varchar(20) NULL,
+	to_status varchar(20) NOT NULL,
+	reason text NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT booking_status_history_pkey PRIMARY KEY (history_id),
+	CONSTRAINT booking_status_history_booking_fkey FOREIGN KEY (booking_id)
+		REFERENCES public.booking (booking_id) ON DELETE CASCADE
+);
+
+CREATE INDEX booking_status_history_booking_id_idx ON public.booking_status_history (booking_id, history_id);
+
+-- bookings created before this migration start their history as draft
+INSERT INTO public.booking_status_history (booking_id, from_status, to_status, created_at)
+SELECT booking_id, NULL, 'draft', created_at FROM public.booking;
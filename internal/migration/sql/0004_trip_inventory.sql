@@ -0,0 +1,48 @@
+-- Trips with seat inventory, and seat holds placed by bookings. available is
+-- decremented by a conditional UPDATE when a booking is held, so it can never
+-- go below zero however many holds race for the last seats.
+CREATE TABLE public.trip (
+	trip_id serial4 NOT NULL,
+	trip_code varchar(20) NOT NULL,
+	origin varchar(100) NOT NULL,
+	destination varchar(100) NOT NULL,
+	departure_at timestamp NOT NULL,
+	capacity int4 NOT NULL,
+	available int4 NOT NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT trip_pkey PRIMARY KEY (trip_id),
+	CONSTRAINT trip_trip_code_key UNIQUE (trip_code),
+	CONSTRAINT trip_capacity_check CHECK (capacity > 0),
+	CONSTRAINT trip_available_check CHECK (available >= 0 AND available <= capacity)
+);
+
+ALTER TABLE public.booking
+	ADD COLUMN trip_id int4 NULL,
+	ADD CONSTRAINT booking_trip_fkey FOREIGN KEY (trip_id) REFERENCES public.trip (trip_id);
+
+CREATE INDEX booking_trip_id_idx ON public.booking (trip_id);
+
+-- status: active while seats are reserved, confirmed once the booking is
+-- confirmed, released when the hold expired or the booking was released or
+-- cancelled (seats returned).
+CREATE TABLE public.seat_hold (
+	hold_id serial4 NOT NULL,
+	trip_id int4 NOT NULL,
+	booking_id int4 NOT NULL,
+	seats int4 NOT NULL,
+	status varchar(20) DEFAULT 'active' NOT NULL,
+	expires_at timestamp NOT NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	released_at timestamp NULL,
+	CONSTRAINT seat_hold_pkey PRIMARY KEY (hold_id),
+	CONSTRAINT seat_hold_trip_fkey FOREIGN KEY (trip_id) REFERENCES public.trip (trip_id),
+	CONSTRAINT seat_hold_booking_fkey FOREIGN KEY (booking_id)
+		REFERENCES public.booking (booking_id) ON DELETE CASCADE,
+	CONSTRAINT seat_hold_seats_check CHECK (seats > 0),
+	CONSTRAINT seat_hold_status_check CHECK (status IN ('active', 'confirmed', 'released'))
+);
+
+-- a booking holds seats at most once at a time
+CREATE UNIQUE INDEX seat_hold_booking_current_key ON public.seat_hold (booking_id)
+	WHERE status IN ('active', 'confirmed');
+CREATE INDEX seat_hold_expiry_idx ON public.seat_hold (expires_at) WHERE status = 'active';
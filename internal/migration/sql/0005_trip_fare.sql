@@ -0,0 +1,5 @@
+-- Adult base fare of a trip in the smallest currency unit; child and infant
+-- fares are derived from it by the pricing rules.
+ALTER TABLE public.trip
+	ADD COLUMN fare int8 DEFAULT 0 NOT NULL,
+	ADD CONSTRAINT trip_fare_check CHECK (fare >= 0);
@@ -0,0 +1,39 @@
+-- Payments collected through a gateway, one intent per row. A booking has at
+-- most one payment in progress or paid at a time; failed and cancelled
+-- attempts are kept.
+CREATE TABLE public.payment (
+	payment_id serial4 NOT NULL,
+	booking_id int4 NOT NULL,
+	gateway varchar(20) NOT NULL,
+	intent_id varchar(100) NOT NULL,
+	amount int8 NOT NULL,
+	currency char(3) NOT NULL,
+	status varchar(20) DEFAULT 'pending' NOT NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at timestamp NULL,
+	paid_at timestamp NULL,
+	CONSTRAINT payment_pkey PRIMARY KEY (payment_id),
+	CONSTRAINT payment_gateway_intent_key UNIQUE (gateway, intent_id),
+	CONSTRAINT payment_booking_fkey FOREIGN KEY (booking_id)
+		REFERENCES public.booking (booking_id) ON DELETE CASCADE,
+	CONSTRAINT payment_amount_check CHECK (amount >= 0),
+	CONSTRAINT payment_status_check
+		CHECK (status IN ('pending', 'succeeded', 'failed', 'cancelled', 'refunded'))
+);
+
+CREATE UNIQUE INDEX payment_booking_current_key ON public.payment (booking_id)
+	WHERE status IN ('pending', 'succeeded');
+CREATE INDEX payment_pending_idx ON public.payment (created_at) WHERE status = 'pending';
+
+-- Webhook deliveries already applied, so redeliveries are acknowledged
+-- without being processed twice.
+CREATE TABLE public.payment_event (
+	event_id varchar(100) NOT NULL,
+	gateway varchar(20) NOT NULL,
+	payment_id int4 NOT NULL,
+	status varchar(20) NOT NULL,
+	received_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT payment_event_pkey PRIMARY KEY (gateway, event_id),
+	CONSTRAINT payment_event_payment_fkey FOREIGN KEY (payment_id)
+		REFERENCES public.payment (payment_id) ON DELETE CASCADE
+);
@@ -0,0 +1,60 @@
+-- Cancellation and refunds. A booking is sold in a fare class that decides
+-- its refund rules; passengers can be cancelled one by one; payment_item
+-- records what each passenger paid, discounts included, so refunds can be
+-- computed per passenger; refunds are recorded against the payment they
+-- return money from.
+ALTER TABLE public.booking
+	ADD COLUMN fare_class varchar(20) DEFAULT 'standard' NOT NULL,
+	ADD CONSTRAINT booking_fare_class_check CHECK (fare_class IN ('standard', 'flex'));
+
+ALTER TABLE public.booking_passenger
+	ADD COLUMN cancelled_at timestamp NULL;
+
+CREATE TABLE public.payment_item (
+	payment_id int4 NOT NULL,
+	passenger_id int4 NOT NULL,
+	band varchar(10) NOT NULL,
+	amount int8 NOT NULL,
+	CONSTRAINT payment_item_pkey PRIMARY KEY (payment_id, passenger_id),
+	CONSTRAINT payment_item_payment_fkey FOREIGN KEY (payment_id)
+		REFERENCES public.payment (payment_id) ON DELETE CASCADE,
+	CONSTRAINT payment_item_passenger_fkey FOREIGN KEY (passenger_id)
+		REFERENCES public.booking_passenger (passenger_id) ON DELETE CASCADE,
+	CONSTRAINT payment_item_amount_check CHECK (amount >= 0)
+);
+
+-- status: pending until the gateway accepted the refund, so a refund
+-- interrupted half way is retried by reconciliation.
+CREATE TABLE public.refund (
+	refund_id serial4 NOT NULL,
+	payment_id int4 NOT NULL,
+	amount int8 NOT NULL,
+	status varchar(20) DEFAULT 'pending' NOT NULL,
+	gateway_refund_id varchar(100) NULL,
+	reason text NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	completed_at timestamp NULL,
+	CONSTRAINT refund_pkey PRIMARY KEY (refund_id),
+	CONSTRAINT refund_payment_fkey FOREIGN KEY (payment_id)
+		REFERENCES public.payment (payment_id) ON DELETE CASCADE,
+	CONSTRAINT refund_amount_check CHECK (amount > 0),
+	CONSTRAINT refund_status_check CHECK (status IN ('pending', 'succeeded'))
+);
+
+CREATE INDEX refund_payment_id_idx ON public.refund (payment_id);
+CREATE INDEX refund_pending_idx ON public.refund (refund_id) WHERE status = 'pending';
+
+-- how the refund of each cancelled passenger was computed
+CREATE TABLE public.refund_item (
+	refund_id int4 NOT NULL,
+	passenger_id int4 NOT NULL,
+	paid int8 NOT NULL,
+	rule varchar(50) NOT NULL,
+	percent int4 NOT NULL,
+	amount int8 NOT NULL,
+	CONSTRAINT refund_item_pkey PRIMARY KEY (refund_id, passenger_id),
+	CONSTRAINT refund_item_refund_fkey FOREIGN KEY (refund_id)
+		REFERENCES public.refund (refund_id) ON DELETE CASCADE,
+	CONSTRAINT refund_item_passenger_fkey FOREIGN KEY (passenger_id)
+		REFERENCES public.booking_passenger (passenger_id) ON DELETE CASCADE
+);
@@ -0,0 +1,30 @@
+-- Travel documents of customers (fl_id NULL) and their family members, keyed
+-- like booking passengers so a document can only belong to the customer's
+-- own family. The document number is stored encrypted by the application;
+-- number_last4 is kept in clear to show a masked number. International trips
+-- require a passport valid on the departure date.
+ALTER TABLE public.trip
+	ADD COLUMN international boolean DEFAULT false NOT NULL;
+
+CREATE TABLE public.travel_document (
+	document_id serial4 NOT NULL,
+	cst_id int4 NOT NULL,
+	fl_id int4 NULL,
+	document_type varchar(20) NOT NULL,
+	issuing_nationality_id int4 NOT NULL,
+	number_encrypted bytea NOT NULL,
+	number_last4 varchar(4) NOT NULL,
+	expires_on date NOT NULL,
+	created_at timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at timestamp NULL,
+	CONSTRAINT travel_document_pkey PRIMARY KEY (document_id),
+	CONSTRAINT travel_document_customer_fkey FOREIGN KEY (cst_id)
+		REFERENCES public.customer (customer_id) ON DELETE CASCADE,
+	CONSTRAINT travel_document_family_fkey FOREIGN KEY (fl_id, cst_id)
+		REFERENCES public.family_list (fl_id, cst_id) ON DELETE CASCADE,
+	CONSTRAINT travel_document_nationality_fkey FOREIGN KEY (issuing_nationality_id)
+		REFERENCES public.nationality (nationality_id),
+	CONSTRAINT travel_document_type_check CHECK (document_type IN ('passport', 'national_id', 'residence_permit'))
+);
+
+CREATE INDEX travel_document_cst_id_idx ON public.travel_document (cst_id);
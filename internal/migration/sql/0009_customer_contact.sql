@@ -0,0 +1,10 @@
+-- Contact details of customers. Email and phone are optional so existing
+-- customers stay valid; phone is E.164 and the address a JSON object.
+-- Emails are unique regardless of case, every deployment being one tenant.
+ALTER TABLE public.customer
+	ADD COLUMN cst_email varchar(254) NULL,
+	ADD COLUMN cst_phone varchar(16) NULL,
+	ADD COLUMN cst_address jsonb NULL;
+
+CREATE UNIQUE INDEX customer_email_key ON public.customer (lower(cst_email));
+CREATE INDEX customer_phone_idx ON public.customer (cst_phone);
@@ -0,0 +1,16 @@
+-- Guardians and emergency contacts. A family member can name the customer
+-- (guardian_customer) or another family member (guardian_fl_id) of the same
+-- customer as their guardian; bookings require one for passengers under 18.
+-- Removing a guardian family member leaves their wards without one.
+-- Emergency contacts of a customer are a JSON array.
+ALTER TABLE public.family_list
+	ADD COLUMN guardian_customer boolean DEFAULT false NOT NULL,
+	ADD COLUMN guardian_fl_id int4 NULL,
+	ADD CONSTRAINT family_list_guardian_fkey FOREIGN KEY (guardian_fl_id)
+		REFERENCES public.family_list (fl_id) ON DELETE SET NULL,
+	ADD CONSTRAINT family_list_guardian_check CHECK (
+		NOT (guardian_customer AND guardian_fl_id IS NOT NULL) AND guardian_fl_id <> fl_id
+	);
+
+ALTER TABLE public.customer
+	ADD COLUMN cst_emergency_contacts jsonb DEFAULT '[]'::jsonb NOT NULL;
@@ -0,0 +1,12 @@
+-- Booked customers and family members cannot be deleted: cascading the
+-- bookings away would drop their seat holds without restocking the trip.
+-- Draft bookings hold no seats and are removed by the application first.
+ALTER TABLE public.booking
+	DROP CONSTRAINT booking_cst_id_fkey,
+	ADD CONSTRAINT booking_cst_id_fkey FOREIGN KEY (cst_id)
+		REFERENCES public.customer (customer_id) ON DELETE RESTRICT;
+
+ALTER TABLE public.booking_passenger
+	DROP CONSTRAINT booking_passenger_family_fkey,
+	ADD CONSTRAINT booking_passenger_family_fkey FOREIGN KEY (fl_id, cst_id)
+		REFERENCES public.family_list (fl_id, cst_id) ON DELETE RESTRICT;
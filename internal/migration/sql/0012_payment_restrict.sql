@@ -0,0 +1,32 @@
+-- Payments, their events, items and refunds are financial records: deleting
+-- a booking, passenger or payment must never take them along.
+ALTER TABLE public.payment
+	DROP CONSTRAINT payment_booking_fkey,
+	ADD CONSTRAINT payment_booking_fkey FOREIGN KEY (booking_id)
+		REFERENCES public.booking (booking_id) ON DELETE RESTRICT;
+
+ALTER TABLE public.payment_event
+	DROP CONSTRAINT payment_event_payment_fkey,
+	ADD CONSTRAINT payment_event_payment_fkey FOREIGN KEY (payment_id)
+		REFERENCES public.payment (payment_id) ON DELETE RESTRICT;
+
+ALTER TABLE public.payment_item
+	DROP CONSTRAINT payment_item_payment_fkey,
+	ADD CONSTRAINT payment_item_payment_fkey FOREIGN KEY (payment_id)
+		REFERENCES public.payment (payment_id) ON DELETE RESTRICT,
+	DROP CONSTRAINT payment_item_passenger_fkey,
+	ADD CONSTRAINT payment_item_passenger_fkey FOREIGN KEY (passenger_id)
+		REFERENCES public.booking_passenger (passenger_id) ON DELETE RESTRICT;
+
+ALTER TABLE public.refund
+	DROP CONSTRAINT refund_payment_fkey,
+	ADD CONSTRAINT refund_payment_fkey FOREIGN KEY (payment_id)
+		REFERENCES public.payment (payment_id) ON DELETE RESTRICT;
+
+ALTER TABLE public.refund_item
+	DROP CONSTRAINT refund_item_refund_fkey,
+	ADD CONSTRAINT refund_item_refund_fkey FOREIGN KEY (refund_id)
+		REFERENCES public.refund (refund_id) ON DELETE RESTRICT,
+	DROP CONSTRAINT refund_item_passenger_fkey,
+	ADD CONSTRAINT refund_item_passenger_fkey FOREIGN KEY (passenger_id)
+		REFERENCES public.booking_passenger (passenger_id) ON DELETE RESTRICT;
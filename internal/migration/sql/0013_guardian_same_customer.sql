@@ -0,0 +1,14 @@
+-- A guardian family member must belong to the same customer as their ward.
+-- Guardians pointing at another customer's family member are dropped first.
+-- Deleting a guardian only clears guardian_fl_id, which needs PostgreSQL 15.
+UPDATE public.family_list w
+SET guardian_fl_id = NULL
+WHERE guardian_fl_id IS NOT NULL AND NOT EXISTS (
+	SELECT 1 FROM public.family_list g
+	WHERE g.fl_id = w.guardian_fl_id AND g.cst_id = w.cst_id
+);
+
+ALTER TABLE public.family_list
+	DROP CONSTRAINT family_list_guardian_fkey,
+	ADD CONSTRAINT family_list_guardian_fkey FOREIGN KEY (guardian_fl_id, cst_id)
+		REFERENCES public.family_list (fl_id, cst_id) ON DELETE SET NULL (guardian_fl_id);
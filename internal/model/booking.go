@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// FareClass decides which refund rules apply when a booking is cancelled.
+type FareClass string
+
+const (
+	FareClassStandard FareClass = "standard"
+	FareClassFlex     FareClass = "flex"
+)
+
+type Booking struct {
+	BookingID   int           `json:"booking_id"`
+	UserID      int           `json:"user_id"`
+	TripID      int           `json:"trip_id,omitempty"`
+	FareClass   FareClass     `json:"fare_class"`
+	Status      BookingStatus `json:"status"`
+	Passengers  []Passenger   `json:"passengers"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at"`
+	HeldAt      *time.Time    `json:"held_at,omitempty"`
+	ConfirmedAt *time.Time    `json:"confirmed_at,omitempty"`
+	TicketedAt  *time.Time    `json:"ticketed_at,omitempty"`
+	CancelledAt *time.Time    `json:"cancelled_at,omitempty"`
+	RefundedAt  *time.Time    `json:"refunded_at,omitempty"`
+	// HoldExpiresAt is set while the booking holds seats that are not yet
+	// confirmed.
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
+}
+
+// Passenger is a traveller on a booking: either the booking customer
+// (IsCustomer, FamilyID 0) or one of the customer's family members.
+// CancelledAt is set once the passenger was cancelled from a paid booking.
+type Passenger struct {
+	PassengerID int        `json:"passenger_id"`
+	FamilyID    int        `json:"family_id,omitempty"`
+	IsCustomer  bool       `json:"is_customer"`
+	Name        string     `json:"name"`
+	Dob         string     `json:"dob"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// BookingRequest is the payload for creating a booking or replacing its
+// passengers. FareClass defaults to standard and is only read on creation.
+type BookingRequest struct {
+	UserID          int       `json:"user_id"`
+	TripID          int       `json:"trip_id"`
+	FareClass       FareClass `json:"fare_class"`
+	IncludeCustomer bool      `json:"include_customer"`
+	FamilyIDs       []int     `json:"family_ids"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+type BookingStatus string
+
+const (
+	BookingDraft     BookingStatus = "draft"
+	BookingHeld      BookingStatus = "held"
+	BookingConfirmed BookingStatus = "confirmed"
+	BookingTicketed  BookingStatus = "ticketed"
+	BookingCancelled BookingStatus = "cancelled"
+	BookingRefunded  BookingStatus = "refunded"
+)
+
+// BookingStatusChange is one row of a booking's transition history. The
+// first entry of a booking has no FromStatus.
+type BookingStatusChange struct {
+	HistoryID  int           `json:"history_id"`
+	BookingID  int           `json:"booking_id"`
+	FromStatus BookingStatus `json:"from_status,omitempty"`
+	ToStatus   BookingStatus `json:"to_status"`
+	Reason     string        `json:"reason,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// SeatAction is the inventory change applied together with a status change.
+type SeatAction int
+
+const (
+	SeatsUnchanged SeatAction = iota
+	// SeatsHold reserves a seat per passenger on the booking's trip until the
+	// hold expires.
+	SeatsHold
+	// SeatsConfirm keeps the held seats for good; it fails once the hold has
+	// expired.
+	SeatsConfirm
+	// SeatsRelease gives held or confirmed seats back to the trip.
+	SeatsRelease
+)
+
+// StatusUpdate is one booking transition as applied by the repository.
+type StatusUpdate struct {
+	BookingID int
+	From      BookingStatus
+	To        BookingStatus
+	Reason    string
+	Seats     SeatAction
+	// HoldTTL is how long SeatsHold reserves the seats.
+	HoldTTL time.Duration
+	// ExpiredOnly limits SeatsRelease to a hold that has already expired, so
+	// the reaper never releases a hold renewed since it looked.
+	ExpiredOnly bool
+}
+
+// TransitionRequest is the optional body of the transition endpoints.
+type TransitionRequest struct {
+	Reason string `json:"reason"`
+}
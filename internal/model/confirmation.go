@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Confirmation is what a booking confirmation prints: the booking, its
+// customer and passengers, the trip and the price breakdown. Trip and Quote
+// are nil for a booking without a trip; Payment is the current payment, if
+// any.
+type Confirmation struct {
+	Booking    *Booking                `json:"booking"`
+	Customer   *UserDetailResponse     `json:"customer"`
+	Passengers []ConfirmationPassenger `json:"passengers"`
+	Trip       *Trip                   `json:"trip,omitempty"`
+	Quote      *Quote                  `json:"quote,omitempty"`
+	Payment    *Payment                `json:"payment,omitempty"`
+	IssuedAt   time.Time               `json:"issued_at"`
+}
+
+// ConfirmationPassenger is a passenger with the nationality printed for
+// them: the issuing country of their travel document, or else the
+// customer's nationality.
+type ConfirmationPassenger struct {
+	Passenger
+	Nationality Nationality `json:"nationality"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type DocumentType string
+
+const (
+	DocumentPassport        DocumentType = "passport"
+	DocumentNationalID      DocumentType = "national_id"
+	DocumentResidencePermit DocumentType = "residence_permit"
+)
+
+// TravelDocument is an identity document of a customer (FamilyID 0) or of
+// one of their family members. Number is only filled in when a single
+// document is read; lists carry NumberMasked. ExpiresOn is YYYY-MM-DD.
+type TravelDocument struct {
+	DocumentID           int          `json:"document_id"`
+	UserID               int          `json:"user_id"`
+	FamilyID             int          `json:"family_id,omitempty"`
+	Type                 DocumentType `json:"document_type"`
+	Number               string       `json:"number,omitempty"`
+	NumberMasked         string       `json:"number_masked"`
+	IssuingNationalityID int          `json:"issuing_nationality_id"`
+	IssuingCountry       Nationality  `json:"issuing_country"`
+	ExpiresOn            string       `json:"expires_on"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            *time.Time   `json:"updated_at"`
+
+	// NumberEncrypted and NumberLast4 are what the repository stores in
+	// place of Number.
+	NumberEncrypted []byte `json:"-"`
+	NumberLast4     string `json:"-"`
+}
+
+// DocumentRequest is the payload for adding or replacing a travel document.
+type DocumentRequest struct {
+	FamilyID             int          `json:"family_id"`
+	Type                 DocumentType `json:"document_type"`
+	Number               string       `json:"number"`
+	IssuingNationalityID int          `json:"issuing_nationality_id"`
+	ExpiresOn            string       `json:"expires_on"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain errors shared by the repository, usecase and delivery layers. Wrap
+// them with fmt.Errorf("...: %w", ...) to add detail.
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrPassengerNotInFamily = errors.New("passenger is not a family member of the booking customer")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+
+	// ErrConflict is the parent of errors caused by the current state of a
+	// resource rather than by the request itself.
+	ErrConflict             = errors.New("conflict")
+	ErrInvalidTransition    = fmt.Errorf("invalid booking status transition: %w", ErrConflict)
+	ErrStatusChanged        = fmt.Errorf("booking status changed concurrently, reload and retry: %w", ErrConflict)
+	ErrSoldOut              = fmt.Errorf("not enough seats available on the trip: %w", ErrConflict)
+	ErrHoldExpired          = fmt.Errorf("seat hold expired, hold the booking again: %w", ErrConflict)
+	ErrNoTrip               = fmt.Errorf("booking has no trip to hold seats on: %w", ErrConflict)
+	ErrTripDeparted         = fmt.Errorf("trip has already departed: %w", ErrConflict)
+	ErrPaymentRequired      = fmt.Errorf("booking must be paid before it is confirmed: %w", ErrConflict)
+	ErrNotPayable           = fmt.Errorf("only held bookings can be paid: %w", ErrConflict)
+	ErrAlreadyPaid          = fmt.Errorf("booking is already paid: %w", ErrConflict)
+	ErrPassengerNotActive   = fmt.Errorf("passenger is not on the booking or already cancelled: %w", ErrConflict)
+	ErrDocumentRequired     = fmt.Errorf("passenger has no passport valid on the travel date: %w", ErrConflict)
+	ErrGuardianRequired     = fmt.Errorf("passenger under 18 has no guardian: %w", ErrConflict)
+	ErrCancellationRequired = fmt.Errorf("confirmed bookings are cancelled through the cancellation endpoint so the refund policy applies: %w", ErrConflict)
+	ErrRefundRequired       = fmt.Errorf("bookings become refunded when their refund is issued, not on request: %w", ErrConflict)
+)
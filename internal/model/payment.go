@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+type PaymentStatus string
+
+// A payment starts pending when its intent is created at the gateway and is
+// settled by the gateway's webhook or by reconciliation.
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentCancelled PaymentStatus = "cancelled"
+	PaymentRefunded  PaymentStatus = "refunded"
+)
+
+// Payment is a payment intent for a booking. ClientSecret is what the client
+// completes the payment with at the gateway; it is only returned when the
+// intent is created and never stored.
+type Payment struct {
+	PaymentID    int           `json:"payment_id"`
+	BookingID    int           `json:"booking_id"`
+	Gateway      string        `json:"gateway"`
+	IntentID     string        `json:"intent_id"`
+	ClientSecret string        `json:"client_secret,omitempty"`
+	Amount       int64         `json:"amount"`
+	Currency     string        `json:"currency"`
+	Status       PaymentStatus `json:"status"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    *time.Time    `json:"updated_at"`
+	PaidAt       *time.Time    `json:"paid_at,omitempty"`
+	// Items split Amount between the passengers it pays for.
+	Items []PaymentItem `json:"items,omitempty"`
+}
+
+// PaymentItem is the part of a payment covering one passenger, after
+// discounts.
+type PaymentItem struct {
+	PassengerID int     `json:"passenger_id"`
+	Band        AgeBand `json:"band"`
+	Amount      int64   `json:"amount"`
+}
+
+// PaymentEvent is a payment gateway notification, delivered by webhook or
+// found by polling the gateway.
+type PaymentEvent struct {
+	EventID  string        `json:"id"`
+	IntentID string        `json:"intent_id"`
+	Status   PaymentStatus `json:"status"`
+	Amount   int64         `json:"amount"`
+	Currency string        `json:"currency"`
+	Created  time.Time     `json:"created"`
+}
+
+// SimulatePaymentRequest asks the development gateway to settle a payment as
+// if the customer had completed (or abandoned) it.
+type SimulatePaymentRequest struct {
+	Status PaymentStatus `json:"status"`
+}
@@ -0,0 +1,48 @@
+package model
+
+// AgeBand is the fare category of a traveller, from their age on the travel
+// date.
+type AgeBand string
+
+const (
+	AgeBandAdult  AgeBand = "adult"
+	AgeBandChild  AgeBand = "child"
+	AgeBandInfant AgeBand = "infant"
+)
+
+// Quote is the itemized price of a set of passengers on a trip. Amounts are
+// in the smallest unit of Currency.
+type Quote struct {
+	TripID     int             `json:"trip_id"`
+	TravelDate string          `json:"travel_date"`
+	Currency   string          `json:"currency"`
+	Items      []QuoteItem     `json:"items"`
+	Subtotal   int64           `json:"subtotal"`
+	Discounts  []QuoteDiscount `json:"discounts"`
+	Total      int64           `json:"total"`
+}
+
+// QuoteItem is the fare of one traveller: BaseFare is the trip's adult fare
+// and Fare the FarePercent of it charged for Band. Items of a paid booking's
+// confirmation have no BaseFare: Fare is what the passenger was charged,
+// their share of the discounts taken off.
+type QuoteItem struct {
+	PassengerID int     `json:"passenger_id,omitempty"`
+	FamilyID    int     `json:"family_id,omitempty"`
+	IsCustomer  bool    `json:"is_customer"`
+	Name        string  `json:"name"`
+	Dob         string  `json:"dob"`
+	Age         int     `json:"age"`
+	Band        AgeBand `json:"band"`
+	BaseFare    int64   `json:"base_fare"`
+	FarePercent int     `json:"fare_percent"`
+	Fare        int64   `json:"fare"`
+}
+
+// QuoteDiscount is a discount taken off the subtotal.
+type QuoteDiscount struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Percent     int    `json:"percent"`
+	Amount      int64  `json:"amount"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+type RefundStatus string
+
+// A refund is pending from when it is recorded until the gateway accepted it.
+const (
+	RefundPending   RefundStatus = "pending"
+	RefundSucceeded RefundStatus = "succeeded"
+)
+
+// Refund returns money from a payment. Items explain how it was computed
+// when it comes from cancelling passengers.
+type Refund struct {
+	RefundID        int          `json:"refund_id"`
+	PaymentID       int          `json:"payment_id"`
+	BookingID       int          `json:"booking_id"`
+	Amount          int64        `json:"amount"`
+	Currency        string       `json:"currency"`
+	Status          RefundStatus `json:"status"`
+	GatewayRefundID string       `json:"gateway_refund_id,omitempty"`
+	Reason          string       `json:"reason,omitempty"`
+	Items           []RefundItem `json:"items,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	CompletedAt     *time.Time   `json:"completed_at,omitempty"`
+}
+
+// RefundItem is the refund for one cancelled passenger: Percent of what they
+// Paid, from the first matching refund Rule.
+type RefundItem struct {
+	PassengerID int     `json:"passenger_id"`
+	Name        string  `json:"name"`
+	Band        AgeBand `json:"band"`
+	Paid        int64   `json:"paid"`
+	Rule        string  `json:"rule"`
+	Percent     int     `json:"percent"`
+	Amount      int64   `json:"amount"`
+}
+
+// CancellationRequest cancels some passengers of a booking, or all of them
+// when PassengerIDs is empty.
+type CancellationRequest struct {
+	PassengerIDs []int  `json:"passenger_ids"`
+	Reason       string `json:"reason"`
+}
+
+// Cancellation is the outcome, or with a preview the expected outcome, of a
+// CancellationRequest.
+type Cancellation struct {
+	BookingID           int           `json:"booking_id"`
+	FareClass           FareClass     `json:"fare_class"`
+	DaysBeforeDeparture int           `json:"days_before_departure"`
+	Status              BookingStatus `json:"status"`
+	Items               []RefundItem  `json:"items"`
+	RefundTotal         int64         `json:"refund_total"`
+	Currency            string        `json:"currency,omitempty"`
+	Refund              *Refund       `json:"refund,omitempty"`
+}
+
+// PassengerCancellation is a cancellation as applied by the repository: the
+// passengers leave the booking, their seats go back to the trip and Refund,
+// when not nil, is recorded as pending. Cancelling the last passengers
+// cancels the booking.
+type PassengerCancellation struct {
+	BookingID    int
+	Status       BookingStatus
+	PassengerIDs []int
+	Reason       string
+	Refund       *Refund
+}
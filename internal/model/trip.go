@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Trip is a scheduled departure with a fixed number of seats. Available is
+// what is left after active and confirmed holds. Fare is the adult fare in
+// the smallest currency unit. Passengers of an International trip need a
+// passport valid on the departure date.
+type Trip struct {
+	TripID        int       `json:"trip_id"`
+	TripCode      string    `json:"trip_code"`
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	DepartureAt   time.Time `json:"departure_at"`
+	Capacity      int       `json:"capacity"`
+	Fare          int64     `json:"fare"`
+	International bool      `json:"international"`
+	Available     int       `json:"available"`
+	CreatedAt     time.Time `json:"created_at"`
+}
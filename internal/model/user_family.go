@@ -1,18 +1,57 @@
 package model
 
+// User is a customer with their family. Email and Phone are optional; Phone
+// is stored in E.164 form and Email is unique across customers regardless of
+// case. EmergencyContacts are who to call when something happens on a trip.
 type User struct {
-	UserID        int      `json:"user_id"`
-	Name          string   `json:"name"`
-	Dob           string   `json:"dob"`
-	NationalityID int      `json:"national_id"`
-	Families      []Family `json:"families"`
+	UserID            int                `json:"user_id"`
+	Name              string             `json:"name"`
+	Dob               string             `json:"dob"`
+	NationalityID     int                `json:"national_id"`
+	Email             string             `json:"email"`
+	Phone             string             `json:"phone"`
+	Address           *Address           `json:"address,omitempty"`
+	EmergencyContacts []EmergencyContact `json:"emergency_contacts"`
+	Families          []Family           `json:"families"`
+}
+
+// Address is a customer's postal address. CountryCode is ISO 3166 alpha-2.
+type Address struct {
+	Line1       string `json:"line1"`
+	Line2       string `json:"line2,omitempty"`
+	City        string `json:"city"`
+	Region      string `json:"region,omitempty"`
+	PostalCode  string `json:"postal_code"`
+	CountryCode string `json:"country_code"`
+}
+
+// EmergencyContact is a person to reach about a customer and their family.
+// Phone is stored in E.164 form.
+type EmergencyContact struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email,omitempty"`
+}
+
+// UserSearch finds customers by exact email, ignoring case, or phone.
+type UserSearch struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
 }
 
 type Family struct {
-	FamilyID int    `json:"family_id"`
-	UserID   int    `json:"user_id"`
-	Name     string `json:"name"`
-	Dob      string `json:"dob"`
+	FamilyID int       `json:"family_id"`
+	UserID   int       `json:"user_id"`
+	Name     string    `json:"name"`
+	Dob      string    `json:"dob"`
+	Guardian *Guardian `json:"guardian,omitempty"`
+}
+
+// Guardian is who is responsible for a family member under 18: the customer
+// (FamilyID 0) or an adult family member of the same customer.
+type Guardian struct {
+	FamilyID int `json:"family_id"`
 }
 
 type FamiliesJSON struct {
@@ -20,10 +59,14 @@ type FamiliesJSON struct {
 }
 
 type UserDetailResponse struct {
-	UserID        int         `json:"user_id"`
-	Name          string      `json:"name"`
-	Dob           string      `json:"dob"`
-	NationalityID int         `json:"national_id"`
-	Nationality   Nationality `json:"nationality"`
-	Families      []Family    `json:"families"`
+	UserID            int                `json:"user_id"`
+	Name              string             `json:"name"`
+	Dob               string             `json:"dob"`
+	NationalityID     int                `json:"national_id"`
+	Nationality       Nationality        `json:"nationality"`
+	Email             string             `json:"email"`
+	Phone             string             `json:"phone"`
+	Address           *Address           `json:"address,omitempty"`
+	EmergencyContacts []EmergencyContact `json:"emergency_contacts"`
+	Families          []Family           `json:"families"`
 }
@@ -0,0 +1,188 @@
+package payment
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FakeGateway is an in-process Gateway for local development and tests. No
+// money moves: intents stay pending until Settle, which returns the signed
+// webhook a real gateway would send. Intents live in memory only, so a
+// restart forgets them; IDs are random so they never repeat one stored
+// before the restart.
+type FakeGateway struct {
+	secret    []byte
+	tolerance time.Duration
+
+	mu          sync.Mutex
+	intents     map[string]*fakeIntent
+	byReference map[string]string
+	refunds     map[string]string
+}
+
+type fakeIntent struct {
+	Intent
+	refunded int64
+}
+
+// NewFakeGateway signs webhooks with secret, or with a random secret when it
+// is empty, and accepts them for tolerance.
+func NewFakeGateway(secret string, tolerance time.Duration) *FakeGateway {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = []byte(randomHex(32))
+	}
+	return &FakeGateway{
+		secret:      key,
+		tolerance:   tolerance,
+		intents:     map[string]*fakeIntent{},
+		byReference: map[string]string{},
+		refunds:     map[string]string{},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateIntent(ctx context.Context, request IntentRequest) (*Intent, error) {
+	if request.Amount <= 0 {
+		return nil, errors.New("fake gateway: amount must be positive")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if id, ok := g.byReference[request.Reference]; ok {
+		intent := g.intents[id].Intent
+		return &intent, nil
+	}
+
+	intent := &fakeIntent{Intent: Intent{
+		ID:           "fake_pi_" + randomHex(12),
+		ClientSecret: "fake_secret_" + randomHex(12),
+		Amount:       request.Amount,
+		Currency:     request.Currency,
+		Status:       model.PaymentPending,
+	}}
+	g.intents[intent.ID] = intent
+	g.byReference[request.Reference] = intent.ID
+
+	result := intent.Intent
+	return &result, nil
+}
+
+func (g *FakeGateway) GetIntent(ctx context.Context, intentID string) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return nil, fmt.Errorf("fake gateway: intent %s: %w", intentID, model.ErrNotFound)
+	}
+	result := intent.Intent
+	return &result, nil
+}
+
+func (g *FakeGateway) CancelIntent(ctx context.Context, intentID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return fmt.Errorf("fake gateway: intent %s: %w", intentID, model.ErrNotFound)
+	}
+	if intent.Status != model.PaymentPending {
+		return fmt.Errorf("fake gateway: intent %s is %s: %w", intentID, intent.Status, model.ErrConflict)
+	}
+	intent.Status = model.PaymentCancelled
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, intentID string, amount int64, reference string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if id, ok := g.refunds[reference]; ok {
+		return id, nil
+	}
+
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return "", fmt.Errorf("fake gateway: intent %s: %w", intentID, model.ErrNotFound)
+	}
+	if intent.Status != model.PaymentSucceeded {
+		return "", fmt.Errorf("fake gateway: intent %s is %s: %w", intentID, intent.Status, model.ErrConflict)
+	}
+	if amount <= 0 || intent.refunded+amount > intent.Amount {
+		return "", fmt.Errorf("fake gateway: refund of %d exceeds the %d left on intent %s: %w",
+			amount, intent.Amount-intent.refunded, intentID, model.ErrConflict)
+	}
+
+	intent.refunded += amount
+	id := "fake_re_" + randomHex(12)
+	g.refunds[reference] = id
+	return id, nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (*model.PaymentEvent, error) {
+	if err := Verify(g.secret, payload, signature, g.tolerance, time.Now()); err != nil {
+		return nil, err
+	}
+
+	event := &model.PaymentEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("fake gateway: decode webhook: %w", err)
+	}
+	return event, nil
+}
+
+// Settle moves a pending intent to succeeded or failed and returns the signed
+// webhook delivery reporting it.
+func (g *FakeGateway) Settle(ctx context.Context, intentID string, status model.PaymentStatus) ([]byte, string, error) {
+	if status != model.PaymentSucceeded && status != model.PaymentFailed {
+		return nil, "", fmt.Errorf("fake gateway: cannot settle as %q", status)
+	}
+
+	g.mu.Lock()
+	intent, ok := g.intents[intentID]
+	if !ok {
+		g.mu.Unlock()
+		return nil, "", fmt.Errorf("fake gateway: intent %s: %w", intentID, model.ErrNotFound)
+	}
+	if intent.Status != model.PaymentPending {
+		g.mu.Unlock()
+		return nil, "", fmt.Errorf("fake gateway: intent %s is %s: %w", intentID, intent.Status, model.ErrConflict)
+	}
+	intent.Status = status
+	event := model.PaymentEvent{
+		EventID:  "fake_evt_" + randomHex(12),
+		IntentID: intent.ID,
+		Status:   status,
+		Amount:   intent.Amount,
+		Currency: intent.Currency,
+		Created:  time.Now().UTC(),
+	}
+	g.mu.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(g.secret, payload, time.Now()), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,56 @@
+// Package payment talks to payment gateways. Bookings are paid through
+// intents: the server creates one for the amount due, the customer completes
+// it at the gateway with the client secret and the gateway reports the
+// outcome through a signed webhook.
+package payment
+
+import (
+	"booking_togo/internal/model"
+	"context"
+)
+
+// Gateway is a payment gateway. Implementations must be safe for concurrent
+// use.
+type Gateway interface {
+	// Name identifies the gateway on stored payments.
+	Name() string
+	// CreateIntent starts a payment. Reference is an idempotency key: the
+	// same reference returns the same intent.
+	CreateIntent(ctx context.Context, request IntentRequest) (*Intent, error)
+	// GetIntent returns the current state of an intent, for reconciliation
+	// when a webhook was missed.
+	GetIntent(ctx context.Context, intentID string) (*Intent, error)
+	// CancelIntent abandons a pending intent.
+	CancelIntent(ctx context.Context, intentID string) error
+	// Refund returns amount of a succeeded intent to the customer.
+	// Reference is an idempotency key: retrying with the same reference
+	// returns the first refund instead of refunding again.
+	Refund(ctx context.Context, intentID string, amount int64, reference string) (refundID string, err error)
+	// ParseWebhook verifies the signature of a webhook delivery and decodes
+	// it. It fails with model.ErrInvalidSignature for forged or stale
+	// deliveries.
+	ParseWebhook(payload []byte, signature string) (*model.PaymentEvent, error)
+}
+
+// IntentRequest is the amount to collect, in the smallest unit of Currency.
+type IntentRequest struct {
+	Amount    int64
+	Currency  string
+	Reference string
+}
+
+// Intent is the gateway's view of a payment.
+type Intent struct {
+	ID           string
+	ClientSecret string
+	Amount       int64
+	Currency     string
+	Status       model.PaymentStatus
+}
+
+// Simulator is implemented by development gateways that can settle an intent
+// without a real customer. It returns the webhook delivery the gateway would
+// have sent.
+type Simulator interface {
+	Settle(ctx context.Context, intentID string, status model.PaymentStatus) (payload []byte, signature string, err error)
+}
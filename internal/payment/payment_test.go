@@ -0,0 +1,123 @@
+package payment
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("whsec_test")
+	payload := []byte(`{"id":"evt_1","status":"succeeded"}`)
+	sentAt := time.Unix(1_700_000_000, 0)
+	signature := Sign(secret, payload, sentAt)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		payload   []byte
+		signature string
+		now       time.Time
+		wantErr   bool
+	}{
+		{"valid", secret, payload, signature, sentAt.Add(time.Minute), false},
+		{"extra signatures are tried", secret, payload, signature + ",v1=00ff", sentAt, false},
+		{"tampered payload", secret, []byte(`{"id":"evt_1","status":"failed"}`), signature, sentAt, true},
+		{"wrong secret", []byte("whsec_other"), payload, signature, sentAt, true},
+		{"replayed too late", secret, payload, signature, sentAt.Add(6 * time.Minute), true},
+		{"from the future", secret, payload, signature, sentAt.Add(-6 * time.Minute), true},
+		{"no timestamp", secret, payload, signature[strings.Index(signature, ",")+1:], sentAt, true},
+		{"empty header", secret, payload, "", sentAt, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.payload, tt.signature, 5*time.Minute, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, model.ErrInvalidSignature) {
+				t.Fatalf("err = %v does not wrap ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestFakeGateway(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway("whsec_test", time.Minute)
+
+	intent, err := gateway.CreateIntent(ctx, IntentRequest{Amount: 1500, Currency: "IDR", Reference: "booking-1-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := gateway.CreateIntent(ctx, IntentRequest{Amount: 1500, Currency: "IDR", Reference: "booking-1-1"})
+	if again.ID != intent.ID {
+		t.Fatalf("same reference created intents %s and %s", intent.ID, again.ID)
+	}
+
+	if _, err := gateway.Refund(ctx, intent.ID, 100, "refund-0"); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("refund of a pending intent: err = %v", err)
+	}
+
+	payload, signature, err := gateway.Settle(ctx, intent.ID, model.PaymentSucceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.IntentID != intent.ID || event.Status != model.PaymentSucceeded || event.Amount != 1500 {
+		t.Fatalf("event = %+v", event)
+	}
+	if _, err := NewFakeGateway("whsec_other", time.Minute).ParseWebhook(payload, signature); !errors.Is(err, model.ErrInvalidSignature) {
+		t.Fatalf("webhook signed by another gateway: err = %v", err)
+	}
+
+	if _, err := gateway.Refund(ctx, intent.ID, 1000, "refund-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gateway.Refund(ctx, intent.ID, 1000, "refund-1"); err != nil {
+		t.Fatalf("retried refund: err = %v", err)
+	}
+	if _, err := gateway.Refund(ctx, intent.ID, 501, "refund-2"); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("refund beyond the amount paid: err = %v", err)
+	}
+	if _, err := gateway.Refund(ctx, intent.ID, 500, "refund-3"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeGatewayIDsSurviveRestarts(t *testing.T) {
+	ctx := context.Background()
+	seen := map[string]bool{}
+	for restart := 0; restart < 3; restart++ {
+		gateway := NewFakeGateway("whsec_test", time.Minute)
+		intent, err := gateway.CreateIntent(ctx, IntentRequest{Amount: 1500, Currency: "IDR", Reference: "booking-1-1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, signature, err := gateway.Settle(ctx, intent.ID, model.PaymentSucceeded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event, err := gateway.ParseWebhook(payload, signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refundID, err := gateway.Refund(ctx, intent.ID, 1500, "refund-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range []string{intent.ID, event.EventID, refundID} {
+			if seen[id] {
+				t.Fatalf("ID %s repeated after a restart", id)
+			}
+			seen[id] = true
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package payment
+
+import (
+	"booking_togo/internal/model"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the webhook signature.
+const SignatureHeader = "X-Payment-Signature"
+
+// Sign returns the signature header value for payload sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<payload>">". Binding the
+// timestamp into the MAC lets receivers reject replayed deliveries.
+func Sign(secret, payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac(secret, timestamp, payload))
+}
+
+// Verify checks a signature produced by Sign and that it is no older (or
+// newer) than tolerance at now. Every failure wraps
+// model.ErrInvalidSignature.
+func Verify(secret, payload []byte, header string, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("malformed %s header: %w", SignatureHeader, model.ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("timestamp outside the %s tolerance: %w", tolerance, model.ErrInvalidSignature)
+	}
+
+	expected := mac(secret, timestamp, payload)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return model.ErrInvalidSignature
+}
+
+func mac(secret []byte, timestamp string, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,153 @@
+// Package pgtest starts a disposable Postgres cluster for integration tests.
+package pgtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUnavailable means no usable Postgres was found; callers should skip.
+var ErrUnavailable = errors.New("pgtest: postgres unavailable")
+
+// Server is a running cluster, or an external one from TEST_DATABASE_URL.
+type Server struct {
+	baseURL string
+	dir     string
+	pgCtl   string
+}
+
+// Start connects to TEST_DATABASE_URL when set. Otherwise it runs initdb and
+// pg_ctl, taken from PG_BIN_DIR or PATH, in a temporary directory with
+// durability turned off.
+func Start() (*Server, error) {
+	if databaseURL := os.Getenv("TEST_DATABASE_URL"); databaseURL != "" {
+		return &Server{baseURL: databaseURL}, nil
+	}
+
+	initdb, pgCtl, err := binaries()
+	if err != nil {
+		return nil, err
+	}
+	if os.Geteuid() == 0 {
+		return nil, fmt.Errorf("%w: initdb refuses to run as root, set TEST_DATABASE_URL instead", ErrUnavailable)
+	}
+
+	dir, err := os.MkdirTemp("", "pgtest-")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{dir: dir, pgCtl: pgCtl}
+
+	dataDir := filepath.Join(dir, "data")
+	if out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust", "--no-sync", "-E", "UTF8").CombinedOutput(); err != nil {
+		s.Stop()
+		return nil, fmt.Errorf("initdb: %w: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		s.Stop()
+		return nil, err
+	}
+
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off -c synchronous_commit=off -c full_page_writes=off", port, dir)
+	start := exec.Command(pgCtl, "-D", dataDir, "-l", filepath.Join(dir, "postgres.log"), "-o", options, "-w", "-t", "60", "start")
+	if out, err := start.CombinedOutput(); err != nil {
+		logs, _ := os.ReadFile(filepath.Join(dir, "postgres.log"))
+		s.Stop()
+		return nil, fmt.Errorf("pg_ctl start: %w: %s\n%s", err, out, logs)
+	}
+
+	s.baseURL = fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port)
+	return s, nil
+}
+
+// Stop shuts the cluster down and removes its directory. It is a no-op for
+// an external TEST_DATABASE_URL server.
+func (s *Server) Stop() {
+	if s.dir == "" {
+		return
+	}
+	if s.pgCtl != "" {
+		exec.Command(s.pgCtl, "-D", filepath.Join(s.dir, "data"), "-m", "immediate", "-w", "stop").Run()
+	}
+	os.RemoveAll(s.dir)
+}
+
+// CreateDatabase creates an empty database with a random name and returns
+// its connection URL. On an external server the database is left behind.
+func (s *Server) CreateDatabase(ctx context.Context) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := "pgtest_" + hex.EncodeToString(b)
+
+	conn, err := pgx.Connect(ctx, s.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, "CREATE DATABASE "+pgx.Identifier{name}.Sanitize()); err != nil {
+		return "", fmt.Errorf("create database: %w", err)
+	}
+
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = "/" + name
+	return u.String(), nil
+}
+
+func binaries() (string, string, error) {
+	lookup := func(name string) (string, error) {
+		if dir := os.Getenv("PG_BIN_DIR"); dir != "" {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s not found in PG_BIN_DIR or PATH", ErrUnavailable, name)
+		}
+		return path, nil
+	}
+
+	initdb, err := lookup("initdb")
+	if err != nil {
+		return "", "", err
+	}
+	pgCtl, err := lookup("pg_ctl")
+	if err != nil {
+		return "", "", err
+	}
+	return initdb, pgCtl, nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
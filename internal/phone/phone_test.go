@@ -0,0 +1,42 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		number  string
+		region  string
+		want    string
+		wantErr error
+	}{
+		{number: "0812-3456-7890", region: "ID", want: "+6281234567890"},
+		{number: "+62 812 3456 7890", region: "", want: "+6281234567890"},
+		{number: "0062 812 3456 7890", region: "JP", want: "+6281234567890"},
+		{number: "(415) 555-2671", region: "US", want: "+14155552671"},
+		{number: "1 415 555 2671", region: "us", want: "+14155552671"},
+		{number: "020 7946 0958", region: "GB", want: "+442079460958"},
+		{number: "06 1234 5678", region: "IT", want: "+390612345678"},
+		{number: "8 (912) 345-67-89", region: "RU", want: "+79123456789"},
+		{number: "0812 3456 7890", region: "", wantErr: ErrNoCountryCode},
+		{number: "0812 3456 7890", region: "ZZ", wantErr: ErrNoCountryCode},
+		{number: "+62 812 abc", region: "ID", wantErr: ErrInvalid},
+		{number: "+0123456789", region: "", wantErr: ErrInvalid},
+		{number: "+1234567890123456", region: "", wantErr: ErrInvalid},
+		{number: "12", region: "ID", wantErr: ErrInvalid},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.number, tt.region)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Normalize(%q, %q) = %q, %v, want %v", tt.number, tt.region, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, %v, want %q", tt.number, tt.region, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,660 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IBookingRepository interface {
+	GetAll(ctx context.Context, userID int) ([]*model.Booking, error)
+	GetByID(ctx context.Context, bookingID int) (*model.Booking, error)
+	Create(ctx context.Context, booking *model.Booking) error
+	ReplacePassengers(ctx context.Context, bookingID int, status model.BookingStatus, passengers []model.Passenger) error
+	Delete(ctx context.Context, bookingID int, status model.BookingStatus) error
+	UpdateStatus(ctx context.Context, update model.StatusUpdate) error
+	History(ctx context.Context, bookingID int) ([]model.BookingStatusChange, error)
+	ExpiredHolds(ctx context.Context, limit int) ([]int, error)
+	CancelPassengers(ctx context.Context, cancellation model.PassengerCancellation) error
+}
+
+type BookingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBookingRepository(db *pgxpool.Pool) *BookingRepository {
+	return &BookingRepository{
+		db: db,
+	}
+}
+
+const bookingColumns = `booking_id, cst_id, COALESCE(trip_id, 0), fare_class, status, created_at, updated_at,
+	held_at, confirmed_at, ticketed_at, cancelled_at, refunded_at,
+	(SELECT h.expires_at FROM seat_hold h WHERE h.booking_id = booking.booking_id AND h.status = 'active')`
+
+// statusColumns records when a booking last entered each state. Draft has no
+// column: returning to draft only clears the hold.
+var statusColumns = map[model.BookingStatus]string{
+	model.BookingHeld:      "held_at",
+	model.BookingConfirmed: "confirmed_at",
+	model.BookingTicketed:  "ticketed_at",
+	model.BookingCancelled: "cancelled_at",
+	model.BookingRefunded:  "refunded_at",
+}
+
+func scanBooking(row pgx.Row) (*model.Booking, error) {
+	booking := &model.Booking{Passengers: []model.Passenger{}}
+	err := row.Scan(&booking.BookingID, &booking.UserID, &booking.TripID, &booking.FareClass, &booking.Status, &booking.CreatedAt, &booking.UpdatedAt,
+		&booking.HeldAt, &booking.ConfirmedAt, &booking.TicketedAt, &booking.CancelledAt, &booking.RefundedAt,
+		&booking.HoldExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// GetAll lists bookings ordered by ID, only those of userID when it is not 0.
+func (r *BookingRepository) GetAll(ctx context.Context, userID int) ([]*model.Booking, error) {
+	query := `SELECT ` + bookingColumns + `
+		FROM booking
+		WHERE $1 = 0 OR cst_id = $1
+		ORDER BY booking_id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []*model.Booking{}
+	byID := map[int]*model.Booking{}
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+		byID[booking.BookingID] = booking
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadPassengers(ctx, byID); err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *BookingRepository) GetByID(ctx context.Context, bookingID int) (*model.Booking, error) {
+	query := `SELECT ` + bookingColumns + `
+		FROM booking
+		WHERE booking_id = $1
+	`
+
+	booking, err := scanBooking(r.db.QueryRow(ctx, query, bookingID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadPassengers(ctx, map[int]*model.Booking{booking.BookingID: booking}); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// loadPassengers fills in the passengers of the given bookings, the customer
+// first and then family members by ID.
+func (r *BookingRepository) loadPassengers(ctx context.Context, bookings map[int]*model.Booking) error {
+	if len(bookings) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(bookings))
+	for id := range bookings {
+		ids = append(ids, id)
+	}
+
+	query := `SELECT bp.booking_id, bp.passenger_id, COALESCE(bp.fl_id, 0), bp.fl_id IS NULL,
+			COALESCE(fl.fl_name, c.cst_name), COALESCE(fl.fl_dob, c.cst_dob), bp.cancelled_at
+		FROM booking_passenger bp
+		JOIN customer c ON c.customer_id = bp.cst_id
+		LEFT JOIN family_list fl ON fl.fl_id = bp.fl_id
+		WHERE bp.booking_id = ANY($1)
+		ORDER BY bp.booking_id, bp.fl_id NULLS FIRST
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bookingID int
+			passenger model.Passenger
+		)
+		if err := rows.Scan(&bookingID, &passenger.PassengerID, &passenger.FamilyID, &passenger.IsCustomer,
+			&passenger.Name, &passenger.Dob, &passenger.CancelledAt); err != nil {
+			return err
+		}
+		booking := bookings[bookingID]
+		booking.Passengers = append(booking.Passengers, passenger)
+	}
+	return rows.Err()
+}
+
+func (r *BookingRepository) Create(ctx context.Context, booking *model.Booking) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	bookingQuery := `INSERT INTO booking (cst_id, trip_id, fare_class, status)
+		VALUES ($1, NULLIF($2, 0), $3, $4)
+		RETURNING booking_id, created_at
+	`
+
+	booking.Status = model.BookingDraft
+	err = tx.QueryRow(ctx, bookingQuery, booking.UserID, booking.TripID, booking.FareClass, booking.Status).
+		Scan(&booking.BookingID, &booking.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPassengers(ctx, tx, booking.BookingID, booking.UserID, booking.Passengers); err != nil {
+		return err
+	}
+
+	if err := insertHistory(ctx, tx, booking.BookingID, "", booking.Status, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplacePassengers swaps the passenger list of a booking in one transaction,
+// provided the booking is still in status. Passengers on both lists keep
+// their row. It returns pgx.ErrNoRows when the booking does not exist,
+// model.ErrStatusChanged when its status moved on and model.ErrConflict when
+// a passenger to remove is on an earlier payment.
+func (r *BookingRepository) ReplacePassengers(ctx context.Context, bookingID int, status model.BookingStatus, passengers []model.Passenger) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var (
+		userID        int
+		currentStatus model.BookingStatus
+	)
+	lockQuery := `SELECT cst_id, status FROM booking WHERE booking_id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, bookingID).Scan(&userID, &currentStatus); err != nil {
+		return err
+	}
+	if currentStatus != status {
+		return model.ErrStatusChanged
+	}
+
+	keepCustomer, keepFamilyIDs := false, []int{}
+	for _, passenger := range passengers {
+		if passenger.IsCustomer {
+			keepCustomer = true
+			continue
+		}
+		keepFamilyIDs = append(keepFamilyIDs, passenger.FamilyID)
+	}
+
+	removeQuery := `DELETE FROM booking_passenger
+		WHERE booking_id = $1 AND NOT ((fl_id IS NULL AND $2::boolean) OR fl_id = ANY($3::int4[]))
+	`
+	if _, err := tx.Exec(ctx, removeQuery, bookingID, keepCustomer, keepFamilyIDs); err != nil {
+		return paymentConflict(err, fmt.Sprintf("a passenger of booking %d", bookingID))
+	}
+
+	existing := map[int]bool{}
+	rows, err := tx.Query(ctx, `SELECT COALESCE(fl_id, 0) FROM booking_passenger WHERE booking_id = $1`, bookingID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var familyID int
+		if err := rows.Scan(&familyID); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[familyID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	added := []model.Passenger{}
+	for _, passenger := range passengers {
+		if !existing[passenger.FamilyID] {
+			added = append(added, passenger)
+		}
+	}
+	if err := insertPassengers(ctx, tx, bookingID, userID, added); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE booking SET updated_at = CURRENT_TIMESTAMP WHERE booking_id = $1`, bookingID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertPassengers attaches the customer and family members to a booking.
+// Family members are selected through the customer's own family_list rows,
+// so an ID belonging to someone else inserts nothing and is reported as
+// model.ErrPassengerNotInFamily.
+func insertPassengers(ctx context.Context, tx pgx.Tx, bookingID, userID int, passengers []model.Passenger) error {
+	familyIDs := []int{}
+	for _, passenger := range passengers {
+		if passenger.IsCustomer {
+			customerQuery := `INSERT INTO booking_passenger (booking_id, cst_id, fl_id) VALUES ($1, $2, NULL)`
+			if _, err := tx.Exec(ctx, customerQuery, bookingID, userID); err != nil {
+				return fmt.Errorf("failed to add customer as passenger: %w", err)
+			}
+			continue
+		}
+		familyIDs = append(familyIDs, passenger.FamilyID)
+	}
+
+	if len(familyIDs) == 0 {
+		return nil
+	}
+
+	familyQuery := `INSERT INTO booking_passenger (booking_id, cst_id, fl_id)
+		SELECT $1, fl.cst_id, fl.fl_id
+		FROM family_list fl
+		WHERE fl.cst_id = $2 AND fl.fl_id = ANY($3)
+		ORDER BY fl.fl_id
+	`
+
+	tag, err := tx.Exec(ctx, familyQuery, bookingID, userID, familyIDs)
+	if err != nil {
+		return fmt.Errorf("failed to add family passengers: %w", err)
+	}
+	if tag.RowsAffected() != int64(len(familyIDs)) {
+		return model.ErrPassengerNotInFamily
+	}
+
+	return nil
+}
+
+// Delete removes a booking, its passengers and history, provided it is still
+// in status. Errors are as for ReplacePassengers; a booking with payments is
+// kept as a model.ErrConflict.
+func (r *BookingRepository) Delete(ctx context.Context, bookingID int, status model.BookingStatus) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM booking WHERE booking_id = $1 AND status = $2`, bookingID, status)
+	if err != nil {
+		return paymentConflict(err, fmt.Sprintf("booking %d", bookingID))
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missingOrChanged(ctx, bookingID)
+	}
+	return nil
+}
+
+// UpdateStatus moves a booking from update.From to update.To, stamping the
+// matching *_at column, applying the seat change and appending to the history
+// in one transaction. The update only applies while the booking is still in
+// From, so two concurrent transitions cannot both succeed; the loser gets
+// model.ErrStatusChanged.
+//
+// Rows are locked seat_hold, then trip, then booking, the same order as every
+// other seat change, so concurrent transitions and the reaper cannot
+// deadlock. A new hold locks the booking before the trip, which is safe as no
+// seat_hold row exists for it yet.
+func (r *BookingRepository) UpdateStatus(ctx context.Context, update model.StatusUpdate) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if update.Seats == model.SeatsConfirm || update.Seats == model.SeatsRelease {
+		if err := changeHeldSeats(ctx, tx, update); err != nil {
+			return err
+		}
+	}
+
+	set := "status = $3, updated_at = CURRENT_TIMESTAMP"
+	if column, ok := statusColumns[update.To]; ok {
+		set += ", " + column + " = CURRENT_TIMESTAMP"
+	}
+	updateQuery := `UPDATE booking SET ` + set + ` WHERE booking_id = $1 AND status = $2`
+
+	tag, err := tx.Exec(ctx, updateQuery, update.BookingID, update.From, update.To)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missingOrChanged(ctx, update.BookingID)
+	}
+
+	if update.Seats == model.SeatsHold {
+		if err := holdSeats(ctx, tx, update); err != nil {
+			return err
+		}
+	}
+
+	if err := insertHistory(ctx, tx, update.BookingID, update.From, update.To, update.Reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// holdSeats takes one seat per passenger from the booking's trip. The
+// conditional UPDATE re-checks availability after waiting for the trip row
+// lock, so racing holds can never take the count below zero.
+func holdSeats(ctx context.Context, tx pgx.Tx, update model.StatusUpdate) error {
+	var (
+		tripID *int
+		seats  int
+	)
+	seatsQuery := `SELECT b.trip_id,
+			(SELECT count(*) FROM booking_passenger bp WHERE bp.booking_id = b.booking_id AND bp.cancelled_at IS NULL)
+		FROM booking b
+		WHERE b.booking_id = $1
+	`
+	if err := tx.QueryRow(ctx, seatsQuery, update.BookingID).Scan(&tripID, &seats); err != nil {
+		return err
+	}
+	if tripID == nil {
+		return model.ErrNoTrip
+	}
+
+	decrementQuery := `UPDATE trip SET available = available - $2 WHERE trip_id = $1 AND available >= $2`
+	tag, err := tx.Exec(ctx, decrementQuery, *tripID, seats)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrSoldOut
+	}
+
+	holdQuery := `INSERT INTO seat_hold (trip_id, booking_id, seats, expires_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP + $4 * interval '1 millisecond')
+	`
+	if _, err := tx.Exec(ctx, holdQuery, *tripID, update.BookingID, seats, update.HoldTTL.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to record seat hold: %w", err)
+	}
+
+	return nil
+}
+
+// changeHeldSeats confirms or releases the booking's current hold. A booking
+// without one has nothing to release.
+func changeHeldSeats(ctx context.Context, tx pgx.Tx, update model.StatusUpdate) error {
+	var (
+		holdID, tripID, seats int
+		status                string
+		expired               bool
+	)
+	holdQuery := `SELECT hold_id, trip_id, seats, status, expires_at <= CURRENT_TIMESTAMP
+		FROM seat_hold
+		WHERE booking_id = $1 AND status IN ('active', 'confirmed')
+		FOR UPDATE
+	`
+	err := tx.QueryRow(ctx, holdQuery, update.BookingID).Scan(&holdID, &tripID, &seats, &status, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		switch {
+		case update.Seats == model.SeatsConfirm:
+			return model.ErrHoldExpired
+		case update.ExpiredOnly:
+			return model.ErrStatusChanged
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if update.Seats == model.SeatsConfirm {
+		if status != "active" || expired {
+			return model.ErrHoldExpired
+		}
+		_, err := tx.Exec(ctx, `UPDATE seat_hold SET status = 'confirmed' WHERE hold_id = $1`, holdID)
+		return err
+	}
+
+	if update.ExpiredOnly && (status != "active" || !expired) {
+		return model.ErrStatusChanged
+	}
+	releaseQuery := `UPDATE seat_hold SET status = 'released', released_at = CURRENT_TIMESTAMP WHERE hold_id = $1`
+	if _, err := tx.Exec(ctx, releaseQuery, holdID); err != nil {
+		return err
+	}
+	restockQuery := `UPDATE trip SET available = available + $2 WHERE trip_id = $1`
+	if _, err := tx.Exec(ctx, restockQuery, tripID, seats); err != nil {
+		return fmt.Errorf("failed to return seats: %w", err)
+	}
+
+	return nil
+}
+
+// CancelPassengers applies a cancellation in one transaction: the passengers
+// are marked cancelled, their seats go back to the trip and the refund, if
+// any, is recorded as pending. Once no passenger is left the booking moves to
+// cancelled and its hold is released. It returns pgx.ErrNoRows when the
+// booking does not exist, model.ErrStatusChanged when its status moved on
+// and model.ErrPassengerNotActive when a passenger is not on the booking or
+// already cancelled. Rows are locked in the same order as UpdateStatus.
+func (r *BookingRepository) CancelPassengers(ctx context.Context, cancellation model.PassengerCancellation) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var holdID, tripID, seats int
+	holdQuery := `SELECT hold_id, trip_id, seats
+		FROM seat_hold
+		WHERE booking_id = $1 AND status IN ('active', 'confirmed')
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, holdQuery, cancellation.BookingID).Scan(&holdID, &tripID, &seats)
+	hasHold := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if hasHold {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM trip WHERE trip_id = $1 FOR UPDATE`, tripID); err != nil {
+			return err
+		}
+	}
+
+	var currentStatus model.BookingStatus
+	lockQuery := `SELECT status FROM booking WHERE booking_id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, cancellation.BookingID).Scan(&currentStatus); err != nil {
+		return err
+	}
+	if currentStatus != cancellation.Status {
+		return model.ErrStatusChanged
+	}
+
+	cancelQuery := `UPDATE booking_passenger SET cancelled_at = CURRENT_TIMESTAMP
+		WHERE booking_id = $1 AND passenger_id = ANY($2) AND cancelled_at IS NULL
+	`
+	tag, err := tx.Exec(ctx, cancelQuery, cancellation.BookingID, cancellation.PassengerIDs)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != int64(len(cancellation.PassengerIDs)) {
+		return model.ErrPassengerNotActive
+	}
+	cancelled := len(cancellation.PassengerIDs)
+
+	var remaining int
+	remainingQuery := `SELECT count(*) FROM booking_passenger WHERE booking_id = $1 AND cancelled_at IS NULL`
+	if err := tx.QueryRow(ctx, remainingQuery, cancellation.BookingID).Scan(&remaining); err != nil {
+		return err
+	}
+
+	if hasHold {
+		// the last passengers release the whole hold
+		returned := cancelled
+		if remaining == 0 {
+			returned = seats
+			releaseQuery := `UPDATE seat_hold SET status = 'released', released_at = CURRENT_TIMESTAMP WHERE hold_id = $1`
+			if _, err := tx.Exec(ctx, releaseQuery, holdID); err != nil {
+				return err
+			}
+		} else {
+			shrinkQuery := `UPDATE seat_hold SET seats = seats - $2 WHERE hold_id = $1`
+			if _, err := tx.Exec(ctx, shrinkQuery, holdID, cancelled); err != nil {
+				return err
+			}
+		}
+		restockQuery := `UPDATE trip SET available = available + $2 WHERE trip_id = $1`
+		if _, err := tx.Exec(ctx, restockQuery, tripID, returned); err != nil {
+			return fmt.Errorf("failed to return seats: %w", err)
+		}
+	}
+
+	if remaining == 0 {
+		updateQuery := `UPDATE booking
+			SET status = $2, cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE booking_id = $1
+		`
+		if _, err := tx.Exec(ctx, updateQuery, cancellation.BookingID, model.BookingCancelled); err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, cancellation.BookingID, currentStatus, model.BookingCancelled, cancellation.Reason); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `UPDATE booking SET updated_at = CURRENT_TIMESTAMP WHERE booking_id = $1`, cancellation.BookingID); err != nil {
+			return err
+		}
+	}
+
+	if cancellation.Refund != nil {
+		if err := insertRefund(ctx, tx, cancellation.Refund); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ExpiredHolds returns up to limit bookings whose active hold has expired,
+// oldest first.
+func (r *BookingRepository) ExpiredHolds(ctx context.Context, limit int) ([]int, error) {
+	query := `SELECT booking_id
+		FROM seat_hold
+		WHERE status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+		ORDER BY expires_at
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookingIDs := []int{}
+	for rows.Next() {
+		var bookingID int
+		if err := rows.Scan(&bookingID); err != nil {
+			return nil, err
+		}
+		bookingIDs = append(bookingIDs, bookingID)
+	}
+
+	return bookingIDs, rows.Err()
+}
+
+func (r *BookingRepository) History(ctx context.Context, bookingID int) ([]model.BookingStatusChange, error) {
+	query := `SELECT history_id, booking_id, COALESCE(from_status, ''), to_status, COALESCE(reason, ''), created_at
+		FROM booking_status_history
+		WHERE booking_id = $1
+		ORDER BY history_id
+	`
+
+	rows, err := r.db.Query(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []model.BookingStatusChange{}
+	for rows.Next() {
+		var change model.BookingStatusChange
+		if err := rows.Scan(&change.HistoryID, &change.BookingID, &change.FromStatus, &change.ToStatus,
+			&change.Reason, &change.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+
+	return history, rows.Err()
+}
+
+func insertHistory(ctx context.Context, tx pgx.Tx, bookingID int, from, to model.BookingStatus, reason string) error {
+	historyQuery := `INSERT INTO booking_status_history (booking_id, from_status, to_status, reason)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''))
+	`
+
+	if _, err := tx.Exec(ctx, historyQuery, bookingID, string(from), string(to), reason); err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
+	return nil
+}
+
+// paymentConflict reports a delete refused because payment records refer to
+// what is being deleted.
+func paymentConflict(err error, what string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return fmt.Errorf("%s has payment records and cannot be removed: %w", what, model.ErrConflict)
+	}
+	return err
+}
+
+// missingOrChanged explains why a status-guarded statement touched no rows.
+func (r *BookingRepository) missingOrChanged(ctx context.Context, bookingID int) error {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM booking WHERE booking_id = $1)`, bookingID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return pgx.ErrNoRows
+	}
+	return model.ErrStatusChanged
+}
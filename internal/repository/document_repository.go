@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IDocumentRepository interface {
+	GetByUser(ctx context.Context, userID int) ([]*model.TravelDocument, error)
+	GetByID(ctx context.Context, userID, documentID int) (*model.TravelDocument, error)
+	Create(ctx context.Context, document *model.TravelDocument) error
+	Update(ctx context.Context, document *model.TravelDocument) error
+	Delete(ctx context.Context, userID, documentID int) error
+}
+
+type DocumentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDocumentRepository(db *pgxpool.Pool) *DocumentRepository {
+	return &DocumentRepository{
+		db: db,
+	}
+}
+
+const documentColumns = `d.document_id, d.cst_id, COALESCE(d.fl_id, 0), d.document_type,
+	d.issuing_nationality_id, COALESCE(n.nationality_name, ''), COALESCE(n.nationality_code, ''),
+	d.number_encrypted, d.number_last4, to_char(d.expires_on, 'YYYY-MM-DD'), d.created_at, d.updated_at`
+
+const documentFrom = ` FROM travel_document d
+	LEFT JOIN nationality n ON n.nationality_id = d.issuing_nationality_id`
+
+func scanDocument(row pgx.Row) (*model.TravelDocument, error) {
+	document := &model.TravelDocument{}
+	err := row.Scan(&document.DocumentID, &document.UserID, &document.FamilyID, &document.Type,
+		&document.IssuingNationalityID, &document.IssuingCountry.NationalityName, &document.IssuingCountry.NationalityCode,
+		&document.NumberEncrypted, &document.NumberLast4, &document.ExpiresOn, &document.CreatedAt, &document.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	document.IssuingCountry.NationalityID = document.IssuingNationalityID
+	return document, nil
+}
+
+// GetByUser lists the documents of a customer and their family members,
+// the customer's own first.
+func (r *DocumentRepository) GetByUser(ctx context.Context, userID int) ([]*model.TravelDocument, error) {
+	query := `SELECT ` + documentColumns + documentFrom + `
+		WHERE d.cst_id = $1
+		ORDER BY d.fl_id NULLS FIRST, d.document_id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []*model.TravelDocument{}
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+func (r *DocumentRepository) GetByID(ctx context.Context, userID, documentID int) (*model.TravelDocument, error) {
+	query := `SELECT ` + documentColumns + documentFrom + ` WHERE d.cst_id = $1 AND d.document_id = $2`
+	return scanDocument(r.db.QueryRow(ctx, query, userID, documentID))
+}
+
+func (r *DocumentRepository) Create(ctx context.Context, document *model.TravelDocument) error {
+	query := `INSERT INTO travel_document (cst_id, fl_id, document_type, issuing_nationality_id,
+			number_encrypted, number_last4, expires_on)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7)
+		RETURNING document_id, created_at
+	`
+	return r.db.QueryRow(ctx, query, document.UserID, document.FamilyID, document.Type, document.IssuingNationalityID,
+		document.NumberEncrypted, document.NumberLast4, document.ExpiresOn).Scan(&document.DocumentID, &document.CreatedAt)
+}
+
+// Update replaces a document; its owner does not change. It returns
+// pgx.ErrNoRows when the customer has no such document.
+func (r *DocumentRepository) Update(ctx context.Context, document *model.TravelDocument) error {
+	query := `UPDATE travel_document
+		SET document_type = $3, issuing_nationality_id = $4, number_encrypted = $5, number_last4 = $6,
+			expires_on = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE cst_id = $1 AND document_id = $2
+		RETURNING COALESCE(fl_id, 0), created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query, document.UserID, document.DocumentID, document.Type, document.IssuingNationalityID,
+		document.NumberEncrypted, document.NumberLast4, document.ExpiresOn).
+		Scan(&document.FamilyID, &document.CreatedAt, &document.UpdatedAt)
+}
+
+// Delete removes a document. It returns pgx.ErrNoRows when the customer has
+// no such document.
+func (r *DocumentRepository) Delete(ctx context.Context, userID, documentID int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM travel_document WHERE cst_id = $1 AND document_id = $2`, userID, documentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
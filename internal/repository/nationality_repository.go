@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type INationalityRepository interface {
+	GetAll(ctx context.Context) ([]model.Nationality, error)
+	GetByID(ctx context.Context, nationalityID int) (*model.Nationality, error)
+}
+
+type NationalityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNationalityRepository(db *pgxpool.Pool) *NationalityRepository {
+	return &NationalityRepository{
+		db: db,
+	}
+}
+
+func (r *NationalityRepository) GetAll(ctx context.Context) ([]model.Nationality, error) {
+	query := `SELECT nationality_id, nationality_name, nationality_code
+		FROM nationality
+		ORDER BY nationality_code, nationality_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nationalities := []model.Nationality{}
+	for rows.Next() {
+		var nationality model.Nationality
+		if err := rows.Scan(&nationality.NationalityID, &nationality.NationalityName, &nationality.NationalityCode); err != nil {
+			return nil, err
+		}
+		nationalities = append(nationalities, nationality)
+	}
+
+	return nationalities, rows.Err()
+}
+
+func (r *NationalityRepository) GetByID(ctx context.Context, nationalityID int) (*model.Nationality, error) {
+	query := `SELECT nationality_id, nationality_name, nationality_code FROM nationality WHERE nationality_id = $1`
+
+	nationality := &model.Nationality{}
+	err := r.db.QueryRow(ctx, query, nationalityID).
+		Scan(&nationality.NationalityID, &nationality.NationalityName, &nationality.NationalityCode)
+	if err != nil {
+		return nil, err
+	}
+	return nationality, nil
+}
@@ -0,0 +1,428 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IPaymentRepository interface {
+	GetByID(ctx context.Context, paymentID int) (*model.Payment, error)
+	GetByIntent(ctx context.Context, gateway, intentID string) (*model.Payment, error)
+	GetByBooking(ctx context.Context, bookingID int) ([]*model.Payment, error)
+	Current(ctx context.Context, bookingID int) (*model.Payment, error)
+	Create(ctx context.Context, payment *model.Payment) error
+	UpdateStatus(ctx context.Context, paymentID int, from, to model.PaymentStatus) error
+	ApplyEvent(ctx context.Context, payment *model.Payment, event *model.PaymentEvent) (applied bool, refund *model.Refund, err error)
+	ToReconcile(ctx context.Context, pendingFor time.Duration, limit int) ([]*model.Payment, error)
+	Items(ctx context.Context, paymentID int) ([]model.PaymentItem, error)
+	CreateRefund(ctx context.Context, refund *model.Refund) error
+	CompleteRefund(ctx context.Context, refundID int, gatewayRefundID string) error
+	RefundsByBooking(ctx context.Context, bookingID int) ([]*model.Refund, error)
+	PendingRefunds(ctx context.Context, limit int) ([]*model.Refund, error)
+}
+
+type PaymentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) *PaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+const paymentColumns = `payment_id, booking_id, gateway, intent_id, amount, currency, status,
+	created_at, updated_at, paid_at`
+
+func scanPayment(row pgx.Row) (*model.Payment, error) {
+	payment := &model.Payment{}
+	err := row.Scan(&payment.PaymentID, &payment.BookingID, &payment.Gateway, &payment.IntentID, &payment.Amount,
+		&payment.Currency, &payment.Status, &payment.CreatedAt, &payment.UpdatedAt, &payment.PaidAt)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (r *PaymentRepository) queryPayments(ctx context.Context, query string, args ...any) ([]*model.Payment, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []*model.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, paymentID int) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE payment_id = $1`
+	return scanPayment(r.db.QueryRow(ctx, query, paymentID))
+}
+
+func (r *PaymentRepository) GetByIntent(ctx context.Context, gateway, intentID string) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE gateway = $1 AND intent_id = $2`
+	return scanPayment(r.db.QueryRow(ctx, query, gateway, intentID))
+}
+
+// GetByBooking lists every payment attempt of a booking, oldest first.
+func (r *PaymentRepository) GetByBooking(ctx context.Context, bookingID int) ([]*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE booking_id = $1 ORDER BY payment_id`
+	return r.queryPayments(ctx, query, bookingID)
+}
+
+// Current returns the pending or succeeded payment of a booking, or
+// pgx.ErrNoRows when there is none.
+func (r *PaymentRepository) Current(ctx context.Context, bookingID int) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment
+		WHERE booking_id = $1 AND status IN ('pending', 'succeeded')`
+	return scanPayment(r.db.QueryRow(ctx, query, bookingID))
+}
+
+// Create stores a payment with its items. A second current payment for the
+// same booking fails with model.ErrConflict.
+func (r *PaymentRepository) Create(ctx context.Context, payment *model.Payment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO payment (booking_id, gateway, intent_id, amount, currency, status, paid_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $6 = 'succeeded' THEN CURRENT_TIMESTAMP END)
+		RETURNING payment_id, created_at, paid_at
+	`
+
+	err = tx.QueryRow(ctx, query, payment.BookingID, payment.Gateway, payment.IntentID, payment.Amount,
+		payment.Currency, payment.Status).Scan(&payment.PaymentID, &payment.CreatedAt, &payment.PaidAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return fmt.Errorf("booking %d already has a payment in progress: %w", payment.BookingID, model.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `INSERT INTO payment_item (payment_id, passenger_id, band, amount) VALUES ($1, $2, $3, $4)`
+	for _, item := range payment.Items {
+		if _, err := tx.Exec(ctx, itemQuery, payment.PaymentID, item.PassengerID, item.Band, item.Amount); err != nil {
+			return fmt.Errorf("failed to insert payment item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Items returns the passengers a payment covers and what each of them paid.
+func (r *PaymentRepository) Items(ctx context.Context, paymentID int) ([]model.PaymentItem, error) {
+	query := `SELECT passenger_id, band, amount FROM payment_item WHERE payment_id = $1 ORDER BY passenger_id`
+	rows, err := r.db.Query(ctx, query, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.PaymentItem{}
+	for rows.Next() {
+		var item model.PaymentItem
+		if err := rows.Scan(&item.PassengerID, &item.Band, &item.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// UpdateStatus moves a payment from one status to another. It returns
+// pgx.ErrNoRows when the payment is not in from.
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID int, from, to model.PaymentStatus) error {
+	query := `UPDATE payment
+		SET status = $3, updated_at = CURRENT_TIMESTAMP,
+			paid_at = CASE WHEN $3 = 'succeeded' THEN CURRENT_TIMESTAMP ELSE paid_at END
+		WHERE payment_id = $1 AND status = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, paymentID, from, to)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ApplyEvent records a gateway event and settles the pending payment it
+// reports on, in one transaction. It returns false without changing anything
+// when the event was already recorded, so webhook redeliveries are harmless.
+// A payment that is no longer pending keeps its status; if it was cancelled
+// or failed and the gateway reports it paid after all, a pending refund of
+// the whole amount is recorded and returned.
+func (r *PaymentRepository) ApplyEvent(ctx context.Context, payment *model.Payment, event *model.PaymentEvent) (bool, *model.Refund, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var status model.PaymentStatus
+	lockQuery := `SELECT status FROM payment WHERE payment_id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, payment.PaymentID).Scan(&status); err != nil {
+		return false, nil, err
+	}
+
+	eventQuery := `INSERT INTO payment_event (event_id, gateway, payment_id, status)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, eventQuery, event.EventID, payment.Gateway, payment.PaymentID, event.Status)
+	if err != nil {
+		return false, nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil, nil
+	}
+
+	var refund *model.Refund
+	switch {
+	case status == model.PaymentPending:
+		updateQuery := `UPDATE payment
+			SET status = $2, updated_at = CURRENT_TIMESTAMP,
+				paid_at = CASE WHEN $2 = 'succeeded' THEN CURRENT_TIMESTAMP ELSE paid_at END
+			WHERE payment_id = $1
+		`
+		if _, err := tx.Exec(ctx, updateQuery, payment.PaymentID, event.Status); err != nil {
+			return false, nil, err
+		}
+	case event.Status == model.PaymentSucceeded && payment.Amount > 0 &&
+		(status == model.PaymentCancelled || status == model.PaymentFailed):
+		var refunded bool
+		refundedQuery := `SELECT EXISTS (SELECT 1 FROM refund WHERE payment_id = $1)`
+		if err := tx.QueryRow(ctx, refundedQuery, payment.PaymentID).Scan(&refunded); err != nil {
+			return false, nil, err
+		}
+		if refunded {
+			break
+		}
+		refund = &model.Refund{
+			PaymentID: payment.PaymentID,
+			BookingID: payment.BookingID,
+			Amount:    payment.Amount,
+			Currency:  payment.Currency,
+			Reason:    fmt.Sprintf("paid after the payment was %s", status),
+		}
+		if err := insertRefund(ctx, tx, refund); err != nil {
+			return false, nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, refund, nil
+}
+
+// ToReconcile lists payments that may be out of step with their booking:
+// pending for longer than pendingFor (a webhook may have been missed) and
+// succeeded while the booking is still held, back in draft, or cancelled
+// without ever being confirmed, unless a refund was already recorded for it.
+// Bookings cancelled after confirmation were refunded by the cancellation
+// policy.
+func (r *PaymentRepository) ToReconcile(ctx context.Context, pendingFor time.Duration, limit int) ([]*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment
+		WHERE (status = 'pending' AND created_at < CURRENT_TIMESTAMP - $1 * interval '1 millisecond')
+			OR (status = 'succeeded' AND booking_id IN (
+				SELECT booking_id FROM booking
+				WHERE status IN ('held', 'draft') OR (status = 'cancelled' AND confirmed_at IS NULL))
+				AND NOT EXISTS (SELECT 1 FROM refund WHERE refund.payment_id = payment.payment_id))
+		ORDER BY payment_id
+		LIMIT $2
+	`
+	return r.queryPayments(ctx, query, pendingFor.Milliseconds(), limit)
+}
+
+const refundColumns = `r.refund_id, r.payment_id, p.booking_id, r.amount, p.currency, r.status,
+	COALESCE(r.gateway_refund_id, ''), COALESCE(r.reason, ''), r.created_at, r.completed_at`
+
+// insertRefund records a pending refund and its items inside tx.
+func insertRefund(ctx context.Context, tx pgx.Tx, refund *model.Refund) error {
+	query := `INSERT INTO refund (payment_id, amount, reason)
+		VALUES ($1, $2, NULLIF($3, ''))
+		RETURNING refund_id, status, created_at
+	`
+	err := tx.QueryRow(ctx, query, refund.PaymentID, refund.Amount, refund.Reason).
+		Scan(&refund.RefundID, &refund.Status, &refund.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert refund: %w", err)
+	}
+
+	itemQuery := `INSERT INTO refund_item (refund_id, passenger_id, paid, rule, percent, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for _, item := range refund.Items {
+		_, err := tx.Exec(ctx, itemQuery, refund.RefundID, item.PassengerID, item.Paid, item.Rule, item.Percent, item.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to insert refund item: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateRefund records a pending refund of a payment.
+func (r *PaymentRepository) CreateRefund(ctx context.Context, refund *model.Refund) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := insertRefund(ctx, tx, refund); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteRefund marks a pending refund as accepted by the gateway. Once the
+// succeeded refunds of a payment add up to its amount the payment becomes
+// refunded. It returns pgx.ErrNoRows when the refund is not pending.
+func (r *PaymentRepository) CompleteRefund(ctx context.Context, refundID int, gatewayRefundID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var paymentID int
+	refundQuery := `UPDATE refund
+		SET status = 'succeeded', gateway_refund_id = NULLIF($2, ''), completed_at = CURRENT_TIMESTAMP
+		WHERE refund_id = $1 AND status = 'pending'
+		RETURNING payment_id
+	`
+	if err := tx.QueryRow(ctx, refundQuery, refundID, gatewayRefundID).Scan(&paymentID); err != nil {
+		return err
+	}
+
+	paymentQuery := `UPDATE payment SET status = 'refunded', updated_at = CURRENT_TIMESTAMP
+		WHERE payment_id = $1 AND status = 'succeeded'
+			AND amount <= (SELECT sum(amount) FROM refund WHERE payment_id = $1 AND status = 'succeeded')
+	`
+	if _, err := tx.Exec(ctx, paymentQuery, paymentID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RefundsByBooking lists the refunds of every payment of a booking, oldest
+// first.
+func (r *PaymentRepository) RefundsByBooking(ctx context.Context, bookingID int) ([]*model.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refund r JOIN payment p USING (payment_id)
+		WHERE p.booking_id = $1
+		ORDER BY r.refund_id
+	`
+	return r.queryRefunds(ctx, query, bookingID)
+}
+
+// PendingRefunds lists refunds the gateway has not accepted yet, oldest
+// first.
+func (r *PaymentRepository) PendingRefunds(ctx context.Context, limit int) ([]*model.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refund r JOIN payment p USING (payment_id)
+		WHERE r.status = 'pending'
+		ORDER BY r.refund_id
+		LIMIT $1
+	`
+	return r.queryRefunds(ctx, query, limit)
+}
+
+func (r *PaymentRepository) queryRefunds(ctx context.Context, query string, args ...any) ([]*model.Refund, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []*model.Refund{}
+	byID := map[int]*model.Refund{}
+	ids := []int{}
+	for rows.Next() {
+		refund := &model.Refund{}
+		err := rows.Scan(&refund.RefundID, &refund.PaymentID, &refund.BookingID, &refund.Amount, &refund.Currency,
+			&refund.Status, &refund.GatewayRefundID, &refund.Reason, &refund.CreatedAt, &refund.CompletedAt)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+		byID[refund.RefundID] = refund
+		ids = append(ids, refund.RefundID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return refunds, nil
+	}
+
+	itemQuery := `SELECT ri.refund_id, ri.passenger_id, COALESCE(fl.fl_name, c.cst_name), COALESCE(pi.band, ''),
+			ri.paid, ri.rule, ri.percent, ri.amount
+		FROM refund_item ri
+		JOIN refund r ON r.refund_id = ri.refund_id
+		JOIN booking_passenger bp ON bp.passenger_id = ri.passenger_id
+		JOIN customer c ON c.customer_id = bp.cst_id
+		LEFT JOIN family_list fl ON fl.fl_id = bp.fl_id
+		LEFT JOIN payment_item pi ON pi.payment_id = r.payment_id AND pi.passenger_id = ri.passenger_id
+		WHERE ri.refund_id = ANY($1)
+		ORDER BY ri.refund_id, ri.passenger_id
+	`
+	itemRows, err := r.db.Query(ctx, itemQuery, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			refundID int
+			item     model.RefundItem
+		)
+		err := itemRows.Scan(&refundID, &item.PassengerID, &item.Name, &item.Band,
+			&item.Paid, &item.Rule, &item.Percent, &item.Amount)
+		if err != nil {
+			return nil, err
+		}
+		byID[refundID].Items = append(byID[refundID].Items, item)
+	}
+	return refunds, itemRows.Err()
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ITripRepository interface {
+	GetAll(ctx context.Context) ([]*model.Trip, error)
+	GetByID(ctx context.Context, tripID int) (*model.Trip, error)
+	Create(ctx context.Context, trip *model.Trip) error
+}
+
+type TripRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTripRepository(db *pgxpool.Pool) *TripRepository {
+	return &TripRepository{
+		db: db,
+	}
+}
+
+// SQLSTATEs of the constraint violations mapped to model errors.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+const tripColumns = `trip_id, trip_code, origin, destination, departure_at, capacity, fare, international, available, created_at`
+
+func scanTrip(row pgx.Row) (*model.Trip, error) {
+	trip := &model.Trip{}
+	err := row.Scan(&trip.TripID, &trip.TripCode, &trip.Origin, &trip.Destination, &trip.DepartureAt,
+		&trip.Capacity, &trip.Fare, &trip.International, &trip.Available, &trip.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+func (r *TripRepository) GetAll(ctx context.Context) ([]*model.Trip, error) {
+	query := `SELECT ` + tripColumns + ` FROM trip ORDER BY departure_at, trip_id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trips := []*model.Trip{}
+	for rows.Next() {
+		trip, err := scanTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, rows.Err()
+}
+
+func (r *TripRepository) GetByID(ctx context.Context, tripID int) (*model.Trip, error) {
+	query := `SELECT ` + tripColumns + ` FROM trip WHERE trip_id = $1`
+	return scanTrip(r.db.QueryRow(ctx, query, tripID))
+}
+
+// Create inserts a trip with every seat available.
+func (r *TripRepository) Create(ctx context.Context, trip *model.Trip) error {
+	query := `INSERT INTO trip (trip_code, origin, destination, departure_at, capacity, fare, international, available)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $5)
+		RETURNING trip_id, available, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, trip.TripCode, trip.Origin, trip.Destination, trip.DepartureAt, trip.Capacity, trip.Fare, trip.International).
+		Scan(&trip.TripID, &trip.Available, &trip.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "trip_trip_code_key" {
+		return fmt.Errorf("trip code %q already exists: %w", trip.TripCode, model.ErrConflict)
+	}
+	return err
+}
@@ -1,15 +1,16 @@
 package repository
 
 import (
+	"booking_togo/internal/logger"
 	"booking_togo/internal/model"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,6 +21,7 @@ type IUserRepository interface {
 	Delete(ctx context.Context, userID int) error
 	DeleteFamily(ctx context.Context, userID int, familyID int) error
 	GetUserDetail(ctx context.Context, userID int) (*model.UserDetailResponse, error)
+	Search(ctx context.Context, search model.UserSearch) ([]*model.UserDetailResponse, error)
 }
 
 type UserRepository struct {
@@ -33,6 +35,19 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 }
 
 func (r *UserRepository) GetAll(ctx context.Context) ([]*model.UserDetailResponse, error) {
+	return r.list(ctx, "")
+}
+
+// Search lists the customers matching every non-empty field of search;
+// emails are compared regardless of case.
+func (r *UserRepository) Search(ctx context.Context, search model.UserSearch) ([]*model.UserDetailResponse, error) {
+	return r.list(ctx, `where ($1 = '' or lower(cust.cst_email) = lower($1))
+			and ($2 = '' or cust.cst_phone = $2)
+			order by cust.customer_id`, search.Email, search.Phone)
+}
+
+// list runs the customer listing query with an optional where clause.
+func (r *UserRepository) list(ctx context.Context, where string, args ...any) ([]*model.UserDetailResponse, error) {
 	families := model.FamiliesJSON{}
 	usersResponse := []*model.UserDetailResponse{}
 	var (
@@ -46,6 +61,10 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*model.UserDetailRespons
       cust.nationality_id,
 			COALESCE(nat.nationality_name,''),
 			COALESCE( nat.nationality_code,''),
+			COALESCE(cust.cst_email,''),
+			COALESCE(cust.cst_phone,''),
+			cust.cst_address,
+			cust.cst_emergency_contacts,
 			COALESCE(
                 (
                     SELECT JSON_AGG(
@@ -53,7 +72,11 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*model.UserDetailRespons
 														'family_id', fl.fl_id::int,
 														'user_id', fl.cst_id::int,
                             'name', fl.fl_name,
-                            'dob', fl.fl_dob
+                            'dob', fl.fl_dob,
+                            'guardian', CASE
+                                WHEN fl.guardian_customer THEN JSON_BUILD_OBJECT('family_id', 0)
+                                WHEN fl.guardian_fl_id IS NOT NULL THEN JSON_BUILD_OBJECT('family_id', fl.guardian_fl_id)
+                            END
                         ) ORDER BY fl.fl_id ASC
                     ) 
                     FROM family_list fl 
@@ -64,9 +87,9 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*model.UserDetailRespons
             ) as families
 			from customer cust
 			left join nationality nat on cust.nationality_id = nat.nationality_id
-			`
+			` + where
 
-	rows, err := r.db.Query(ctx, queryStatment)
+	rows, err := r.db.Query(ctx, queryStatment, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +103,10 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*model.UserDetailRespons
 			&user.NationalityID,
 			&nationalityName,
 			&nationalityCode,
+			&user.Email,
+			&user.Phone,
+			&user.Address,
+			&user.EmergencyContacts,
 			&families.Families,
 		)
 
@@ -94,7 +121,7 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*model.UserDetailRespons
 		if len(families.Families) > 0 {
 			familyUnmarshallErr := json.Unmarshal(families.Families, &user.Families)
 			if familyUnmarshallErr != nil {
-				log.Error("GetAll - JSON Unmarshal error: ", familyUnmarshallErr)
+				logger.FromContext(ctx).Error("GetAll - JSON Unmarshal error: ", familyUnmarshallErr)
 				return nil, familyUnmarshallErr
 			}
 		}
@@ -115,26 +142,27 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 
 	defer tx.Rollback(ctx)
 
-	userQuery := `INSERT INTO customer (nationality_id, cst_name, cst_dob) 
-		VALUES ($1, $2, $3) 
+	userQuery := `INSERT INTO customer (nationality_id, cst_name, cst_dob, cst_email, cst_phone, cst_address, cst_emergency_contacts) 
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, COALESCE($7, '[]'::jsonb)) 
 		RETURNING customer_id
 	`
 
 	queryRowErr := tx.QueryRow(ctx, userQuery,
-		user.NationalityID, user.Name, user.Dob,
+		user.NationalityID, user.Name, user.Dob, user.Email, user.Phone, user.Address, user.EmergencyContacts,
 	).Scan(&customerID)
 
 	user.UserID = customerID
 	if queryRowErr != nil {
-		return queryRowErr
+		return emailConflict(queryRowErr, user.Email)
 	}
 
 	copyCount, copyCountErr := tx.CopyFrom(ctx,
 		pgx.Identifier{"family_list"},
-		[]string{"cst_id", "fl_name", "fl_dob"},
+		[]string{"cst_id", "fl_name", "fl_dob", "guardian_customer", "guardian_fl_id"},
 		pgx.CopyFromSlice(len(user.Families), func(i int) ([]any, error) {
 			family := user.Families[i]
-			return []any{user.UserID, family.Name, family.Dob}, nil
+			guardianCustomer, guardianFamilyID := guardianColumns(family)
+			return []any{user.UserID, family.Name, family.Dob, guardianCustomer, guardianFamilyID}, nil
 		}),
 	)
 
@@ -147,7 +175,7 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Infof("✅ COPY inserted %d family members for user %d", copyCount, user.UserID)
+	logger.FromContext(ctx).Infof("✅ COPY inserted %d family members for user %d", copyCount, user.UserID)
 
 	return nil
 }
@@ -166,6 +194,10 @@ func (r *UserRepository) GetUserDetail(ctx context.Context, userID int) (*model.
       cust.nationality_id,
 			COALESCE(nat.nationality_name,''),
 			COALESCE( nat.nationality_code,''),
+			COALESCE(cust.cst_email,''),
+			COALESCE(cust.cst_phone,''),
+			cust.cst_address,
+			cust.cst_emergency_contacts,
 			COALESCE(
                 (
                     SELECT JSON_AGG(
@@ -173,7 +205,11 @@ func (r *UserRepository) GetUserDetail(ctx context.Context, userID int) (*model.
 														'family_id', fl.fl_id::int,
 														'user_id', fl.cst_id::int,
                             'name', fl.fl_name,
-                            'dob', fl.fl_dob
+                            'dob', fl.fl_dob,
+                            'guardian', CASE
+                                WHEN fl.guardian_customer THEN JSON_BUILD_OBJECT('family_id', 0)
+                                WHEN fl.guardian_fl_id IS NOT NULL THEN JSON_BUILD_OBJECT('family_id', fl.guardian_fl_id)
+                            END
                         ) ORDER BY fl.fl_id ASC
                     ) 
                     FROM family_list fl 
@@ -194,18 +230,22 @@ func (r *UserRepository) GetUserDetail(ctx context.Context, userID int) (*model.
 		&UserDetailResponse.NationalityID,
 		&nationalityName,
 		&nationalityCode,
+		&UserDetailResponse.Email,
+		&UserDetailResponse.Phone,
+		&UserDetailResponse.Address,
+		&UserDetailResponse.EmergencyContacts,
 		&families.Families,
 	)
 
 	if err != nil {
-		log.Error("GetUserDetail - QueryRow error: ", err)
+		logger.FromContext(ctx).Error("GetUserDetail - QueryRow error: ", err)
 		return &UserDetailResponse, err
 	}
 
 	if len(families.Families) > 0 {
 		familyUnmarshallErr := json.Unmarshal(families.Families, &UserDetailResponse.Families)
 		if familyUnmarshallErr != nil {
-			log.Error("GetUserDetail - JSON Unmarshal error: ", familyUnmarshallErr)
+			logger.FromContext(ctx).Error("GetUserDetail - JSON Unmarshal error: ", familyUnmarshallErr)
 			return &UserDetailResponse, familyUnmarshallErr
 		}
 	}
@@ -217,6 +257,9 @@ func (r *UserRepository) GetUserDetail(ctx context.Context, userID int) (*model.
 	return &UserDetailResponse, nil
 }
 
+// Delete removes the customer with their draft bookings and family members.
+// Any other booking keeps the customer: that is reported as
+// model.ErrConflict.
 func (r *UserRepository) Delete(ctx context.Context, userID int) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -225,10 +268,15 @@ func (r *UserRepository) Delete(ctx context.Context, userID int) error {
 
 	defer tx.Rollback(ctx)
 
+	deleteDraftsQuery := `DELETE FROM booking WHERE cst_id = $1 AND status = 'draft'`
+	if _, err := tx.Exec(ctx, deleteDraftsQuery, userID); err != nil {
+		return bookedConflict(err, fmt.Sprintf("customer %d", userID))
+	}
+
 	deleteUserQuery := `DELETE FROM customer WHERE customer_id = $1`
 	_, deleteUserErr := tx.Exec(ctx, deleteUserQuery, userID)
 	if deleteUserErr != nil {
-		return deleteUserErr
+		return bookedConflict(deleteUserErr, fmt.Sprintf("customer %d", userID))
 
 	}
 
@@ -245,12 +293,33 @@ func (r *UserRepository) Delete(ctx context.Context, userID int) error {
 	return nil
 }
 
+// DeleteFamily removes the family member from draft bookings and then
+// deletes them. A family member on any other booking is kept and
+// model.ErrConflict returned.
 func (r *UserRepository) DeleteFamily(ctx context.Context, userID int, familyID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	leaveDraftsQuery := `
+		DELETE FROM booking_passenger p
+		USING booking b
+		WHERE b.booking_id = p.booking_id AND b.status = 'draft' AND p.cst_id = $1 AND p.fl_id = $2`
+	if _, err := tx.Exec(ctx, leaveDraftsQuery, userID, familyID); err != nil {
+		return bookedConflict(err, fmt.Sprintf("family member %d", familyID))
+	}
 
 	deleteFamilyQuery := `DELETE FROM family_list WHERE cst_id = $1 and fl_id = $2`
-	_, deleteFamilyErr := r.db.Exec(ctx, deleteFamilyQuery, userID, familyID)
+	_, deleteFamilyErr := tx.Exec(ctx, deleteFamilyQuery, userID, familyID)
 	if deleteFamilyErr != nil {
-		return deleteFamilyErr
+		return bookedConflict(deleteFamilyErr, fmt.Sprintf("family member %d", familyID))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -270,17 +339,18 @@ func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	defer tx.Rollback(ctx)
 
 	userQuery := `UPDATE customer 
-    SET nationality_id = $1, cst_name = $2, cst_dob = $3 , updated_at = CURRENT_TIMESTAMP
+    SET nationality_id = $1, cst_name = $2, cst_dob = $3, cst_email = NULLIF($5, ''), cst_phone = NULLIF($6, ''),
+        cst_address = $7, cst_emergency_contacts = COALESCE($8, '[]'::jsonb), updated_at = CURRENT_TIMESTAMP
     WHERE customer_id = $4
     RETURNING customer_id, updated_at`
 
 	queryRowErr := tx.QueryRow(ctx, userQuery,
-		user.NationalityID, user.Name, user.Dob, user.UserID,
+		user.NationalityID, user.Name, user.Dob, user.UserID, user.Email, user.Phone, user.Address, user.EmergencyContacts,
 	).Scan(&customerID, &updatedAt)
 
 	user.UserID = customerID
 	if queryRowErr != nil {
-		return queryRowErr
+		return emailConflict(queryRowErr, user.Email)
 	}
 
 	if err = r.upsertFamilyMembers(ctx, tx, user.Families); err != nil {
@@ -303,17 +373,20 @@ func (r *UserRepository) upsertFamilyMembers(ctx context.Context, tx pgx.Tx, fam
 	batch := &pgx.Batch{}
 	for _, family := range families {
 		query := `
-		INSERT INTO family_list (fl_id,cst_id, fl_name, fl_dob) 
+		INSERT INTO family_list (fl_id,cst_id, fl_name, fl_dob, guardian_customer, guardian_fl_id) 
 		VALUES ( 
 			CASE WHEN $1 = 0 THEN nextval('family_list_fl_id_seq') ELSE $1 END,
-		$2, $3, $4)
+		$2, $3, $4, $5, $6)
 		ON CONFLICT (fl_id) 
 		DO UPDATE SET 
 				cst_id = EXCLUDED.cst_id,
 				fl_name = EXCLUDED.fl_name,
-				fl_dob = EXCLUDED.fl_dob`
+				fl_dob = EXCLUDED.fl_dob,
+				guardian_customer = EXCLUDED.guardian_customer,
+				guardian_fl_id = EXCLUDED.guardian_fl_id`
 
-		batch.Queue(query, family.FamilyID, family.UserID, family.Name, family.Dob)
+		guardianCustomer, guardianFamilyID := guardianColumns(family)
+		batch.Queue(query, family.FamilyID, family.UserID, family.Name, family.Dob, guardianCustomer, guardianFamilyID)
 	}
 
 	results := tx.SendBatch(ctx, batch)
@@ -326,7 +399,39 @@ func (r *UserRepository) upsertFamilyMembers(ctx context.Context, tx pgx.Tx, fam
 		}
 	}
 
-	log.Infof("✅ upsert user family successfully: %d family members processed", len(families))
+	logger.FromContext(ctx).Infof("✅ upsert user family successfully: %d family members processed", len(families))
 
 	return results.Close()
 }
+
+// guardianColumns splits a family member's guardian into guardian_customer
+// and guardian_fl_id.
+func guardianColumns(family model.Family) (bool, *int) {
+	if family.Guardian == nil {
+		return false, nil
+	}
+	if family.Guardian.FamilyID == 0 {
+		return true, nil
+	}
+	guardianFamilyID := family.Guardian.FamilyID
+	return false, &guardianFamilyID
+}
+
+// bookedConflict reports a delete refused by a restricting foreign key, i.e.
+// because bookings or payments still refer to what is being deleted.
+func bookedConflict(err error, what string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return fmt.Errorf("%s has bookings and cannot be deleted: %w", what, model.ErrConflict)
+	}
+	return err
+}
+
+// emailConflict reports a duplicate email as model.ErrConflict.
+func emailConflict(err error, email string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "customer_email_key" {
+		return fmt.Errorf("email %s is already used by another customer: %w", email, model.ErrConflict)
+	}
+	return err
+}
@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// userRepositoryFixture is a fresh, empty repository plus a way to seed the
+// nationality table, which IUserRepository has no method for.
+type userRepositoryFixture struct {
+	repo           IUserRepository
+	addNationality func(t *testing.T, name, code string) int
+}
+
+// runUserRepositoryConformance checks the behaviour every IUserRepository
+// implementation must share.
+func runUserRepositoryConformance(t *testing.T, newFixture func(t *testing.T) userRepositoryFixture) {
+	ctx := context.Background()
+
+	t.Run("create assigns ids and detail joins nationality and families", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Indonesia", "ID")
+
+		user := &model.User{
+			Name:          "Budi Santoso",
+			Dob:           "1990-05-15",
+			NationalityID: nationalityID,
+			Families: []model.Family{
+				{Name: "Siti Aminah", Dob: "1992-01-01", UserID: 999, FamilyID: 999},
+				{Name: "Andi Santoso", Dob: "2015-07-20"},
+			},
+		}
+		if err := f.repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if user.UserID == 0 {
+			t.Fatal("Create did not set UserID")
+		}
+
+		detail, err := f.repo.GetUserDetail(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("GetUserDetail: %v", err)
+		}
+		if detail.Name != user.Name || detail.Dob != user.Dob || detail.NationalityID != nationalityID {
+			t.Fatalf("unexpected customer: %+v", detail)
+		}
+		want := model.Nationality{NationalityID: nationalityID, NationalityName: "Indonesia", NationalityCode: "ID"}
+		if detail.Nationality != want {
+			t.Fatalf("nationality = %+v, want %+v", detail.Nationality, want)
+		}
+		if len(detail.Families) != 2 {
+			t.Fatalf("families = %+v, want 2", detail.Families)
+		}
+		if detail.Families[0].Name != "Siti Aminah" || detail.Families[1].Name != "Andi Santoso" {
+			t.Fatalf("families not ordered by id: %+v", detail.Families)
+		}
+		for _, family := range detail.Families {
+			if family.UserID != user.UserID || family.FamilyID == 0 || family.FamilyID == 999 {
+				t.Fatalf("family ids not assigned by the repository: %+v", family)
+			}
+		}
+	})
+
+	t.Run("unknown nationality is left joined", func(t *testing.T) {
+		f := newFixture(t)
+
+		user := &model.User{Name: "No Nation", Dob: "1980-01-01", NationalityID: 4242}
+		if err := f.repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		detail, err := f.repo.GetUserDetail(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("GetUserDetail: %v", err)
+		}
+		want := model.Nationality{NationalityID: 4242}
+		if detail.Nationality != want {
+			t.Fatalf("nationality = %+v, want %+v", detail.Nationality, want)
+		}
+		if detail.Families == nil || len(detail.Families) != 0 {
+			t.Fatalf("families = %#v, want empty non-nil slice", detail.Families)
+		}
+	})
+
+	t.Run("detail of a missing customer is ErrNoRows", func(t *testing.T) {
+		f := newFixture(t)
+
+		detail, err := f.repo.GetUserDetail(ctx, 12345)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			t.Fatalf("err = %v, want pgx.ErrNoRows", err)
+		}
+		if detail == nil {
+			t.Fatal("detail must not be nil")
+		}
+	})
+
+	t.Run("get all returns every customer", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Singapore", "SG")
+
+		empty, err := f.repo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetAll on empty store = %#v, want empty non-nil slice", empty)
+		}
+
+		for _, name := range []string{"First Customer", "Second Customer"} {
+			user := &model.User{Name: name, Dob: "1991-02-03", NationalityID: nationalityID,
+				Families: []model.Family{{Name: name + " Jr", Dob: "2010-01-01"}}}
+			if err := f.repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		users, err := f.repo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+		if len(users) != 2 || users[0].Name != "First Customer" || users[1].Name != "Second Customer" {
+			t.Fatalf("unexpected users: %+v", users)
+		}
+		for _, user := range users {
+			if len(user.Families) != 1 || user.Nationality.NationalityCode != "SG" {
+				t.Fatalf("unexpected user detail: %+v", user)
+			}
+		}
+	})
+
+	t.Run("update changes the customer and upserts families", func(t *testing.T) {
+		f := newFixture(t)
+		first := f.addNationality(t, "Indonesia", "ID")
+		second := f.addNationality(t, "Malaysia", "MY")
+
+		user := &model.User{Name: "Budi Santoso", Dob: "1990-05-15", NationalityID: first,
+			Families: []model.Family{{Name: "Siti Aminah", Dob: "1992-01-01"}}}
+		if err := f.repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		before, _ := f.repo.GetUserDetail(ctx, user.UserID)
+		existing := before.Families[0]
+
+		update := &model.User{
+			UserID:        user.UserID,
+			Name:          "Budi Updated",
+			Dob:           "1990-05-16",
+			NationalityID: second,
+			Families: []model.Family{
+				{FamilyID: existing.FamilyID, UserID: user.UserID, Name: "Siti Renamed", Dob: "1992-01-02"},
+				{FamilyID: 0, UserID: user.UserID, Name: "New Member", Dob: "2020-03-04"},
+			},
+		}
+		if err := f.repo.Update(ctx, update); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if update.UserID != user.UserID {
+			t.Fatalf("Update changed UserID to %d", update.UserID)
+		}
+
+		after, err := f.repo.GetUserDetail(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("GetUserDetail: %v", err)
+		}
+		if after.Name != "Budi Updated" || after.Dob != "1990-05-16" || after.Nationality.NationalityCode != "MY" {
+			t.Fatalf("customer not updated: %+v", after)
+		}
+		if len(after.Families) != 2 {
+			t.Fatalf("families = %+v, want 2", after.Families)
+		}
+		if after.Families[0].FamilyID != existing.FamilyID || after.Families[0].Name != "Siti Renamed" {
+			t.Fatalf("existing family not updated in place: %+v", after.Families[0])
+		}
+		if after.Families[1].FamilyID <= existing.FamilyID || after.Families[1].Name != "New Member" {
+			t.Fatalf("new family not appended: %+v", after.Families[1])
+		}
+	})
+
+	t.Run("contact details round trip and emails are unique", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Indonesia", "ID")
+
+		address := &model.Address{Line1: "Jl. Sudirman 1", City: "Jakarta", PostalCode: "10220", CountryCode: "ID"}
+		user := &model.User{Name: "Contact Customer", Dob: "1990-01-01", NationalityID: nationalityID,
+			Email: "Contact@Example.com", Phone: "+6281234567890", Address: address}
+		if err := f.repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		detail, err := f.repo.GetUserDetail(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("GetUserDetail: %v", err)
+		}
+		if detail.Email != user.Email || detail.Phone != user.Phone || detail.Address == nil || *detail.Address != *address {
+			t.Fatalf("contact details = %q %q %+v", detail.Email, detail.Phone, detail.Address)
+		}
+
+		// customers without email never conflict
+		for _, name := range []string{"Silent Customer", "Quiet Customer"} {
+			if err := f.repo.Create(ctx, &model.User{Name: name, Dob: "1990-01-01", NationalityID: nationalityID}); err != nil {
+				t.Fatalf("Create without email: %v", err)
+			}
+		}
+
+		duplicate := &model.User{Name: "Duplicate Customer", Dob: "1990-01-01", NationalityID: nationalityID, Email: "contact@EXAMPLE.com"}
+		if err := f.repo.Create(ctx, duplicate); !errors.Is(err, model.ErrConflict) {
+			t.Fatalf("Create with a used email: err = %v, want model.ErrConflict", err)
+		}
+
+		other := &model.User{Name: "Other Customer", Dob: "1990-01-01", NationalityID: nationalityID, Email: "other@example.com"}
+		if err := f.repo.Create(ctx, other); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		other.Email = "CONTACT@example.com"
+		if err := f.repo.Update(ctx, other); !errors.Is(err, model.ErrConflict) {
+			t.Fatalf("Update to a used email: err = %v, want model.ErrConflict", err)
+		}
+
+		// keeping one's own email and clearing the address is fine
+		user.Address = nil
+		if err := f.repo.Update(ctx, user); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if detail, _ = f.repo.GetUserDetail(ctx, user.UserID); detail.Address != nil || detail.Email != user.Email {
+			t.Fatalf("after update: %+v", detail)
+		}
+	})
+
+	t.Run("search matches email regardless of case and phone", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Indonesia", "ID")
+
+		users := []*model.User{
+			{Name: "Searched Customer", Dob: "1990-01-01", NationalityID: nationalityID, Email: "found@example.com", Phone: "+6281111111111"},
+			{Name: "Sharing Customer", Dob: "1990-01-01", NationalityID: nationalityID, Email: "share@example.com", Phone: "+6281111111111"},
+			{Name: "Unrelated Customer", Dob: "1990-01-01", NationalityID: nationalityID},
+		}
+		for _, user := range users {
+			if err := f.repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		tests := []struct {
+			search model.UserSearch
+			want   []int
+		}{
+			{search: model.UserSearch{Email: "FOUND@example.com"}, want: []int{users[0].UserID}},
+			{search: model.UserSearch{Phone: "+6281111111111"}, want: []int{users[0].UserID, users[1].UserID}},
+			{search: model.UserSearch{Email: "share@example.com", Phone: "+6281111111111"}, want: []int{users[1].UserID}},
+			{search: model.UserSearch{Email: "nobody@example.com"}, want: []int{}},
+		}
+		for _, tt := range tests {
+			found, err := f.repo.Search(ctx, tt.search)
+			if err != nil {
+				t.Fatalf("Search(%+v): %v", tt.search, err)
+			}
+			got := []int{}
+			for _, user := range found {
+				got = append(got, user.UserID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%+v) = %v, want %v", tt.search, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search(%+v) = %v, want %v", tt.search, got, tt.want)
+				}
+			}
+		}
+	})
+
+	t.Run("guardians and emergency contacts round trip", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Indonesia", "ID")
+
+		user := &model.User{Name: "Guardian Customer", Dob: "1980-01-01", NationalityID: nationalityID,
+			Families: []model.Family{
+				{Name: "Adult Sibling", Dob: "1985-01-01"},
+				{Name: "Minor Child", Dob: "2015-01-01", Guardian: &model.Guardian{FamilyID: 0}},
+			}}
+		if err := f.repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		detail, err := f.repo.GetUserDetail(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("GetUserDetail: %v", err)
+		}
+		if detail.EmergencyContacts == nil || len(detail.EmergencyContacts) != 0 {
+			t.Fatalf("emergency contacts = %#v, want an empty list", detail.EmergencyContacts)
+		}
+		adult, minor := detail.Families[0], detail.Families[1]
+		if adult.Guardian != nil || minor.Guardian == nil || minor.Guardian.FamilyID != 0 {
+			t.Fatalf("guardians = %+v, %+v", adult.Guardian, minor.Guardian)
+		}
+
+		contact := model.EmergencyContact{Name: "Siti Aminah", Relationship: "sister", Phone: "+6281234567890"}
+		user.EmergencyContacts = []model.EmergencyContact{contact}
+		minor.Guardian = &model.Guardian{FamilyID: adult.FamilyID}
+		user.Families = []model.Family{minor}
+		if err := f.repo.Update(ctx, user); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		detail, _ = f.repo.GetUserDetail(ctx, user.UserID)
+		if len(detail.EmergencyContacts) != 1 || detail.EmergencyContacts[0] != contact {
+			t.Fatalf("emergency contacts = %+v", detail.EmergencyContacts)
+		}
+		if guardian := detail.Families[1].Guardian; guardian == nil || guardian.FamilyID != adult.FamilyID {
+			t.Fatalf("guardian after update = %+v", guardian)
+		}
+
+		// removing the guardian leaves the minor without one
+		if err := f.repo.DeleteFamily(ctx, user.UserID, adult.FamilyID); err != nil {
+			t.Fatalf("DeleteFamily: %v", err)
+		}
+		detail, _ = f.repo.GetUserDetail(ctx, user.UserID)
+		if len(detail.Families) != 1 || detail.Families[0].Guardian != nil {
+			t.Fatalf("families after deleting the guardian = %+v", detail.Families)
+		}
+	})
+
+	t.Run("update of a missing customer is ErrNoRows", func(t *testing.T) {
+		f := newFixture(t)
+
+		update := &model.User{UserID: 777, Name: "Ghost Customer", Dob: "1990-01-01", NationalityID: 1}
+		err := f.repo.Update(ctx, update)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			t.Fatalf("err = %v, want pgx.ErrNoRows", err)
+		}
+	})
+
+	t.Run("delete family only removes a member of that customer", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Indonesia", "ID")
+
+		owner := &model.User{Name: "Owner Customer", Dob: "1990-01-01", NationalityID: nationalityID,
+			Families: []model.Family{{Name: "Owner Child", Dob: "2012-01-01"}, {Name: "Owner Spouse", Dob: "1991-01-01"}}}
+		other := &model.User{Name: "Other Customer", Dob: "1990-01-01", NationalityID: nationalityID}
+		for _, user := range []*model.User{owner, other} {
+			if err := f.repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		detail, _ := f.repo.GetUserDetail(ctx, owner.UserID)
+		child := detail.Families[0]
+
+		if err := f.repo.DeleteFamily(ctx, other.UserID, child.FamilyID); err != nil {
+			t.Fatalf("DeleteFamily with wrong owner: %v", err)
+		}
+		if detail, _ = f.repo.GetUserDetail(ctx, owner.UserID); len(detail.Families) != 2 {
+			t.Fatalf("family deleted through another customer: %+v", detail.Families)
+		}
+
+		if err := f.repo.DeleteFamily(ctx, owner.UserID, child.FamilyID); err != nil {
+			t.Fatalf("DeleteFamily: %v", err)
+		}
+		detail, _ = f.repo.GetUserDetail(ctx, owner.UserID)
+		if len(detail.Families) != 1 || detail.Families[0].Name != "Owner Spouse" {
+			t.Fatalf("families after delete = %+v", detail.Families)
+		}
+	})
+
+	t.Run("delete removes the customer and its families", func(t *testing.T) {
+		f := newFixture(t)
+		nationalityID := f.addNationality(t, "Indonesia", "ID")
+
+		user := &model.User{Name: "Doomed Customer", Dob: "1990-01-01", NationalityID: nationalityID,
+			Families: []model.Family{{Name: "Doomed Child", Dob: "2012-01-01"}}}
+		if err := f.repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if err := f.repo.Delete(ctx, user.UserID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := f.repo.GetUserDetail(ctx, user.UserID); !errors.Is(err, pgx.ErrNoRows) {
+			t.Fatalf("GetUserDetail after delete: err = %v, want pgx.ErrNoRows", err)
+		}
+		if err := f.repo.Delete(ctx, user.UserID); err != nil {
+			t.Fatalf("Delete of a missing customer: %v", err)
+		}
+
+		// a new customer must not inherit the deleted families
+		next := &model.User{Name: "Fresh Customer", Dob: "1990-01-01", NationalityID: nationalityID}
+		if err := f.repo.Create(ctx, next); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if detail, _ := f.repo.GetUserDetail(ctx, next.UserID); len(detail.Families) != 0 {
+			t.Fatalf("families = %+v, want none", detail.Families)
+		}
+	})
+}
+
+func TestInMemoryUserRepositoryConformance(t *testing.T) {
+	runUserRepositoryConformance(t, func(t *testing.T) userRepositoryFixture {
+		repo := NewInMemoryUserRepository()
+		return userRepositoryFixture{
+			repo: repo,
+			addNationality: func(t *testing.T, name, code string) int {
+				return repo.AddNationality(name, code)
+			},
+		}
+	})
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"booking_togo/internal/model"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// InMemoryUserRepository is a thread-safe IUserRepository for tests and
+// demos. It mirrors UserRepository, quirks included: customer and family IDs
+// come from sequences, nationalities are left-joined, families are ordered by
+// ID, a missing customer is reported as pgx.ErrNoRows and family upserts are
+// keyed on the family ID alone.
+type InMemoryUserRepository struct {
+	mu sync.RWMutex
+
+	customers     map[int]model.User
+	families      map[int]model.Family
+	nationalities map[int]model.Nationality
+
+	customerSeq    int
+	familySeq      int
+	nationalitySeq int
+}
+
+func NewInMemoryUserRepository() *InMemoryUserRepository {
+	return &InMemoryUserRepository{
+		customers:     map[int]model.User{},
+		families:      map[int]model.Family{},
+		nationalities: map[int]model.Nationality{},
+	}
+}
+
+// AddNationality stores a nationality and returns its generated ID.
+func (r *InMemoryUserRepository) AddNationality(name, code string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nationalitySeq++
+	r.nationalities[r.nationalitySeq] = model.Nationality{
+		NationalityID:   r.nationalitySeq,
+		NationalityName: name,
+		NationalityCode: code,
+	}
+	return r.nationalitySeq
+}
+
+func (r *InMemoryUserRepository) GetAll(ctx context.Context) ([]*model.UserDetailResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.customers))
+	for id := range r.customers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	usersResponse := []*model.UserDetailResponse{}
+	for _, id := range ids {
+		usersResponse = append(usersResponse, r.detail(id))
+	}
+	return usersResponse, nil
+}
+
+func (r *InMemoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkEmail(0, user.Email); err != nil {
+		return err
+	}
+
+	r.customerSeq++
+	user.UserID = r.customerSeq
+	r.customers[user.UserID] = model.User{
+		UserID:        user.UserID,
+		Name:          user.Name,
+		Dob:           user.Dob,
+		NationalityID: user.NationalityID,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Address:       copyAddress(user.Address),
+
+		EmergencyContacts: copyContacts(user.EmergencyContacts),
+	}
+
+	// like the COPY, only name, dob and guardian are taken from the payload
+	for _, family := range user.Families {
+		r.familySeq++
+		r.families[r.familySeq] = model.Family{
+			FamilyID: r.familySeq,
+			UserID:   user.UserID,
+			Name:     family.Name,
+			Dob:      family.Dob,
+			Guardian: copyGuardian(family.Guardian),
+		}
+	}
+
+	return nil
+}
+
+func (r *InMemoryUserRepository) GetUserDetail(ctx context.Context, userID int) (*model.UserDetailResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.customers[userID]; !ok {
+		return &model.UserDetailResponse{}, pgx.ErrNoRows
+	}
+	return r.detail(userID), nil
+}
+
+func (r *InMemoryUserRepository) Delete(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.customers, userID)
+	for id, family := range r.families {
+		if family.UserID == userID {
+			delete(r.families, id)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryUserRepository) DeleteFamily(ctx context.Context, userID int, familyID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if family, ok := r.families[familyID]; ok && family.UserID == userID {
+		delete(r.families, familyID)
+		// ON DELETE SET NULL on guardian_fl_id
+		for id, ward := range r.families {
+			if ward.Guardian != nil && ward.Guardian.FamilyID == familyID {
+				ward.Guardian = nil
+				r.families[id] = ward
+			}
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryUserRepository) Update(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	customer, ok := r.customers[user.UserID]
+	if !ok {
+		// UPDATE ... RETURNING scans nothing into the zero customer ID
+		user.UserID = 0
+		return pgx.ErrNoRows
+	}
+
+	if err := r.checkEmail(user.UserID, user.Email); err != nil {
+		return err
+	}
+
+	customer.Name = user.Name
+	customer.Dob = user.Dob
+	customer.NationalityID = user.NationalityID
+	customer.Email = user.Email
+	customer.Phone = user.Phone
+	customer.Address = copyAddress(user.Address)
+	customer.EmergencyContacts = copyContacts(user.EmergencyContacts)
+	r.customers[user.UserID] = customer
+
+	for _, family := range user.Families {
+		id := family.FamilyID
+		if id == 0 {
+			r.familySeq++
+			id = r.familySeq
+		}
+		// ON CONFLICT (fl_id) DO UPDATE, including cst_id from the payload
+		r.families[id] = model.Family{
+			FamilyID: id,
+			UserID:   family.UserID,
+			Name:     family.Name,
+			Dob:      family.Dob,
+			Guardian: copyGuardian(family.Guardian),
+		}
+	}
+
+	return nil
+}
+
+// detail builds the joined response; callers must hold the lock.
+func (r *InMemoryUserRepository) detail(userID int) *model.UserDetailResponse {
+	customer := r.customers[userID]
+	nationality := r.nationalities[customer.NationalityID]
+	nationality.NationalityID = customer.NationalityID
+
+	families := []model.Family{}
+	for _, family := range r.families {
+		if family.UserID == userID {
+			family.Guardian = copyGuardian(family.Guardian)
+			families = append(families, family)
+		}
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].FamilyID < families[j].FamilyID })
+
+	return &model.UserDetailResponse{
+		UserID:        customer.UserID,
+		Name:          customer.Name,
+		Dob:           customer.Dob,
+		NationalityID: customer.NationalityID,
+		Nationality:   nationality,
+		Email:         customer.Email,
+		Phone:         customer.Phone,
+		Address:       copyAddress(customer.Address),
+		Families:      families,
+
+		EmergencyContacts: copyContacts(customer.EmergencyContacts),
+	}
+}
+
+func (r *InMemoryUserRepository) Search(ctx context.Context, search model.UserSearch) ([]*model.UserDetailResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := []int{}
+	for id, customer := range r.customers {
+		if (search.Email == "" || strings.EqualFold(customer.Email, search.Email)) &&
+			(search.Phone == "" || customer.Phone == search.Phone) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	usersResponse := []*model.UserDetailResponse{}
+	for _, id := range ids {
+		usersResponse = append(usersResponse, r.detail(id))
+	}
+	return usersResponse, nil
+}
+
+// checkEmail mirrors the unique index on lower(cst_email); callers must
+// hold the lock.
+func (r *InMemoryUserRepository) checkEmail(userID int, email string) error {
+	if email == "" {
+		return nil
+	}
+	for id, customer := range r.customers {
+		if id != userID && strings.EqualFold(customer.Email, email) {
+			return fmt.Errorf("email %s is already used by another customer: %w", email, model.ErrConflict)
+		}
+	}
+	return nil
+}
+
+func copyAddress(address *model.Address) *model.Address {
+	if address == nil {
+		return nil
+	}
+	copied := *address
+	return &copied
+}
+
+// copyContacts never returns nil, as cst_emergency_contacts defaults to an
+// empty array.
+func copyContacts(contacts []model.EmergencyContact) []model.EmergencyContact {
+	return append([]model.EmergencyContact{}, contacts...)
+}
+
+func copyGuardian(guardian *model.Guardian) *model.Guardian {
+	if guardian == nil {
+		return nil
+	}
+	copied := *guardian
+	return &copied
+}
+
+// Nationalities returns an INationalityRepository over the nationalities
+// added with AddNationality.
+func (r *InMemoryUserRepository) Nationalities() INationalityRepository {
+	return inMemoryNationalities{r}
+}
+
+type inMemoryNationalities struct {
+	r *InMemoryUserRepository
+}
+
+func (n inMemoryNationalities) GetAll(ctx context.Context) ([]model.Nationality, error) {
+	n.r.mu.RLock()
+	defer n.r.mu.RUnlock()
+
+	nationalities := []model.Nationality{}
+	for _, nationality := range n.r.nationalities {
+		nationalities = append(nationalities, nationality)
+	}
+	sort.Slice(nationalities, func(i, j int) bool {
+		return nationalities[i].NationalityCode < nationalities[j].NationalityCode
+	})
+	return nationalities, nil
+}
+
+func (n inMemoryNationalities) GetByID(ctx context.Context, nationalityID int) (*model.Nationality, error) {
+	n.r.mu.RLock()
+	defer n.r.mu.RUnlock()
+
+	nationality, ok := n.r.nationalities[nationalityID]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &nationality, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"booking_togo/internal/migration"
+	"booking_togo/internal/pgtest"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TestUserRepositoryConformance runs the shared suite against a fresh
+// database, on a throwaway Postgres or on the server TEST_DATABASE_URL names,
+// and is skipped when neither is available.
+func TestUserRepositoryConformance(t *testing.T) {
+	server, err := pgtest.Start()
+	if errors.Is(err, pgtest.ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	databaseURL, err := server.CreateDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := migration.Up(ctx, pool); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	runUserRepositoryConformance(t, func(t *testing.T) userRepositoryFixture {
+		if _, err := pool.Exec(ctx, `TRUNCATE customer, family_list, nationality RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+
+		return userRepositoryFixture{
+			repo: NewUserRepository(pool),
+			addNationality: func(t *testing.T, name, code string) int {
+				var id int
+				err := pool.QueryRow(ctx,
+					`INSERT INTO nationality (nationality_name, nationality_code) VALUES ($1, $2) RETURNING nationality_id`,
+					name, code,
+				).Scan(&id)
+				if err != nil {
+					t.Fatalf("insert nationality: %v", err)
+				}
+				return id
+			},
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Package secretbox encrypts individual column values, such as travel
+// document numbers, before they are stored.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of a key: AES-256.
+const KeySize = 32
+
+// ErrDecrypt is returned for ciphertext that was tampered with or sealed
+// with another key.
+var ErrDecrypt = errors.New("secretbox: message authentication failed")
+
+// Box seals values with AES-256-GCM. A sealed value is the random nonce
+// followed by the ciphertext and its tag, so equal values never produce
+// equal ciphertext.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New builds a Box from a KeySize-byte key.
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secretbox: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// NewFromBase64 builds a Box from a base64 encoded key, or from a random key
+// when encoded is empty. Values sealed with a random key cannot be opened
+// once the process exits.
+func NewFromBase64(encoded string) (*Box, error) {
+	if encoded == "" {
+		key := make([]byte, KeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return New(key)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox: key is not base64: %w", err)
+	}
+	return New(key)
+}
+
+// Seal encrypts plaintext. additionalData, which may be nil, is
+// authenticated but not stored: Open must be given the same.
+func (b *Box) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize(), b.aead.NonceSize()+len(plaintext)+b.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a value sealed by Seal with the same key and additional
+// data, or fails with ErrDecrypt.
+func (b *Box) Open(sealed, additionalData []byte) ([]byte, error) {
+	size := b.aead.NonceSize()
+	if len(sealed) < size+b.aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := b.aead.Open(nil, sealed[:size], sealed[size:], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,59 @@
+package secretbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	box, err := NewFromBase64(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := box.Seal([]byte("A1234567"), []byte("passport"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("A1234567")) {
+		t.Fatal("sealed value contains the plaintext")
+	}
+	again, _ := box.Seal([]byte("A1234567"), []byte("passport"))
+	if bytes.Equal(sealed, again) {
+		t.Fatal("sealing twice produced the same ciphertext")
+	}
+
+	plaintext, err := box.Open(sealed, []byte("passport"))
+	if err != nil || string(plaintext) != "A1234567" {
+		t.Fatalf("Open = %q, %v", plaintext, err)
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	other, _ := New(bytes.Repeat([]byte{8}, KeySize))
+	for name, open := range map[string]func() ([]byte, error){
+		"tampered":        func() ([]byte, error) { return box.Open(tampered, []byte("passport")) },
+		"other key":       func() ([]byte, error) { return other.Open(sealed, []byte("passport")) },
+		"other data":      func() ([]byte, error) { return box.Open(sealed, []byte("visa")) },
+		"truncated value": func() ([]byte, error) { return box.Open(sealed[:4], []byte("passport")) },
+	} {
+		if _, err := open(); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: err = %v, want ErrDecrypt", name, err)
+		}
+	}
+}
+
+func TestNewRejectsBadKeys(t *testing.T) {
+	if _, err := New(make([]byte, 16)); err == nil {
+		t.Error("16 byte key accepted")
+	}
+	if _, err := NewFromBase64("not base64!"); err == nil {
+		t.Error("invalid base64 accepted")
+	}
+	if _, err := NewFromBase64(""); err != nil {
+		t.Errorf("random key: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package seed
+
+import "booking_togo/internal/model"
+
+// Nationalities is the ISO 3166-1 country list keyed by alpha-2 code. Names
+// use the common short form so they fit nationality_name varchar(50).
+var Nationalities = []model.Nationality{
+	{NationalityCode: "AD", NationalityName: "Andorra"},
+	{NationalityCode: "AE", NationalityName: "United Arab Emirates"},
+	{NationalityCode: "AF", NationalityName: "Afghanistan"},
+	{NationalityCode: "AG", NationalityName: "Antigua and Barbuda"},
+	{NationalityCode: "AI", NationalityName: "Anguilla"},
+	{NationalityCode: "AL", NationalityName: "Albania"},
+	{NationalityCode: "AM", NationalityName: "Armenia"},
+	{NationalityCode: "AO", NationalityName: "Angola"},
+	{NationalityCode: "AQ", NationalityName: "Antarctica"},
+	{NationalityCode: "AR", NationalityName: "Argentina"},
+	{NationalityCode: "AS", NationalityName: "American Samoa"},
+	{NationalityCode: "AT", NationalityName: "Austria"},
+	{NationalityCode: "AU", NationalityName: "Australia"},
+	{NationalityCode: "AW", NationalityName: "Aruba"},
+	{NationalityCode: "AX", NationalityName: "Åland Islands"},
+	{NationalityCode: "AZ", NationalityName: "Azerbaijan"},
+	{NationalityCode: "BA", NationalityName: "Bosnia and Herzegovina"},
+	{NationalityCode: "BB", NationalityName: "Barbados"},
+	{NationalityCode: "BD", NationalityName: "Bangladesh"},
+	{NationalityCode: "BE", NationalityName: "Belgium"},
+	{NationalityCode: "BF", NationalityName: "Burkina Faso"},
+	{NationalityCode: "BG", NationalityName: "Bulgaria"},
+	{NationalityCode: "BH", NationalityName: "Bahrain"},
+	{NationalityCode: "BI", NationalityName: "Burundi"},
+	{NationalityCode: "BJ", NationalityName: "Benin"},
+	{NationalityCode: "BL", NationalityName: "Saint Barthélemy"},
+	{NationalityCode: "BM", NationalityName: "Bermuda"},
+	{NationalityCode: "BN", NationalityName: "Brunei Darussalam"},
+	{NationalityCode: "BO", NationalityName: "Bolivia"},
+	{NationalityCode: "BQ", NationalityName: "Bonaire, Sint Eustatius and Saba"},
+	{NationalityCode: "BR", NationalityName: "Brazil"},
+	{NationalityCode: "BS", NationalityName: "Bahamas"},
+	{NationalityCode: "BT", NationalityName: "Bhutan"},
+	{NationalityCode: "BV", NationalityName: "Bouvet Island"},
+	{NationalityCode: "BW", NationalityName: "Botswana"},
+	{NationalityCode: "BY", NationalityName: "Belarus"},
+	{NationalityCode: "BZ", NationalityName: "Belize"},
+	{NationalityCode: "CA", NationalityName: "Canada"},
+	{NationalityCode: "CC", NationalityName: "Cocos (Keeling) Islands"},
+	{NationalityCode: "CD", NationalityName: "Congo, Democratic Republic of the"},
+	{NationalityCode: "CF", NationalityName: "Central African Republic"},
+	{NationalityCode: "CG", NationalityName: "Congo"},
+	{NationalityCode: "CH", NationalityName: "Switzerland"},
+	{NationalityCode: "CI", NationalityName: "Côte d'Ivoire"},
+	{NationalityCode: "CK", NationalityName: "Cook Islands"},
+	{NationalityCode: "CL", NationalityName: "Chile"},
+	{NationalityCode: "CM", NationalityName: "Cameroon"},
+	{NationalityCode: "CN", NationalityName: "China"},
+	{NationalityCode: "CO", NationalityName: "Colombia"},
+	{NationalityCode: "CR", NationalityName: "Costa Rica"},
+	{NationalityCode: "CU", NationalityName: "Cuba"},
+	{NationalityCode: "CV", NationalityName: "Cabo Verde"},
+	{NationalityCode: "CW", NationalityName: "Curaçao"},
+	{NationalityCode: "CX", NationalityName: "Christmas Island"},
+	{NationalityCode: "CY", NationalityName: "Cyprus"},
+	{NationalityCode: "CZ", NationalityName: "Czechia"},
+	{NationalityCode: "DE", NationalityName: "Germany"},
+	{NationalityCode: "DJ", NationalityName: "Djibouti"},
+	{NationalityCode: "DK", NationalityName: "Denmark"},
+	{NationalityCode: "DM", NationalityName: "Dominica"},
+	{NationalityCode: "DO", NationalityName: "Dominican Republic"},
+	{NationalityCode: "DZ", NationalityName: "Algeria"},
+	{NationalityCode: "EC", NationalityName: "Ecuador"},
+	{NationalityCode: "EE", NationalityName: "Estonia"},
+	{NationalityCode: "EG", NationalityName: "Egypt"},
+	{NationalityCode: "EH", NationalityName: "Western Sahara"},
+	{NationalityCode: "ER", NationalityName: "Eritrea"},
+	{NationalityCode: "ES", NationalityName: "Spain"},
+	{NationalityCode: "ET", NationalityName: "Ethiopia"},
+	{NationalityCode: "FI", NationalityName: "Finland"},
+	{NationalityCode: "FJ", NationalityName: "Fiji"},
+	{NationalityCode: "FK", NationalityName: "Falkland Islands (Malvinas)"},
+	{NationalityCode: "FM", NationalityName: "Micronesia"},
+	{NationalityCode: "FO", NationalityName: "Faroe Islands"},
+	{NationalityCode: "FR", NationalityName: "France"},
+	{NationalityCode: "GA", NationalityName: "Gabon"},
+	{NationalityCode: "GB", NationalityName: "United Kingdom"},
+	{NationalityCode: "GD", NationalityName: "Grenada"},
+	{NationalityCode: "GE", NationalityName: "Georgia"},
+	{NationalityCode: "GF", NationalityName: "French Guiana"},
+	{NationalityCode: "GG", NationalityName: "Guernsey"},
+	{NationalityCode: "GH", NationalityName: "Ghana"},
+	{NationalityCode: "GI", NationalityName: "Gibraltar"},
+	{NationalityCode: "GL", NationalityName: "Greenland"},
+	{NationalityCode: "GM", NationalityName: "Gambia"},
+	{NationalityCode: "GN", NationalityName: "Guinea"},
+	{NationalityCode: "GP", NationalityName: "Guadeloupe"},
+	{NationalityCode: "GQ", NationalityName: "Equatorial Guinea"},
+	{NationalityCode: "GR", NationalityName: "Greece"},
+	{NationalityCode: "GS", NationalityName: "South Georgia and the South Sandwich Islands"},
+	{NationalityCode: "GT", NationalityName: "Guatemala"},
+	{NationalityCode: "GU", NationalityName: "Guam"},
+	{NationalityCode: "GW", NationalityName: "Guinea-Bissau"},
+	{NationalityCode: "GY", NationalityName: "Guyana"},
+	{NationalityCode: "HK", NationalityName: "Hong Kong"},
+	{NationalityCode: "HM", NationalityName: "Heard Island and McDonald Islands"},
+	{NationalityCode: "HN", NationalityName: "Honduras"},
+	{NationalityCode: "HR", NationalityName: "Croatia"},
+	{NationalityCode: "HT", NationalityName: "Haiti"},
+	{NationalityCode: "HU", NationalityName: "Hungary"},
+	{NationalityCode: "ID", NationalityName: "Indonesia"},
+	{NationalityCode: "IE", NationalityName: "Ireland"},
+	{NationalityCode: "IL", NationalityName: "Israel"},
+	{NationalityCode: "IM", NationalityName: "Isle of Man"},
+	{NationalityCode: "IN", NationalityName: "India"},
+	{NationalityCode: "IO", NationalityName: "British Indian Ocean Territory"},
+	{NationalityCode: "IQ", NationalityName: "Iraq"},
+	{NationalityCode: "IR", NationalityName: "Iran"},
+	{NationalityCode: "IS", NationalityName: "Iceland"},
+	{NationalityCode: "IT", NationalityName: "Italy"},
+	{NationalityCode: "JE", NationalityName: "Jersey"},
+	{NationalityCode: "JM", NationalityName: "Jamaica"},
+	{NationalityCode: "JO", NationalityName: "Jordan"},
+	{NationalityCode: "JP", NationalityName: "Japan"},
+	{NationalityCode: "KE", NationalityName: "Kenya"},
+	{NationalityCode: "KG", NationalityName: "Kyrgyzstan"},
+	{NationalityCode: "KH", NationalityName: "Cambodia"},
+	{NationalityCode: "KI", NationalityName: "Kiribati"},
+	{NationalityCode: "KM", NationalityName: "Comoros"},
+	{NationalityCode: "KN", NationalityName: "Saint Kitts and Nevis"},
+	{NationalityCode: "KP", NationalityName: "Korea, Democratic People's Republic of"},
+	{NationalityCode: "KR", NationalityName: "Korea, Republic of"},
+	{NationalityCode: "KW", NationalityName: "Kuwait"},
+	{NationalityCode: "KY", NationalityName: "Cayman Islands"},
+	{NationalityCode: "KZ", NationalityName: "Kazakhstan"},
+	{NationalityCode: "LA", NationalityName: "Lao People's Democratic Republic"},
+	{NationalityCode: "LB", NationalityName: "Lebanon"},
+	{NationalityCode: "LC", NationalityName: "Saint Lucia"},
+	{NationalityCode: "LI", NationalityName: "Liechtenstein"},
+	{NationalityCode: "LK", NationalityName: "Sri Lanka"},
+	{NationalityCode: "LR", NationalityName: "Liberia"},
+	{NationalityCode: "LS", NationalityName: "Lesotho"},
+	{NationalityCode: "LT", NationalityName: "Lithuania"},
+	{NationalityCode: "LU", NationalityName: "Luxembourg"},
+	{NationalityCode: "LV", NationalityName: "Latvia"},
+	{NationalityCode: "LY", NationalityName: "Libya"},
+	{NationalityCode: "MA", NationalityName: "Morocco"},
+	{NationalityCode: "MC", NationalityName: "Monaco"},
+	{NationalityCode: "MD", NationalityName: "Moldova"},
+	{NationalityCode: "ME", NationalityName: "Montenegro"},
+	{NationalityCode: "MF", NationalityName: "Saint Martin (French part)"},
+	{NationalityCode: "MG", NationalityName: "Madagascar"},
+	{NationalityCode: "MH", NationalityName: "Marshall Islands"},
+	{NationalityCode: "MK", NationalityName: "North Macedonia"},
+	{NationalityCode: "ML", NationalityName: "Mali"},
+	{NationalityCode: "MM", NationalityName: "Myanmar"},
+	{NationalityCode: "MN", NationalityName: "Mongolia"},
+	{NationalityCode: "MO", NationalityName: "Macao"},
+	{NationalityCode: "MP", NationalityName: "Northern Mariana Islands"},
+	{NationalityCode: "MQ", NationalityName: "Martinique"},
+	{NationalityCode: "MR", NationalityName: "Mauritania"},
+	{NationalityCode: "MS", NationalityName: "Montserrat"},
+	{NationalityCode: "MT", NationalityName: "Malta"},
+	{NationalityCode: "MU", NationalityName: "Mauritius"},
+	{NationalityCode: "MV", NationalityName: "Maldives"},
+	{NationalityCode: "MW", NationalityName: "Malawi"},
+	{NationalityCode: "MX", NationalityName: "Mexico"},
+	{NationalityCode: "MY", NationalityName: "Malaysia"},
+	{NationalityCode: "MZ", NationalityName: "Mozambique"},
+	{NationalityCode: "NA", NationalityName: "Namibia"},
+	{NationalityCode: "NC", NationalityName: "New Caledonia"},
+	{NationalityCode: "NE", NationalityName: "Niger"},
+	{NationalityCode: "NF", NationalityName: "Norfolk Island"},
+	{NationalityCode: "NG", NationalityName: "Nigeria"},
+	{NationalityCode: "NI", NationalityName: "Nicaragua"},
+	{NationalityCode: "NL", NationalityName: "Netherlands"},
+	{NationalityCode: "NO", NationalityName: "Norway"},
+	{NationalityCode: "NP", NationalityName: "Nepal"},
+	{NationalityCode: "NR", NationalityName: "Nauru"},
+	{NationalityCode: "NU", NationalityName: "Niue"},
+	{NationalityCode: "NZ", NationalityName: "New Zealand"},
+	{NationalityCode: "OM", NationalityName: "Oman"},
+	{NationalityCode: "PA", NationalityName: "Panama"},
+	{NationalityCode: "PE", NationalityName: "Peru"},
+	{NationalityCode: "PF", NationalityName: "French Polynesia"},
+	{NationalityCode: "PG", NationalityName: "Papua New Guinea"},
+	{NationalityCode: "PH", NationalityName: "Philippines"},
+	{NationalityCode: "PK", NationalityName: "Pakistan"},
+	{NationalityCode: "PL", NationalityName: "Poland"},
+	{NationalityCode: "PM", NationalityName: "Saint Pierre and Miquelon"},
+	{NationalityCode: "PN", NationalityName: "Pitcairn"},
+	{NationalityCode: "PR", NationalityName: "Puerto Rico"},
+	{NationalityCode: "PS", NationalityName: "Palestine, State of"},
+	{NationalityCode: "PT", NationalityName: "Portugal"},
+	{NationalityCode: "PW", NationalityName: "Palau"},
+	{NationalityCode: "PY", NationalityName: "Paraguay"},
+	{NationalityCode: "QA", NationalityName: "Qatar"},
+	{NationalityCode: "RE", NationalityName: "Réunion"},
+	{NationalityCode: "RO", NationalityName: "Romania"},
+	{NationalityCode: "RS", NationalityName: "Serbia"},
+	{NationalityCode: "RU", NationalityName: "Russian Federation"},
+	{NationalityCode: "RW", NationalityName: "Rwanda"},
+	{NationalityCode: "SA", NationalityName: "Saudi Arabia"},
+	{NationalityCode: "SB", NationalityName: "Solomon Islands"},
+	{NationalityCode: "SC", NationalityName: "Seychelles"},
+	{NationalityCode: "SD", NationalityName: "Sudan"},
+	{NationalityCode: "SE", NationalityName: "Sweden"},
+	{NationalityCode: "SG", NationalityName: "Singapore"},
+	{NationalityCode: "SH", NationalityName: "Saint Helena, Ascension and Tristan da Cunha"},
+	{NationalityCode: "SI", NationalityName: "Slovenia"},
+	{NationalityCode: "SJ", NationalityName: "Svalbard and Jan Mayen"},
+	{NationalityCode: "SK", NationalityName: "Slovakia"},
+	{NationalityCode: "SL", NationalityName: "Sierra Leone"},
+	{NationalityCode: "SM", NationalityName: "San Marino"},
+	{NationalityCode: "SN", NationalityName: "Senegal"},
+	{NationalityCode: "SO", NationalityName: "Somalia"},
+	{NationalityCode: "SR", NationalityName: "Suriname"},
+	{NationalityCode: "SS", NationalityName: "South Sudan"},
+	{NationalityCode: "ST", NationalityName: "Sao Tome and Principe"},
+	{NationalityCode: "SV", NationalityName: "El Salvador"},
+	{NationalityCode: "SX", NationalityName: "Sint Maarten (Dutch part)"},
+	{NationalityCode: "SY", NationalityName: "Syrian Arab Republic"},
+	{NationalityCode: "SZ", NationalityName: "Eswatini"},
+	{NationalityCode: "TC", NationalityName: "Turks and Caicos Islands"},
+	{NationalityCode: "TD", NationalityName: "Chad"},
+	{NationalityCode: "TF", NationalityName: "French Southern Territories"},
+	{NationalityCode: "TG", NationalityName: "Togo"},
+	{NationalityCode: "TH", NationalityName: "Thailand"},
+	{NationalityCode: "TJ", NationalityName: "Tajikistan"},
+	{NationalityCode: "TK", NationalityName: "Tokelau"},
+	{NationalityCode: "TL", NationalityName: "Timor-Leste"},
+	{NationalityCode: "TM", NationalityName: "Turkmenistan"},
+	{NationalityCode: "TN", NationalityName: "Tunisia"},
+	{NationalityCode: "TO", NationalityName: "Tonga"},
+	{NationalityCode: "TR", NationalityName: "Türkiye"},
+	{NationalityCode: "TT", NationalityName: "Trinidad and Tobago"},
+	{NationalityCode: "TV", NationalityName: "Tuvalu"},
+	{NationalityCode: "TW", NationalityName: "Taiwan"},
+	{NationalityCode: "TZ", NationalityName: "Tanzania"},
+	{NationalityCode: "UA", NationalityName: "Ukraine"},
+	{NationalityCode: "UG", NationalityName: "Uganda"},
+	{NationalityCode: "UM", NationalityName: "United States Minor Outlying Islands"},
+	{NationalityCode: "US", NationalityName: "United States of America"},
+	{NationalityCode: "UY", NationalityName: "Uruguay"},
+	{NationalityCode: "UZ", NationalityName: "Uzbekistan"},
+	{NationalityCode: "VA", NationalityName: "Holy See"},
+	{NationalityCode: "VC", NationalityName: "Saint Vincent and the Grenadines"},
+	{NationalityCode: "VE", NationalityName: "Venezuela"},
+	{NationalityCode: "VG", NationalityName: "Virgin Islands (British)"},
+	{NationalityCode: "VI", NationalityName: "Virgin Islands (U.S.)"},
+	{NationalityCode: "VN", NationalityName: "Viet Nam"},
+	{NationalityCode: "VU", NationalityName: "Vanuatu"},
+	{NationalityCode: "WF", NationalityName: "Wallis and Futuna"},
+	{NationalityCode: "WS", NationalityName: "Samoa"},
+	{NationalityCode: "YE", NationalityName: "Yemen"},
+	{NationalityCode: "YT", NationalityName: "Mayotte"},
+	{NationalityCode: "ZA", NationalityName: "South Africa"},
+	{NationalityCode: "ZM", NationalityName: "Zambia"},
+	{NationalityCode: "ZW", NationalityName: "Zimbabwe"},
+}
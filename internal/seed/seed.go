@@ -0,0 +1,139 @@
+// Package seed fills a development database with reference data and
+// synthetic customers.
+package seed
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoadNationalities inserts every entry of Nationalities whose code is not in
+// the table yet, so it can be re-run safely. It returns the number of rows
+// inserted.
+func LoadNationalities(ctx context.Context, db *pgxpool.Pool) (int64, error) {
+	codes := make([]string, 0, len(Nationalities))
+	names := make([]string, 0, len(Nationalities))
+	for _, nationality := range Nationalities {
+		codes = append(codes, nationality.NationalityCode)
+		names = append(names, nationality.NationalityName)
+	}
+
+	query := `INSERT INTO nationality (nationality_code, nationality_name)
+		SELECT src.code, src.name
+		FROM unnest($1::text[], $2::text[]) AS src(code, name)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM nationality n WHERE n.nationality_code = src.code
+		)
+		ORDER BY src.code
+	`
+
+	tag, err := db.Exec(ctx, query, codes, names)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert nationalities: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// NationalityIDs returns every nationality ID ordered by code, which keeps
+// customer generation stable across databases seeded the same way.
+func NationalityIDs(ctx context.Context, db *pgxpool.Pool) ([]int, error) {
+	rows, err := db.Query(ctx, `SELECT nationality_id FROM nationality ORDER BY nationality_code, nationality_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CustomerOptions controls the synthetic customers generated by Customers.
+type CustomerOptions struct {
+	Count       int
+	Seed        uint64
+	MaxFamilies int
+	// Today is the date family members' ages are taken at to decide who is a
+	// minor and needs a guardian.
+	Today time.Time
+}
+
+// Customers creates opts.Count customers through repo.Create, so families go
+// through the same COPY path as the API. The same seed, nationality IDs and
+// Today always produce the same customers.
+func Customers(ctx context.Context, repo repository.IUserRepository, nationalityIDs []int, opts CustomerOptions) error {
+	if opts.Count <= 0 {
+		return nil
+	}
+	if len(nationalityIDs) == 0 {
+		return fmt.Errorf("no nationalities to assign, load them first")
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	for i := 0; i < opts.Count; i++ {
+		user := generateCustomer(rng, nationalityIDs, opts.MaxFamilies, opts.Today)
+		if err := repo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create customer %d of %d: %w", i+1, opts.Count, err)
+		}
+	}
+	return nil
+}
+
+var (
+	firstNames = []string{
+		"Adi", "Agus", "Ayu", "Bambang", "Budi", "Citra", "Dewi", "Dimas", "Eka", "Fajar",
+		"Gita", "Hendra", "Indah", "Joko", "Kartika", "Lestari", "Made", "Nur", "Putri", "Rina",
+		"Sari", "Taufik", "Utami", "Wahyu", "Yusuf", "Amelia", "Daniel", "Hana", "Kevin", "Maria",
+	}
+	lastNames = []string{
+		"Pratama", "Saputra", "Wijaya", "Santoso", "Hidayat", "Kurniawan", "Setiawan", "Nugroho",
+		"Gunawan", "Siregar", "Lubis", "Nasution", "Halim", "Tanjung", "Sihombing", "Wibowo",
+	}
+)
+
+func generateCustomer(rng *rand.Rand, nationalityIDs []int, maxFamilies int, today time.Time) *model.User {
+	lastName := pick(rng, lastNames)
+	user := &model.User{
+		Name:          pick(rng, firstNames) + " " + lastName,
+		Dob:           randomDate(rng, 1950, 2005),
+		NationalityID: nationalityIDs[rng.IntN(len(nationalityIDs))],
+	}
+
+	// minors get the customer as guardian so they can be booked
+	adultBefore := today.AddDate(-18, 0, 0).Format("2006-01-02")
+	if maxFamilies > 0 {
+		for n := rng.IntN(maxFamilies + 1); n > 0; n-- {
+			family := model.Family{
+				Name: pick(rng, firstNames) + " " + lastName,
+				Dob:  randomDate(rng, 1940, 2024),
+			}
+			if family.Dob > adultBefore {
+				family.Guardian = &model.Guardian{FamilyID: 0}
+			}
+			user.Families = append(user.Families, family)
+		}
+	}
+	return user
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.IntN(len(values))]
+}
+
+// randomDate returns a YYYY-MM-DD date; days stop at 28 so every month works.
+func randomDate(rng *rand.Rand, fromYear, toYear int) string {
+	year := fromYear + rng.IntN(toYear-fromYear+1)
+	return fmt.Sprintf("%04d-%02d-%02d", year, 1+rng.IntN(12), 1+rng.IntN(28))
+}
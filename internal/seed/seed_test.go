@@ -0,0 +1,59 @@
+package seed
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestNationalitiesFitSchema(t *testing.T) {
+	seen := map[string]bool{}
+	for _, nationality := range Nationalities {
+		if len(nationality.NationalityCode) != 2 || seen[nationality.NationalityCode] {
+			t.Errorf("bad or duplicate code %q", nationality.NationalityCode)
+		}
+		seen[nationality.NationalityCode] = true
+
+		if n := utf8.RuneCountInString(nationality.NationalityName); n == 0 || n > 50 {
+			t.Errorf("%s: name %q does not fit varchar(50)", nationality.NationalityCode, nationality.NationalityName)
+		}
+	}
+}
+
+func TestCustomersAreDeterministic(t *testing.T) {
+	ctx := context.Background()
+	opts := CustomerOptions{Count: 25, Seed: 42, MaxFamilies: 4, Today: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+
+	seeded := func() []any {
+		repo := repository.NewInMemoryUserRepository()
+		ids := []int{repo.AddNationality("Indonesia", "ID"), repo.AddNationality("Japan", "JP")}
+		if err := Customers(ctx, repo, ids, opts); err != nil {
+			t.Fatalf("Customers: %v", err)
+		}
+		users, _ := repo.GetAll(ctx)
+		out := make([]any, len(users))
+		for i, user := range users {
+			out[i] = *user
+		}
+		return out
+	}
+
+	first, second := seeded(), seeded()
+	if len(first) != opts.Count {
+		t.Fatalf("got %d customers, want %d", len(first), opts.Count)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("the same seed produced different customers")
+	}
+	for _, user := range first {
+		for _, family := range user.(model.UserDetailResponse).Families {
+			if minor := family.Dob > "2008-01-01"; minor != (family.Guardian != nil) {
+				t.Errorf("%s born %s: guardian = %v", family.Name, family.Dob, family.Guardian)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"fmt"
+)
+
+// bookingTransitions is the booking state machine: every status lists the
+// statuses it may move to. Refunded is terminal.
+var bookingTransitions = map[model.BookingStatus][]model.BookingStatus{
+	model.BookingDraft:     {model.BookingHeld, model.BookingCancelled},
+	model.BookingHeld:      {model.BookingDraft, model.BookingConfirmed, model.BookingCancelled},
+	model.BookingConfirmed: {model.BookingTicketed, model.BookingCancelled},
+	model.BookingTicketed:  {model.BookingCancelled},
+	model.BookingCancelled: {model.BookingRefunded},
+	model.BookingRefunded:  {},
+}
+
+// BookingActions maps the transition endpoint names to their target status.
+// There is no refund action: only MarkRefunded moves a booking to refunded.
+var BookingActions = map[string]model.BookingStatus{
+	"hold":    model.BookingHeld,
+	"release": model.BookingDraft,
+	"confirm": model.BookingConfirmed,
+	"ticket":  model.BookingTicketed,
+	"cancel":  model.BookingCancelled,
+}
+
+// CanTransition reports whether the state machine allows from -> to.
+func CanTransition(from, to model.BookingStatus) bool {
+	for _, next := range bookingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func checkTransition(from, to model.BookingStatus) error {
+	if _, ok := bookingTransitions[to]; !ok {
+		return fmt.Errorf("unknown booking status %q: %w", to, model.ErrInvalidTransition)
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("cannot move booking from %s to %s: %w", from, to, model.ErrInvalidTransition)
+	}
+	return nil
+}
+
+// seatAction is the inventory change that goes with a transition: holding
+// reserves seats, confirming keeps them, and leaving held or a confirmed state
+// for draft or cancelled gives them back.
+func seatAction(from, to model.BookingStatus) model.SeatAction {
+	switch {
+	case to == model.BookingHeld:
+		return model.SeatsHold
+	case from == model.BookingHeld && to == model.BookingConfirmed:
+		return model.SeatsConfirm
+	case to == model.BookingDraft, to == model.BookingCancelled:
+		return model.SeatsRelease
+	default:
+		return model.SeatsUnchanged
+	}
+}
+
+// checkEditable rejects passenger changes and deletion once a booking has
+// left draft.
+func checkEditable(booking *model.Booking) error {
+	if booking.Status != model.BookingDraft {
+		return fmt.Errorf("booking %d is %s, only draft bookings can be changed: %w",
+			booking.BookingID, booking.Status, model.ErrConflict)
+	}
+	return nil
+}
+
+// isPaidStatus reports whether a booking in status has been paid for.
+func isPaidStatus(status model.BookingStatus) bool {
+	return status == model.BookingConfirmed || status == model.BookingTicketed
+}
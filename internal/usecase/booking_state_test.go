@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"errors"
+	"testing"
+)
+
+func TestBookingStateMachine(t *testing.T) {
+	legal := map[[2]model.BookingStatus]bool{
+		{model.BookingDraft, model.BookingHeld}:          true,
+		{model.BookingDraft, model.BookingCancelled}:     true,
+		{model.BookingHeld, model.BookingDraft}:          true,
+		{model.BookingHeld, model.BookingConfirmed}:      true,
+		{model.BookingHeld, model.BookingCancelled}:      true,
+		{model.BookingConfirmed, model.BookingTicketed}:  true,
+		{model.BookingConfirmed, model.BookingCancelled}: true,
+		{model.BookingTicketed, model.BookingCancelled}:  true,
+		{model.BookingCancelled, model.BookingRefunded}:  true,
+	}
+
+	statuses := []model.BookingStatus{
+		model.BookingDraft, model.BookingHeld, model.BookingConfirmed,
+		model.BookingTicketed, model.BookingCancelled, model.BookingRefunded,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			err := checkTransition(from, to)
+			if want := legal[[2]model.BookingStatus{from, to}]; (err == nil) != want {
+				t.Errorf("%s -> %s: err = %v, want legal = %v", from, to, err, want)
+			}
+			if err != nil && !errors.Is(err, model.ErrConflict) {
+				t.Errorf("%s -> %s: error %v is not a conflict", from, to, err)
+			}
+		}
+	}
+
+	if err := checkTransition(model.BookingDraft, "boarding"); !errors.Is(err, model.ErrInvalidTransition) {
+		t.Errorf("unknown status: err = %v", err)
+	}
+	for action, to := range BookingActions {
+		if _, ok := bookingTransitions[to]; !ok {
+			t.Errorf("action %s targets unknown status %s", action, to)
+		}
+	}
+}
+
+func TestSeatAction(t *testing.T) {
+	tests := []struct {
+		from, to model.BookingStatus
+		want     model.SeatAction
+	}{
+		{model.BookingDraft, model.BookingHeld, model.SeatsHold},
+		{model.BookingHeld, model.BookingConfirmed, model.SeatsConfirm},
+		{model.BookingHeld, model.BookingDraft, model.SeatsRelease},
+		{model.BookingHeld, model.BookingCancelled, model.SeatsRelease},
+		{model.BookingConfirmed, model.BookingCancelled, model.SeatsRelease},
+		{model.BookingTicketed, model.BookingCancelled, model.SeatsRelease},
+		{model.BookingDraft, model.BookingCancelled, model.SeatsRelease},
+		{model.BookingConfirmed, model.BookingTicketed, model.SeatsUnchanged},
+		{model.BookingCancelled, model.BookingRefunded, model.SeatsUnchanged},
+	}
+	for _, tt := range tests {
+		if got := seatAction(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s -> %s: seat action = %d, want %d", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/metrics"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+type IBookingUsecase interface {
+	GetAll(ctx context.Context, userID int) (bookings []*model.Booking, err error)
+	Create(ctx context.Context, request *model.BookingRequest) (booking *model.Booking, err error)
+	Detail(ctx context.Context, bookingID int) (booking *model.Booking, err error)
+	UpdatePassengers(ctx context.Context, bookingID int, request *model.BookingRequest) (booking *model.Booking, err error)
+	Delete(ctx context.Context, bookingID int) (err error)
+	Transition(ctx context.Context, bookingID int, to model.BookingStatus, reason string) (booking *model.Booking, err error)
+	MarkRefunded(ctx context.Context, bookingID int, reason string) (booking *model.Booking, err error)
+	History(ctx context.Context, bookingID int) (history []model.BookingStatusChange, err error)
+}
+
+type BookingUsecase struct {
+	bookingRepository  repository.IBookingRepository
+	userRepository     repository.IUserRepository
+	tripRepository     repository.ITripRepository
+	paymentRepository  repository.IPaymentRepository
+	documentRepository repository.IDocumentRepository
+	holdTTL            time.Duration
+}
+
+// NewBookingUsecase builds the booking usecase; holdTTL is how long a hold
+// keeps seats reserved before the reaper releases them.
+func NewBookingUsecase(bookingRepository repository.IBookingRepository, userRepository repository.IUserRepository,
+	tripRepository repository.ITripRepository, paymentRepository repository.IPaymentRepository,
+	documentRepository repository.IDocumentRepository, holdTTL time.Duration) *BookingUsecase {
+	return &BookingUsecase{
+		bookingRepository:  bookingRepository,
+		userRepository:     userRepository,
+		tripRepository:     tripRepository,
+		paymentRepository:  paymentRepository,
+		documentRepository: documentRepository,
+		holdTTL:            holdTTL,
+	}
+}
+
+func (u *BookingUsecase) GetAll(ctx context.Context, userID int) (bookings []*model.Booking, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.GetAll")
+	defer func() { tracing.End(span, err) }()
+
+	bookings, err = u.bookingRepository.GetAll(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Booking get all failed: ", err.Error())
+		return
+	}
+	return
+}
+
+func (u *BookingUsecase) Create(ctx context.Context, request *model.BookingRequest) (booking *model.Booking, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.Create")
+	defer func() { tracing.End(span, err) }()
+
+	customer, err := validatePassengers(ctx, u.userRepository, request)
+	if err != nil {
+		return nil, err
+	}
+	if request.FareClass == "" {
+		request.FareClass = model.FareClassStandard
+	}
+	err = validation.Validate(request.FareClass, validation.In(model.FareClassStandard, model.FareClassFlex).
+		Error("fare class must be standard or flex"))
+	if err != nil {
+		return nil, validation.Errors{"fare_class": err}
+	}
+	travelDate := time.Now()
+	if request.TripID != 0 {
+		trip, tripErr := u.tripRepository.GetByID(ctx, request.TripID)
+		if errors.Is(tripErr, pgx.ErrNoRows) {
+			return nil, validation.Errors{"trip_id": errors.New("trip not found")}
+		}
+		if tripErr != nil {
+			return nil, tripErr
+		}
+		travelDate = trip.DepartureAt
+	}
+	if err = checkGuardians(customer, request.FamilyIDs, travelDate); err != nil {
+		return nil, err
+	}
+
+	booking = &model.Booking{
+		UserID:     request.UserID,
+		TripID:     request.TripID,
+		FareClass:  request.FareClass,
+		Passengers: passengersFromRequest(request),
+	}
+	if err = u.bookingRepository.Create(ctx, booking); err != nil {
+		logger.FromContext(ctx).Error("Booking create failed: ", err.Error())
+		return nil, err
+	}
+
+	metrics.BookingsCreated.Inc()
+
+	return u.bookingRepository.GetByID(ctx, booking.BookingID)
+}
+
+func (u *BookingUsecase) Detail(ctx context.Context, bookingID int) (booking *model.Booking, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.Detail")
+	defer func() { tracing.End(span, err) }()
+
+	booking, err = u.bookingRepository.GetByID(ctx, bookingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, bookingNotFound(bookingID)
+	}
+	return
+}
+
+// UpdatePassengers replaces the passenger list. The booking keeps its
+// customer, whatever user_id the request carries.
+func (u *BookingUsecase) UpdatePassengers(ctx context.Context, bookingID int, request *model.BookingRequest) (booking *model.Booking, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.UpdatePassengers")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := u.Detail(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkEditable(current); err != nil {
+		return nil, err
+	}
+
+	request.UserID = current.UserID
+	customer, err := validatePassengers(ctx, u.userRepository, request)
+	if err != nil {
+		return nil, err
+	}
+	travelDate := time.Now()
+	if current.TripID != 0 {
+		trip, tripErr := u.tripRepository.GetByID(ctx, current.TripID)
+		if tripErr != nil {
+			return nil, tripErr
+		}
+		travelDate = trip.DepartureAt
+	}
+	if err = checkGuardians(customer, request.FamilyIDs, travelDate); err != nil {
+		return nil, err
+	}
+
+	err = u.bookingRepository.ReplacePassengers(ctx, bookingID, current.Status, passengersFromRequest(request))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, bookingNotFound(bookingID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Booking passengers update failed: ", err.Error())
+		return nil, err
+	}
+
+	return u.bookingRepository.GetByID(ctx, bookingID)
+}
+
+func (u *BookingUsecase) Delete(ctx context.Context, bookingID int) (err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := u.Detail(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+	if err = checkEditable(current); err != nil {
+		return err
+	}
+
+	err = u.bookingRepository.Delete(ctx, bookingID, current.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return bookingNotFound(bookingID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Booking delete failed: ", err.Error())
+	}
+	return
+}
+
+// Transition moves a booking to another status if the state machine allows
+// it. The repository applies the move only if nobody changed the status since
+// it was read, so concurrent transitions fail with model.ErrStatusChanged
+// instead of skipping a state. Paid bookings are not cancelled here but
+// through ICancellationUsecase, which refunds them, and only MarkRefunded
+// moves a booking to refunded.
+func (u *BookingUsecase) Transition(ctx context.Context, bookingID int, to model.BookingStatus, reason string) (booking *model.Booking, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.Transition")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := u.Detail(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTransition(current.Status, to); err != nil {
+		return nil, err
+	}
+	if to == model.BookingCancelled && isPaidStatus(current.Status) {
+		return nil, model.ErrCancellationRequired
+	}
+	if to == model.BookingRefunded {
+		return nil, model.ErrRefundRequired
+	}
+
+	return u.transition(ctx, current, to, reason)
+}
+
+// MarkRefunded moves a cancelled booking to refunded once the payment usecase
+// has paid its refund out.
+func (u *BookingUsecase) MarkRefunded(ctx context.Context, bookingID int, reason string) (booking *model.Booking, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.MarkRefunded")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := u.Detail(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTransition(current.Status, model.BookingRefunded); err != nil {
+		return nil, err
+	}
+
+	return u.transition(ctx, current, model.BookingRefunded, reason)
+}
+
+// transition applies a move Transition or MarkRefunded has allowed.
+func (u *BookingUsecase) transition(ctx context.Context, current *model.Booking, to model.BookingStatus, reason string) (*model.Booking, error) {
+	bookingID := current.BookingID
+	update := model.StatusUpdate{
+		BookingID: bookingID,
+		From:      current.Status,
+		To:        to,
+		Reason:    reason,
+		Seats:     seatAction(current.Status, to),
+		HoldTTL:   u.holdTTL,
+	}
+	if update.Seats == model.SeatsHold {
+		if err := u.checkHoldable(ctx, current); err != nil {
+			return nil, err
+		}
+	}
+	if to == model.BookingConfirmed {
+		if err := u.checkPaid(ctx, bookingID); err != nil {
+			return nil, err
+		}
+	}
+
+	err := u.bookingRepository.UpdateStatus(ctx, update)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, bookingNotFound(bookingID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Booking transition failed: ", err.Error())
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Infof("Booking %d moved from %s to %s", bookingID, current.Status, to)
+
+	return u.bookingRepository.GetByID(ctx, bookingID)
+}
+
+// checkHoldable gives a clear error before trying to hold seats for a booking
+// without a trip, on a trip that has left, with a minor who has lost their
+// guardian since being booked, or on an international trip with a passenger
+// who has no valid passport.
+func (u *BookingUsecase) checkHoldable(ctx context.Context, booking *model.Booking) error {
+	if booking.TripID == 0 {
+		return model.ErrNoTrip
+	}
+	trip, err := u.tripRepository.GetByID(ctx, booking.TripID)
+	if err != nil {
+		return err
+	}
+	if !trip.DepartureAt.After(time.Now()) {
+		return fmt.Errorf("trip %s: %w", trip.TripCode, model.ErrTripDeparted)
+	}
+	customer, err := u.userRepository.GetUserDetail(ctx, booking.UserID)
+	if err != nil {
+		return err
+	}
+	familyIDs := []int{}
+	for _, passenger := range booking.Passengers {
+		if passenger.FamilyID != 0 && passenger.CancelledAt == nil {
+			familyIDs = append(familyIDs, passenger.FamilyID)
+		}
+	}
+	if err := checkGuardians(customer, familyIDs, trip.DepartureAt); err != nil {
+		return err
+	}
+	if !trip.International {
+		return nil
+	}
+	documents, err := u.documentRepository.GetByUser(ctx, booking.UserID)
+	if err != nil {
+		return err
+	}
+	return checkTravelDocuments(trip, booking.Passengers, documents)
+}
+
+// checkPaid requires a succeeded payment before a booking is confirmed.
+func (u *BookingUsecase) checkPaid(ctx context.Context, bookingID int) error {
+	payment, err := u.paymentRepository.Current(ctx, bookingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.ErrPaymentRequired
+	}
+	if err != nil {
+		return err
+	}
+	if payment.Status != model.PaymentSucceeded {
+		return fmt.Errorf("payment %d is %s: %w", payment.PaymentID, payment.Status, model.ErrPaymentRequired)
+	}
+	return nil
+}
+
+// ReleaseExpiredHolds moves bookings whose hold expired back to draft and
+// returns their seats, one transaction per booking through the same path as
+// a release request. Bookings confirmed or re-held in the meantime are
+// skipped.
+func (u *BookingUsecase) ReleaseExpiredHolds(ctx context.Context) (released int, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.ReleaseExpiredHolds")
+	defer func() { tracing.End(span, err) }()
+
+	bookingIDs, err := u.bookingRepository.ExpiredHolds(ctx, expiredHoldBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, bookingID := range bookingIDs {
+		err = u.bookingRepository.UpdateStatus(ctx, model.StatusUpdate{
+			BookingID:   bookingID,
+			From:        model.BookingHeld,
+			To:          model.BookingDraft,
+			Reason:      "hold expired",
+			Seats:       model.SeatsRelease,
+			ExpiredOnly: true,
+		})
+		if errors.Is(err, model.ErrStatusChanged) || errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return released, fmt.Errorf("failed to release hold of booking %d: %w", bookingID, err)
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// expiredHoldBatch caps the holds released per reaper run.
+const expiredHoldBatch = 100
+
+// WatchExpiredHolds runs ReleaseExpiredHolds every interval until ctx is done.
+func (u *BookingUsecase) WatchExpiredHolds(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := u.ReleaseExpiredHolds(ctx)
+			if err != nil {
+				logger.FromContext(ctx).Error("Releasing expired holds failed: ", err)
+				continue
+			}
+			if released > 0 {
+				logger.FromContext(ctx).Infof("Released %d expired seat holds", released)
+			}
+		}
+	}
+}
+
+func (u *BookingUsecase) History(ctx context.Context, bookingID int) (history []model.BookingStatusChange, err error) {
+	ctx, span := tracing.Start(ctx, "BookingUsecase.History")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = u.Detail(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	return u.bookingRepository.History(ctx, bookingID)
+}
+
+// validatePassengers checks the request shape, then that the customer exists
+// and owns every listed family member, and returns the customer. The
+// repository re-checks ownership inside its transaction, this only produces
+// the friendlier error.
+func validatePassengers(ctx context.Context, userRepository repository.IUserRepository, request *model.BookingRequest) (customer *model.UserDetailResponse, err error) {
+	ctx, span := tracing.Start(ctx, "validatePassengers")
+	defer func() { tracing.End(span, err) }()
+
+	err = validation.ValidateStruct(request,
+		validation.Field(&request.UserID, validation.Required.Error("User ID is required"), validation.Min(1).Error("User ID must be a positive integer")),
+		validation.Field(&request.FamilyIDs,
+			validation.When(!request.IncludeCustomer, validation.Required.Error("at least one passenger is required")),
+			validation.Each(validation.Min(1).Error("Family ID must be a positive integer")),
+			validation.By(uniqueIDs)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err = userRepository.GetUserDetail(ctx, request.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, validation.Errors{"user_id": errors.New("customer not found")}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[int]bool, len(customer.Families))
+	for _, family := range customer.Families {
+		owned[family.FamilyID] = true
+	}
+	for _, familyID := range request.FamilyIDs {
+		if !owned[familyID] {
+			return nil, fmt.Errorf("family member %d: %w", familyID, model.ErrPassengerNotInFamily)
+		}
+	}
+
+	return customer, nil
+}
+
+// checkGuardians requires every family member among familyIDs who is under
+// 18 on travelDate to have a guardian. familyIDs must belong to customer.
+func checkGuardians(customer *model.UserDetailResponse, familyIDs []int, travelDate time.Time) error {
+	families := make(map[int]model.Family, len(customer.Families))
+	for _, family := range customer.Families {
+		families[family.FamilyID] = family
+	}
+
+	travelDate = travelDate.UTC()
+	for _, familyID := range familyIDs {
+		family := families[familyID]
+		if family.Guardian != nil {
+			continue
+		}
+		dob, err := time.Parse(dateLayout, family.Dob)
+		if err != nil {
+			return err
+		}
+		if ageOn(dob, travelDate) < adultAge {
+			return fmt.Errorf("%s: %w", family.Name, model.ErrGuardianRequired)
+		}
+	}
+	return nil
+}
+
+func passengersFromRequest(request *model.BookingRequest) []model.Passenger {
+	passengers := []model.Passenger{}
+	if request.IncludeCustomer {
+		passengers = append(passengers, model.Passenger{IsCustomer: true})
+	}
+	for _, familyID := range request.FamilyIDs {
+		passengers = append(passengers, model.Passenger{FamilyID: familyID})
+	}
+	return passengers
+}
+
+func bookingNotFound(bookingID int) error {
+	return fmt.Errorf("booking %d: %w", bookingID, model.ErrNotFound)
+}
+
+func uniqueIDs(value interface{}) error {
+	ids, _ := value.([]int)
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return validation.NewError("validation_duplicate_id", fmt.Sprintf("ID %d is listed more than once", id))
+		}
+		seen[id] = true
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBookingUsecaseValidatePassengers(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewInMemoryUserRepository()
+	nationalityID := users.AddNationality("Indonesia", "ID")
+
+	owner := &model.User{Name: "Budi Santoso", Dob: "1980-01-01", NationalityID: nationalityID,
+		Families: []model.Family{{Name: "Siti Santoso", Dob: "2012-01-01"}}}
+	other := &model.User{Name: "Ayu Wijaya", Dob: "1985-01-01", NationalityID: nationalityID,
+		Families: []model.Family{{Name: "Dimas Wijaya", Dob: "2014-01-01"}}}
+	for _, user := range []*model.User{owner, other} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ownFamilyID, otherFamilyID := familyIDs(t, users, owner.UserID)[0], familyIDs(t, users, other.UserID)[0]
+
+	tests := []struct {
+		name    string
+		request model.BookingRequest
+		wantErr error
+	}{
+		{
+			name:    "customer and own family member",
+			request: model.BookingRequest{UserID: owner.UserID, IncludeCustomer: true, FamilyIDs: []int{ownFamilyID}},
+		},
+		{
+			name:    "customer travelling alone",
+			request: model.BookingRequest{UserID: owner.UserID, IncludeCustomer: true},
+		},
+		{
+			name:    "family member of another customer",
+			request: model.BookingRequest{UserID: owner.UserID, FamilyIDs: []int{ownFamilyID, otherFamilyID}},
+			wantErr: model.ErrPassengerNotInFamily,
+		},
+		{
+			name:    "no passengers",
+			request: model.BookingRequest{UserID: owner.UserID},
+			wantErr: errAny,
+		},
+		{
+			name:    "duplicate family member",
+			request: model.BookingRequest{UserID: owner.UserID, FamilyIDs: []int{ownFamilyID, ownFamilyID}},
+			wantErr: errAny,
+		},
+		{
+			name:    "unknown customer",
+			request: model.BookingRequest{UserID: 999, IncludeCustomer: true},
+			wantErr: errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validatePassengers(ctx, users, &tt.request)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Fatal("expected an error")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// errAny marks cases where any validation error is acceptable.
+var errAny = errors.New("any error")
+
+func familyIDs(t *testing.T, users repository.IUserRepository, userID int) []int {
+	t.Helper()
+	detail, err := users.GetUserDetail(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int{}
+	for _, family := range detail.Families {
+		ids = append(ids, family.FamilyID)
+	}
+	return ids
+}
+
+func TestCheckGuardians(t *testing.T) {
+	departure := time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC)
+	customer := &model.UserDetailResponse{Name: "Budi Santoso", Dob: "1980-01-01", Families: []model.Family{
+		{FamilyID: 1, Name: "Adult Sibling", Dob: "1985-01-01"},
+		{FamilyID: 2, Name: "Guarded Child", Dob: "2015-01-01", Guardian: &model.Guardian{FamilyID: 0}},
+		{FamilyID: 3, Name: "Lone Child", Dob: "2015-01-01"},
+		{FamilyID: 4, Name: "Almost Adult", Dob: "2008-07-01"},
+		{FamilyID: 5, Name: "Still Minor", Dob: "2008-07-02"},
+	}}
+
+	tests := []struct {
+		name      string
+		familyIDs []int
+		wantErr   bool
+	}{
+		{name: "customer travelling alone"},
+		{name: "adult without guardian", familyIDs: []int{1}},
+		{name: "minor with guardian", familyIDs: []int{1, 2}},
+		{name: "minor without guardian", familyIDs: []int{1, 3}, wantErr: true},
+		{name: "turns 18 on the departure date", familyIDs: []int{4}},
+		{name: "turns 18 the day after departure", familyIDs: []int{5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := checkGuardians(customer, tt.familyIDs, departure)
+		if tt.wantErr {
+			if !errors.Is(err, model.ErrGuardianRequired) {
+				t.Errorf("%s: got %v, want ErrGuardianRequired", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestBookingUsecaseCheckHoldableGuardians(t *testing.T) {
+	departure := time.Now().Add(48 * time.Hour)
+	cancelled := time.Now()
+	customer := &model.UserDetailResponse{UserID: 1, Name: "Budi Santoso", Dob: "1980-01-01", Families: []model.Family{
+		{FamilyID: 2, Name: "Guarded Child", Dob: "2020-01-01", Guardian: &model.Guardian{FamilyID: 0}},
+		{FamilyID: 3, Name: "Lone Child", Dob: "2020-01-01"},
+	}}
+	u := &BookingUsecase{
+		userRepository: stubCustomers{customer: customer},
+		tripRepository: stubTrips{trip: &model.Trip{TripID: 1, TripCode: "JKT-DPS-1", DepartureAt: departure}},
+	}
+
+	tests := []struct {
+		name       string
+		passengers []model.Passenger
+		wantErr    bool
+	}{
+		{name: "minor with guardian", passengers: []model.Passenger{{IsCustomer: true}, {FamilyID: 2}}},
+		{name: "guardian removed after booking", passengers: []model.Passenger{{FamilyID: 3}}, wantErr: true},
+		{name: "cancelled minor", passengers: []model.Passenger{{FamilyID: 2}, {FamilyID: 3, CancelledAt: &cancelled}}},
+	}
+	for _, tt := range tests {
+		booking := &model.Booking{BookingID: 1, UserID: customer.UserID, TripID: 1, Passengers: tt.passengers}
+		err := u.checkHoldable(context.Background(), booking)
+		if tt.wantErr {
+			if !errors.Is(err, model.ErrGuardianRequired) {
+				t.Errorf("%s: got %v, want ErrGuardianRequired", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
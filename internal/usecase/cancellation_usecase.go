@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+type ICancellationUsecase interface {
+	Preview(ctx context.Context, bookingID int, request *model.CancellationRequest) (cancellation *model.Cancellation, err error)
+	Cancel(ctx context.Context, bookingID int, request *model.CancellationRequest) (cancellation *model.Cancellation, err error)
+	Refunds(ctx context.Context, bookingID int) (refunds []*model.Refund, err error)
+}
+
+type CancellationUsecase struct {
+	bookingRepository repository.IBookingRepository
+	paymentRepository repository.IPaymentRepository
+	tripRepository    repository.ITripRepository
+	bookings          IBookingUsecase
+	payments          IPaymentUsecase
+	policy            RefundPolicy
+}
+
+// NewCancellationUsecase builds the cancellation usecase. Refunds of paid
+// passengers follow policy and are paid out through payments.
+func NewCancellationUsecase(bookingRepository repository.IBookingRepository, paymentRepository repository.IPaymentRepository,
+	tripRepository repository.ITripRepository, bookings IBookingUsecase, payments IPaymentUsecase, policy RefundPolicy) *CancellationUsecase {
+	return &CancellationUsecase{
+		bookingRepository: bookingRepository,
+		paymentRepository: paymentRepository,
+		tripRepository:    tripRepository,
+		bookings:          bookings,
+		payments:          payments,
+		policy:            policy,
+	}
+}
+
+// Preview computes what cancelling would refund without changing anything.
+func (u *CancellationUsecase) Preview(ctx context.Context, bookingID int, request *model.CancellationRequest) (cancellation *model.Cancellation, err error) {
+	ctx, span := tracing.Start(ctx, "CancellationUsecase.Preview")
+	defer func() { tracing.End(span, err) }()
+
+	_, _, cancellation, err = u.plan(ctx, bookingID, request, time.Now())
+	return
+}
+
+// Cancel cancels passengers of a booking, or all of them. Unpaid bookings can
+// only be cancelled as a whole and go through the state machine. Paid
+// passengers leave the booking with their refund recorded in one
+// transaction; the refund is then issued, and retried by payment
+// reconciliation if the gateway fails.
+func (u *CancellationUsecase) Cancel(ctx context.Context, bookingID int, request *model.CancellationRequest) (cancellation *model.Cancellation, err error) {
+	ctx, span := tracing.Start(ctx, "CancellationUsecase.Cancel")
+	defer func() { tracing.End(span, err) }()
+
+	booking, payment, cancellation, err := u.plan(ctx, bookingID, request, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if !isPaidStatus(booking.Status) {
+		if booking, err = u.bookings.Transition(ctx, bookingID, model.BookingCancelled, request.Reason); err != nil {
+			return nil, err
+		}
+		cancellation.Status = booking.Status
+		return cancellation, nil
+	}
+
+	applied := model.PassengerCancellation{
+		BookingID: bookingID,
+		Status:    booking.Status,
+		Reason:    request.Reason,
+	}
+	for _, item := range cancellation.Items {
+		applied.PassengerIDs = append(applied.PassengerIDs, item.PassengerID)
+	}
+	if cancellation.RefundTotal > 0 {
+		applied.Refund = &model.Refund{
+			PaymentID: payment.PaymentID,
+			BookingID: bookingID,
+			Amount:    cancellation.RefundTotal,
+			Currency:  payment.Currency,
+			Reason:    request.Reason,
+			Items:     cancellation.Items,
+		}
+	}
+
+	err = u.bookingRepository.CancelPassengers(ctx, applied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, bookingNotFound(bookingID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Booking cancellation failed: ", err.Error())
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Infof("Cancelled %d passengers of booking %d", len(applied.PassengerIDs), bookingID)
+
+	if applied.Refund != nil {
+		if err := u.payments.IssueRefund(ctx, applied.Refund); err != nil {
+			logger.FromContext(ctx).Errorf("Refund %d failed, reconciliation retries it: %v", applied.Refund.RefundID, err)
+		}
+		cancellation.Refund = applied.Refund
+	}
+
+	if booking, err = u.bookings.Detail(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	cancellation.Status = booking.Status
+	return cancellation, nil
+}
+
+func (u *CancellationUsecase) Refunds(ctx context.Context, bookingID int) (refunds []*model.Refund, err error) {
+	ctx, span := tracing.Start(ctx, "CancellationUsecase.Refunds")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = u.bookings.Detail(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	return u.paymentRepository.RefundsByBooking(ctx, bookingID)
+}
+
+// plan checks a cancellation request against the booking and computes the
+// refund of every passenger it cancels, with what they paid and the policy
+// rule for the booking's fare class on the day. It also returns the
+// booking and, for paid bookings, the payment refunds are taken from.
+func (u *CancellationUsecase) plan(ctx context.Context, bookingID int, request *model.CancellationRequest, now time.Time) (
+	*model.Booking, *model.Payment, *model.Cancellation, error) {
+	err := validation.ValidateStruct(request,
+		validation.Field(&request.PassengerIDs,
+			validation.Each(validation.Min(1).Error("Passenger ID must be a positive integer")),
+			validation.By(uniqueIDs)),
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	booking, err := u.bookings.Detail(ctx, bookingID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = checkTransition(booking.Status, model.BookingCancelled); err != nil {
+		return nil, nil, nil, err
+	}
+
+	active := map[int]model.Passenger{}
+	all := []int{}
+	for _, passenger := range booking.Passengers {
+		if passenger.CancelledAt == nil {
+			active[passenger.PassengerID] = passenger
+			all = append(all, passenger.PassengerID)
+		}
+	}
+	passengerIDs := request.PassengerIDs
+	if len(passengerIDs) == 0 {
+		passengerIDs = all
+	}
+	for _, passengerID := range passengerIDs {
+		if _, ok := active[passengerID]; !ok {
+			return nil, nil, nil, fmt.Errorf("passenger %d: %w", passengerID, model.ErrPassengerNotActive)
+		}
+	}
+
+	paid := isPaidStatus(booking.Status)
+	if !paid && len(passengerIDs) < len(all) {
+		return nil, nil, nil, fmt.Errorf("booking %d is %s, only passengers of confirmed bookings can be cancelled one by one: %w",
+			bookingID, booking.Status, model.ErrConflict)
+	}
+
+	cancellation := &model.Cancellation{
+		BookingID: bookingID,
+		FareClass: booking.FareClass,
+		Status:    booking.Status,
+		Items:     make([]model.RefundItem, 0, len(passengerIDs)),
+	}
+	if len(passengerIDs) == len(all) {
+		cancellation.Status = model.BookingCancelled
+	}
+
+	var (
+		payment *model.Payment
+		items   = map[int]model.PaymentItem{}
+	)
+	if paid {
+		if cancellation.DaysBeforeDeparture, err = u.daysBeforeDeparture(ctx, booking, now); err != nil {
+			return nil, nil, nil, err
+		}
+		if payment, err = u.paidWith(ctx, bookingID); err != nil {
+			return nil, nil, nil, err
+		}
+		if payment != nil {
+			cancellation.Currency = payment.Currency
+			paidItems, err := u.paymentRepository.Items(ctx, payment.PaymentID)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			for _, item := range paidItems {
+				items[item.PassengerID] = item
+			}
+		}
+	}
+
+	for _, passengerID := range passengerIDs {
+		item := model.RefundItem{Rule: "none"}
+		if paidItem, ok := items[passengerID]; ok {
+			item = u.policy.Item(booking.FareClass, paidItem.Band, cancellation.DaysBeforeDeparture, paidItem.Amount)
+		}
+		item.PassengerID = passengerID
+		item.Name = active[passengerID].Name
+		cancellation.Items = append(cancellation.Items, item)
+		cancellation.RefundTotal += item.Amount
+	}
+
+	return booking, payment, cancellation, nil
+}
+
+// daysBeforeDeparture is how many whole days are left before the booking's
+// trip leaves. Passengers of a departed trip cannot be cancelled.
+func (u *CancellationUsecase) daysBeforeDeparture(ctx context.Context, booking *model.Booking, now time.Time) (int, error) {
+	if booking.TripID == 0 {
+		return 0, nil
+	}
+	trip, err := u.tripRepository.GetByID(ctx, booking.TripID)
+	if err != nil {
+		return 0, err
+	}
+	days := daysBefore(trip.DepartureAt, now)
+	if days < 0 {
+		return 0, fmt.Errorf("trip %s: %w", trip.TripCode, model.ErrTripDeparted)
+	}
+	return days, nil
+}
+
+// paidWith returns the succeeded payment of a booking, or nil for bookings
+// confirmed without one.
+func (u *CancellationUsecase) paidWith(ctx context.Context, bookingID int) (*model.Payment, error) {
+	payment, err := u.paymentRepository.Current(ctx, bookingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != model.PaymentSucceeded {
+		return nil, nil
+	}
+	return payment, nil
+}
@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type IConfirmationUsecase interface {
+	Confirmation(ctx context.Context, bookingID int) (confirmation *model.Confirmation, err error)
+}
+
+type ConfirmationUsecase struct {
+	bookingRepository  repository.IBookingRepository
+	userRepository     repository.IUserRepository
+	tripRepository     repository.ITripRepository
+	paymentRepository  repository.IPaymentRepository
+	documentRepository repository.IDocumentRepository
+	rules              FareRules
+}
+
+// NewConfirmationUsecase builds the booking confirmation usecase. The price
+// breakdown is worked out with rules, like the amount of a payment.
+func NewConfirmationUsecase(bookingRepository repository.IBookingRepository, userRepository repository.IUserRepository,
+	tripRepository repository.ITripRepository, paymentRepository repository.IPaymentRepository,
+	documentRepository repository.IDocumentRepository, rules FareRules) *ConfirmationUsecase {
+	return &ConfirmationUsecase{
+		bookingRepository:  bookingRepository,
+		userRepository:     userRepository,
+		tripRepository:     tripRepository,
+		paymentRepository:  paymentRepository,
+		documentRepository: documentRepository,
+		rules:              rules,
+	}
+}
+
+// Confirmation gathers what the confirmation of a booking prints. Passengers
+// who were cancelled are listed but not priced. Paid bookings show what each
+// passenger was charged, from the payment's stored items; bookings with a
+// pending payment are priced as of that payment, the others as of now.
+func (u *ConfirmationUsecase) Confirmation(ctx context.Context, bookingID int) (confirmation *model.Confirmation, err error) {
+	ctx, span := tracing.Start(ctx, "ConfirmationUsecase.Confirmation")
+	defer func() { tracing.End(span, err) }()
+
+	booking, err := u.bookingRepository.GetByID(ctx, bookingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, bookingNotFound(bookingID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := u.userRepository.GetUserDetail(ctx, booking.UserID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Confirmation customer lookup failed: ", err.Error())
+		return nil, err
+	}
+	documents, err := u.documentRepository.GetByUser(ctx, booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	confirmation = &model.Confirmation{
+		Booking:    booking,
+		Customer:   customer,
+		Passengers: make([]model.ConfirmationPassenger, 0, len(booking.Passengers)),
+		IssuedAt:   now,
+	}
+	for _, passenger := range booking.Passengers {
+		confirmation.Passengers = append(confirmation.Passengers, model.ConfirmationPassenger{
+			Passenger:   passenger,
+			Nationality: passengerNationality(customer, documents, passenger),
+		})
+	}
+
+	if booking.TripID == 0 {
+		return confirmation, nil
+	}
+	if confirmation.Trip, err = u.tripRepository.GetByID(ctx, booking.TripID); err != nil {
+		return nil, err
+	}
+
+	active := []model.Passenger{}
+	for _, passenger := range booking.Passengers {
+		if passenger.CancelledAt == nil {
+			active = append(active, passenger)
+		}
+	}
+
+	pricedAt := now
+	payment, err := u.paymentRepository.Current(ctx, bookingID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if payment != nil {
+		confirmation.Payment = payment
+		pricedAt = payment.CreatedAt
+	}
+	if payment != nil && payment.Status == model.PaymentSucceeded {
+		items, err := u.paymentRepository.Items(ctx, payment.PaymentID)
+		if err != nil {
+			return nil, err
+		}
+		confirmation.Quote = paidQuote(confirmation.Trip, payment, active, items)
+		return confirmation, nil
+	}
+	if confirmation.Quote, err = u.rules.Price(confirmation.Trip, active, pricedAt); err != nil {
+		return nil, err
+	}
+
+	return confirmation, nil
+}
+
+// paidQuote is the breakdown of what payment charged passengers, from its
+// stored items, as the fare rules may have changed since. Discounts are
+// already shared out over the items, so none are listed.
+func paidQuote(trip *model.Trip, payment *model.Payment, passengers []model.Passenger, items []model.PaymentItem) *model.Quote {
+	travelDate := trip.DepartureAt.UTC()
+	quote := &model.Quote{
+		TripID:     trip.TripID,
+		TravelDate: travelDate.Format(dateLayout),
+		Currency:   payment.Currency,
+		Items:      make([]model.QuoteItem, 0, len(passengers)),
+		Discounts:  []model.QuoteDiscount{},
+	}
+
+	paid := make(map[int]model.PaymentItem, len(items))
+	for _, item := range items {
+		paid[item.PassengerID] = item
+	}
+	for _, passenger := range passengers {
+		item, ok := paid[passenger.PassengerID]
+		if !ok {
+			continue
+		}
+		quoteItem := model.QuoteItem{
+			PassengerID: passenger.PassengerID,
+			FamilyID:    passenger.FamilyID,
+			IsCustomer:  passenger.IsCustomer,
+			Name:        passenger.Name,
+			Dob:         passenger.Dob,
+			Band:        item.Band,
+			Fare:        item.Amount,
+		}
+		if dob, err := time.Parse(dateLayout, passenger.Dob); err == nil {
+			quoteItem.Age = ageOn(dob, travelDate)
+		}
+		quote.Items = append(quote.Items, quoteItem)
+		quote.Subtotal += item.Amount
+	}
+	quote.Total = quote.Subtotal
+	return quote
+}
+
+// passengerNationality is the issuing country of the passenger's passport,
+// or of another of their travel documents, and otherwise the nationality of
+// the customer.
+func passengerNationality(customer *model.UserDetailResponse, documents []*model.TravelDocument, passenger model.Passenger) model.Nationality {
+	var found *model.TravelDocument
+	for _, document := range documents {
+		if document.FamilyID != passenger.FamilyID {
+			continue
+		}
+		if document.Type == model.DocumentPassport {
+			return document.IssuingCountry
+		}
+		if found == nil {
+			found = document
+		}
+	}
+	if found != nil {
+		return found.IssuingCountry
+	}
+	return customer.Nationality
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPassengerNationality(t *testing.T) {
+	indonesia := model.Nationality{NationalityID: 1, NationalityName: "Indonesia", NationalityCode: "ID"}
+	japan := model.Nationality{NationalityID: 2, NationalityName: "Japan", NationalityCode: "JP"}
+	singapore := model.Nationality{NationalityID: 3, NationalityName: "Singapore", NationalityCode: "SG"}
+
+	customer := &model.UserDetailResponse{Nationality: indonesia}
+	documents := []*model.TravelDocument{
+		{FamilyID: 7, Type: model.DocumentResidencePermit, IssuingCountry: singapore},
+		{FamilyID: 7, Type: model.DocumentPassport, IssuingCountry: japan},
+		{FamilyID: 8, Type: model.DocumentNationalID, IssuingCountry: singapore},
+	}
+
+	tests := []struct {
+		name      string
+		passenger model.Passenger
+		want      string
+	}{
+		{name: "customer without documents", passenger: model.Passenger{IsCustomer: true}, want: "ID"},
+		{name: "passport wins over other documents", passenger: model.Passenger{FamilyID: 7}, want: "JP"},
+		{name: "any document without a passport", passenger: model.Passenger{FamilyID: 8}, want: "SG"},
+		{name: "family member without documents", passenger: model.Passenger{FamilyID: 9}, want: "ID"},
+	}
+	for _, tt := range tests {
+		if got := passengerNationality(customer, documents, tt.passenger); got.NationalityCode != tt.want {
+			t.Errorf("%s: nationality = %s, want %s", tt.name, got.NationalityCode, tt.want)
+		}
+	}
+}
+
+// stubBookingRepository serves one booking.
+type stubBookingRepository struct {
+	repository.IBookingRepository
+	booking *model.Booking
+}
+
+func (s stubBookingRepository) GetByID(ctx context.Context, bookingID int) (*model.Booking, error) {
+	if bookingID != s.booking.BookingID {
+		return nil, pgx.ErrNoRows
+	}
+	result := *s.booking
+	return &result, nil
+}
+
+func TestConfirmationUsecasePricing(t *testing.T) {
+	cancelled := time.Now()
+	booking := &model.Booking{BookingID: testBookingID, UserID: 1, TripID: 1, Status: model.BookingConfirmed,
+		Passengers: []model.Passenger{
+			{PassengerID: 1, IsCustomer: true, Name: "Budi Santoso", Dob: "1980-01-01"},
+			{PassengerID: 2, FamilyID: 7, Name: "Sari Santoso", Dob: "2018-01-01", CancelledAt: &cancelled},
+		}}
+	customer := &model.UserDetailResponse{UserID: 1, Name: "Budi Santoso", Dob: "1980-01-01"}
+	// the fare went up since the booking was paid
+	trip := &model.Trip{TripID: 1, TripCode: "JKT-DPS-1", DepartureAt: time.Now().Add(48 * time.Hour), Fare: 2_000_000}
+	paid := &model.Payment{PaymentID: 1, BookingID: testBookingID, Status: model.PaymentSucceeded, Amount: 1_350_000,
+		Currency: "IDR", CreatedAt: time.Now().Add(-24 * time.Hour), Items: []model.PaymentItem{
+			{PassengerID: 1, Band: model.AgeBandAdult, Amount: 900_000},
+			{PassengerID: 2, Band: model.AgeBandChild, Amount: 450_000},
+		}}
+
+	tests := []struct {
+		name      string
+		payment   *model.Payment
+		wantFare  int64
+		wantTotal int64
+	}{
+		{name: "paid booking shows what was charged", payment: paid, wantFare: 900_000, wantTotal: 900_000},
+		{name: "unpaid booking is priced now", wantFare: 2_000_000, wantTotal: 2_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := newStubPayments(nil)
+			if tt.payment != nil {
+				payments.payments[tt.payment.PaymentID] = tt.payment
+			}
+			u := NewConfirmationUsecase(stubBookingRepository{booking: booking}, stubCustomers{customer: customer},
+				stubTrips{trip: trip}, payments, &stubDocuments{}, FareRules{Currency: "IDR", ChildMaxAge: 11, ChildPercent: 50})
+
+			confirmation, err := u.Confirmation(context.Background(), testBookingID)
+			if err != nil {
+				t.Fatalf("Confirmation: %v", err)
+			}
+			if len(confirmation.Passengers) != 2 {
+				t.Errorf("passengers = %+v, want the cancelled one listed too", confirmation.Passengers)
+			}
+			quote := confirmation.Quote
+			if len(quote.Items) != 1 || quote.Items[0].PassengerID != 1 || quote.Items[0].Fare != tt.wantFare {
+				t.Fatalf("quote items = %+v, want passenger 1 at %d", quote.Items, tt.wantFare)
+			}
+			if quote.Total != tt.wantTotal || quote.Currency != "IDR" {
+				t.Fatalf("quote total = %d %s, want %d IDR", quote.Total, quote.Currency, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/secretbox"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+type IDocumentUsecase interface {
+	GetByUser(ctx context.Context, userID int) (documents []*model.TravelDocument, err error)
+	Detail(ctx context.Context, userID, documentID int) (document *model.TravelDocument, err error)
+	Create(ctx context.Context, userID int, request *model.DocumentRequest) (document *model.TravelDocument, err error)
+	Update(ctx context.Context, userID, documentID int, request *model.DocumentRequest) (document *model.TravelDocument, err error)
+	Delete(ctx context.Context, userID, documentID int) (err error)
+}
+
+type DocumentUsecase struct {
+	documentRepository    repository.IDocumentRepository
+	userRepository        repository.IUserRepository
+	nationalityRepository repository.INationalityRepository
+	box                   *secretbox.Box
+}
+
+// NewDocumentUsecase builds the travel document usecase. Document numbers
+// are sealed with box before they reach the repository.
+func NewDocumentUsecase(documentRepository repository.IDocumentRepository, userRepository repository.IUserRepository,
+	nationalityRepository repository.INationalityRepository, box *secretbox.Box) *DocumentUsecase {
+	return &DocumentUsecase{
+		documentRepository:    documentRepository,
+		userRepository:        userRepository,
+		nationalityRepository: nationalityRepository,
+		box:                   box,
+	}
+}
+
+// GetByUser lists the documents of a customer and their family members with
+// masked numbers.
+func (u *DocumentUsecase) GetByUser(ctx context.Context, userID int) (documents []*model.TravelDocument, err error) {
+	ctx, span := tracing.Start(ctx, "DocumentUsecase.GetByUser")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = u.customer(ctx, userID); err != nil {
+		return nil, err
+	}
+	documents, err = u.documentRepository.GetByUser(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Document get all failed: ", err.Error())
+		return nil, err
+	}
+	for _, document := range documents {
+		document.NumberMasked = maskNumber(document.NumberLast4)
+	}
+	return documents, nil
+}
+
+// Detail returns one document with its number decrypted.
+func (u *DocumentUsecase) Detail(ctx context.Context, userID, documentID int) (document *model.TravelDocument, err error) {
+	ctx, span := tracing.Start(ctx, "DocumentUsecase.Detail")
+	defer func() { tracing.End(span, err) }()
+
+	document, err = u.documentRepository.GetByID(ctx, userID, documentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, documentNotFound(documentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := u.box.Open(document.NumberEncrypted, documentAAD(userID))
+	if err != nil {
+		logger.FromContext(ctx).Errorf("Document %d number cannot be decrypted: %v", documentID, err)
+		return nil, err
+	}
+	document.Number = string(number)
+	document.NumberMasked = maskNumber(document.NumberLast4)
+	return document, nil
+}
+
+func (u *DocumentUsecase) Create(ctx context.Context, userID int, request *model.DocumentRequest) (document *model.TravelDocument, err error) {
+	ctx, span := tracing.Start(ctx, "DocumentUsecase.Create")
+	defer func() { tracing.End(span, err) }()
+
+	customer, err := u.customer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if document, err = u.document(ctx, customer, request); err != nil {
+		return nil, err
+	}
+
+	if err = u.documentRepository.Create(ctx, document); err != nil {
+		logger.FromContext(ctx).Error("Document create failed: ", err.Error())
+		return nil, err
+	}
+
+	return u.Detail(ctx, userID, document.DocumentID)
+}
+
+// Update replaces a document. The traveller it belongs to cannot change;
+// family_id in the request is ignored.
+func (u *DocumentUsecase) Update(ctx context.Context, userID, documentID int, request *model.DocumentRequest) (document *model.TravelDocument, err error) {
+	ctx, span := tracing.Start(ctx, "DocumentUsecase.Update")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := u.documentRepository.GetByID(ctx, userID, documentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, documentNotFound(documentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	customer, err := u.customer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	request.FamilyID = current.FamilyID
+	if document, err = u.document(ctx, customer, request); err != nil {
+		return nil, err
+	}
+	document.DocumentID = documentID
+
+	err = u.documentRepository.Update(ctx, document)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, documentNotFound(documentID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Document update failed: ", err.Error())
+		return nil, err
+	}
+
+	return u.Detail(ctx, userID, documentID)
+}
+
+func (u *DocumentUsecase) Delete(ctx context.Context, userID, documentID int) (err error) {
+	ctx, span := tracing.Start(ctx, "DocumentUsecase.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	err = u.documentRepository.Delete(ctx, userID, documentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return documentNotFound(documentID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Document delete failed: ", err.Error())
+	}
+	return
+}
+
+func (u *DocumentUsecase) customer(ctx context.Context, userID int) (*model.UserDetailResponse, error) {
+	customer, err := u.userRepository.GetUserDetail(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("customer %d: %w", userID, model.ErrNotFound)
+	}
+	return customer, err
+}
+
+var documentNumberPattern = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// document validates a request for one of customer's travellers and turns it
+// into a document with the number sealed. Numbers are stored upper case
+// without spaces or dashes.
+func (u *DocumentUsecase) document(ctx context.Context, customer *model.UserDetailResponse, request *model.DocumentRequest) (*model.TravelDocument, error) {
+	request.Number = normaliseDocumentNumber(request.Number)
+
+	err := validation.ValidateStruct(request,
+		validation.Field(&request.FamilyID, validation.Min(0), validation.By(familyOf(customer))),
+		validation.Field(&request.Type, validation.Required,
+			validation.In(model.DocumentPassport, model.DocumentNationalID, model.DocumentResidencePermit).
+				Error("must be passport, national_id or residence_permit")),
+		validation.Field(&request.Number, validation.Required, validation.Length(5, 20),
+			validation.Match(documentNumberPattern).Error("must contain only letters and digits")),
+		validation.Field(&request.IssuingNationalityID, validation.Required),
+		validation.Field(&request.ExpiresOn, validation.Required, validation.By(validateDateFormat),
+			validation.By(notExpired(time.Now()))),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.nationalityRepository.GetByID(ctx, request.IssuingNationalityID); errors.Is(err, pgx.ErrNoRows) {
+		return nil, validation.Errors{"issuing_nationality_id": errors.New("issuing country not found")}
+	} else if err != nil {
+		return nil, err
+	}
+
+	sealed, err := u.box.Seal([]byte(request.Number), documentAAD(customer.UserID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TravelDocument{
+		UserID:               customer.UserID,
+		FamilyID:             request.FamilyID,
+		Type:                 request.Type,
+		IssuingNationalityID: request.IssuingNationalityID,
+		ExpiresOn:            request.ExpiresOn,
+		NumberEncrypted:      sealed,
+		NumberLast4:          request.Number[len(request.Number)-4:],
+	}, nil
+}
+
+// notExpired rejects a YYYY-MM-DD date before the UTC day of now. Malformed
+// dates are left to validateDateFormat.
+func notExpired(now time.Time) validation.RuleFunc {
+	year, month, day := now.UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return func(value interface{}) error {
+		date, _ := value.(string)
+		expiresOn, err := time.Parse(dateLayout, date)
+		if err != nil {
+			return nil
+		}
+		if expiresOn.Before(today) {
+			return validation.NewError("validation_document_expired", "document has expired")
+		}
+		return nil
+	}
+}
+
+// familyOf accepts 0, the customer, or one of the customer's family members.
+func familyOf(customer *model.UserDetailResponse) validation.RuleFunc {
+	return func(value interface{}) error {
+		familyID, _ := value.(int)
+		if familyID == 0 {
+			return nil
+		}
+		for _, family := range customer.Families {
+			if family.FamilyID == familyID {
+				return nil
+			}
+		}
+		return validation.NewError("validation_not_in_family", "family member not found for this customer")
+	}
+}
+
+// documentAAD binds a sealed number to its customer, so it cannot be copied
+// to another customer's row and still decrypt.
+func documentAAD(userID int) []byte {
+	return []byte(fmt.Sprintf("travel_document:%d", userID))
+}
+
+func normaliseDocumentNumber(number string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number)))
+}
+
+func maskNumber(last4 string) string {
+	return "****" + last4
+}
+
+func documentNotFound(documentID int) error {
+	return fmt.Errorf("document %d: %w", documentID, model.ErrNotFound)
+}
+
+// checkTravelDocuments requires every passenger of an international trip to
+// hold a passport that has not expired on the departure date.
+func checkTravelDocuments(trip *model.Trip, passengers []model.Passenger, documents []*model.TravelDocument) error {
+	if !trip.International {
+		return nil
+	}
+	travelDate := trip.DepartureAt.UTC().Format(dateLayout)
+
+	for _, passenger := range passengers {
+		if passenger.CancelledAt != nil {
+			continue
+		}
+		valid := false
+		for _, document := range documents {
+			if document.Type == model.DocumentPassport && document.FamilyID == passenger.FamilyID &&
+				document.ExpiresOn >= travelDate {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%s has no passport valid on %s: %w", passenger.Name, travelDate, model.ErrDocumentRequired)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/secretbox"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestNormaliseDocumentNumber(t *testing.T) {
+	tests := map[string]string{
+		"a1234567":      "A1234567",
+		" x 123-456 7 ": "X1234567",
+		"AB-12-CD":      "AB12CD",
+	}
+	for in, want := range tests {
+		if got := normaliseDocumentNumber(in); got != want {
+			t.Errorf("normaliseDocumentNumber(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckTravelDocuments(t *testing.T) {
+	departure := time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC)
+	cancelled := departure.Add(-48 * time.Hour)
+	passengers := []model.Passenger{
+		{PassengerID: 1, IsCustomer: true, Name: "Budi"},
+		{PassengerID: 2, FamilyID: 7, Name: "Sari"},
+		{PassengerID: 3, FamilyID: 8, Name: "Dodi", CancelledAt: &cancelled},
+	}
+	passport := func(familyID int, expiresOn string) *model.TravelDocument {
+		return &model.TravelDocument{FamilyID: familyID, Type: model.DocumentPassport, ExpiresOn: expiresOn}
+	}
+
+	tests := []struct {
+		name          string
+		international bool
+		documents     []*model.TravelDocument
+		wantErr       bool
+	}{
+		{name: "domestic trip", documents: nil},
+		{name: "everyone has a passport", international: true,
+			documents: []*model.TravelDocument{passport(0, "2030-01-01"), passport(7, "2026-07-01")}},
+		{name: "passport expires the day before", international: true, wantErr: true,
+			documents: []*model.TravelDocument{passport(0, "2030-01-01"), passport(7, "2026-06-30")}},
+		{name: "family member without passport", international: true, wantErr: true,
+			documents: []*model.TravelDocument{passport(0, "2030-01-01")}},
+		{name: "national id is not enough", international: true, wantErr: true,
+			documents: []*model.TravelDocument{
+				passport(7, "2030-01-01"),
+				{Type: model.DocumentNationalID, ExpiresOn: "2030-01-01"},
+			}},
+	}
+	for _, tt := range tests {
+		trip := &model.Trip{DepartureAt: departure, International: tt.international}
+		err := checkTravelDocuments(trip, passengers, tt.documents)
+		if tt.wantErr {
+			if !errors.Is(err, model.ErrDocumentRequired) {
+				t.Errorf("%s: got %v, want ErrDocumentRequired", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+// stubDocuments is an in-memory repository.IDocumentRepository.
+type stubDocuments struct {
+	documents []*model.TravelDocument
+}
+
+func (s *stubDocuments) GetByUser(ctx context.Context, userID int) ([]*model.TravelDocument, error) {
+	documents := []*model.TravelDocument{}
+	for _, document := range s.documents {
+		if document.UserID == userID {
+			stored := *document
+			documents = append(documents, &stored)
+		}
+	}
+	return documents, nil
+}
+
+func (s *stubDocuments) GetByID(ctx context.Context, userID, documentID int) (*model.TravelDocument, error) {
+	for _, document := range s.documents {
+		if document.UserID == userID && document.DocumentID == documentID {
+			stored := *document
+			return &stored, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubDocuments) Create(ctx context.Context, document *model.TravelDocument) error {
+	document.DocumentID = len(s.documents) + 1
+	stored := *document
+	s.documents = append(s.documents, &stored)
+	return nil
+}
+
+func (s *stubDocuments) Update(ctx context.Context, document *model.TravelDocument) error {
+	return nil
+}
+
+func (s *stubDocuments) Delete(ctx context.Context, userID, documentID int) error {
+	return nil
+}
+
+// stubCustomers serves the detail of one customer.
+type stubCustomers struct {
+	repository.IUserRepository
+	customer *model.UserDetailResponse
+}
+
+func (s stubCustomers) GetUserDetail(ctx context.Context, userID int) (*model.UserDetailResponse, error) {
+	if userID != s.customer.UserID {
+		return nil, pgx.ErrNoRows
+	}
+	return s.customer, nil
+}
+
+type stubNationalities struct{}
+
+func (stubNationalities) GetAll(ctx context.Context) ([]model.Nationality, error) {
+	return []model.Nationality{{NationalityID: 1, NationalityName: "Indonesia", NationalityCode: "ID"}}, nil
+}
+
+func (stubNationalities) GetByID(ctx context.Context, nationalityID int) (*model.Nationality, error) {
+	if nationalityID != 1 {
+		return nil, pgx.ErrNoRows
+	}
+	return &model.Nationality{NationalityID: 1, NationalityName: "Indonesia", NationalityCode: "ID"}, nil
+}
+
+func TestDocumentUsecaseCreate(t *testing.T) {
+	box, err := secretbox.New(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer := &model.UserDetailResponse{UserID: 1, Name: "Budi Santoso",
+		Families: []model.Family{{FamilyID: 7, UserID: 1, Name: "Sari Santoso"}}}
+	today := time.Now().UTC().Format(dateLayout)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(dateLayout)
+
+	tests := []struct {
+		name      string
+		request   model.DocumentRequest
+		wantField string
+	}{
+		{name: "passport valid for years",
+			request: model.DocumentRequest{Type: model.DocumentPassport, Number: "a 123-4567", IssuingNationalityID: 1, ExpiresOn: "2099-01-01"}},
+		{name: "family member's document expiring today",
+			request: model.DocumentRequest{FamilyID: 7, Type: model.DocumentNationalID, Number: "X12345", IssuingNationalityID: 1, ExpiresOn: today}},
+		{name: "expired yesterday", wantField: "expires_on",
+			request: model.DocumentRequest{Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: 1, ExpiresOn: yesterday}},
+		{name: "malformed expiry", wantField: "expires_on",
+			request: model.DocumentRequest{Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: 1, ExpiresOn: "01-01-2099"}},
+		{name: "someone else's family member", wantField: "family_id",
+			request: model.DocumentRequest{FamilyID: 8, Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: 1, ExpiresOn: "2099-01-01"}},
+		{name: "unknown issuing country", wantField: "issuing_nationality_id",
+			request: model.DocumentRequest{Type: model.DocumentPassport, Number: "A1234567", IssuingNationalityID: 2, ExpiresOn: "2099-01-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents := &stubDocuments{}
+			u := NewDocumentUsecase(documents, stubCustomers{customer: customer}, stubNationalities{}, box)
+
+			document, err := u.Create(context.Background(), customer.UserID, &tt.request)
+			if tt.wantField != "" {
+				var errs validation.Errors
+				if !errors.As(err, &errs) || errs[tt.wantField] == nil {
+					t.Fatalf("Create err = %v, want a validation error on %s", err, tt.wantField)
+				}
+				if len(documents.documents) != 0 {
+					t.Fatalf("invalid document stored: %+v", documents.documents[0])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if document.Number != tt.request.Number || document.ExpiresOn != tt.request.ExpiresOn {
+				t.Fatalf("document = %+v", document)
+			}
+			if stored := documents.documents[0]; bytes.Contains(stored.NumberEncrypted, []byte(tt.request.Number)) {
+				t.Fatal("number stored in clear")
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+)
+
+type INationalityUsecase interface {
+	GetAll(ctx context.Context) (nationalities []model.Nationality, err error)
+}
+
+type NationalityUsecase struct {
+	nationalityRepository repository.INationalityRepository
+}
+
+func NewNationalityUsecase(nationalityRepository repository.INationalityRepository) *NationalityUsecase {
+	return &NationalityUsecase{
+		nationalityRepository: nationalityRepository,
+	}
+}
+
+func (u *NationalityUsecase) GetAll(ctx context.Context) (nationalities []model.Nationality, err error) {
+	ctx, span := tracing.Start(ctx, "NationalityUsecase.GetAll")
+	defer func() { tracing.End(span, err) }()
+
+	nationalities, err = u.nationalityRepository.GetAll(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Nationality get all failed: ", err.Error())
+		return
+	}
+	return
+}
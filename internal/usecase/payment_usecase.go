@@ -0,0 +1,496 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	paymentGateway "booking_togo/internal/payment"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+type IPaymentUsecase interface {
+	GetByBooking(ctx context.Context, bookingID int) (payments []*model.Payment, err error)
+	Detail(ctx context.Context, paymentID int) (payment *model.Payment, err error)
+	Pay(ctx context.Context, bookingID int) (payment *model.Payment, err error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (err error)
+	CanSimulate() bool
+	Simulate(ctx context.Context, paymentID int, status model.PaymentStatus) (payment *model.Payment, err error)
+	IssueRefund(ctx context.Context, refund *model.Refund) (err error)
+}
+
+type PaymentUsecase struct {
+	paymentRepository repository.IPaymentRepository
+	tripRepository    repository.ITripRepository
+	bookings          IBookingUsecase
+	gateway           paymentGateway.Gateway
+	rules             FareRules
+}
+
+// NewPaymentUsecase builds the payment usecase. Amounts due are priced with
+// rules, and paid bookings are confirmed through bookings so the state
+// machine and its history apply.
+func NewPaymentUsecase(paymentRepository repository.IPaymentRepository, tripRepository repository.ITripRepository,
+	bookings IBookingUsecase, gateway paymentGateway.Gateway, rules FareRules) *PaymentUsecase {
+	return &PaymentUsecase{
+		paymentRepository: paymentRepository,
+		tripRepository:    tripRepository,
+		bookings:          bookings,
+		gateway:           gateway,
+		rules:             rules,
+	}
+}
+
+// freeGateway marks payments of bookings with nothing to pay, which are
+// settled without a gateway.
+const freeGateway = "none"
+
+func (u *PaymentUsecase) GetByBooking(ctx context.Context, bookingID int) (payments []*model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.GetByBooking")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = u.bookings.Detail(ctx, bookingID); err != nil {
+		return nil, err
+	}
+	return u.paymentRepository.GetByBooking(ctx, bookingID)
+}
+
+func (u *PaymentUsecase) Detail(ctx context.Context, paymentID int) (payment *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.Detail")
+	defer func() { tracing.End(span, err) }()
+
+	payment, err = u.paymentRepository.GetByID(ctx, paymentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("payment %d: %w", paymentID, model.ErrNotFound)
+	}
+	return
+}
+
+// Pay starts paying a held booking: it prices the passengers and creates a
+// payment intent for the total, or returns the one already in progress. A
+// booking with nothing to pay is paid and confirmed straight away.
+func (u *PaymentUsecase) Pay(ctx context.Context, bookingID int) (payment *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.Pay")
+	defer func() { tracing.End(span, err) }()
+
+	booking, err := u.bookings.Detail(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if isPaidStatus(booking.Status) {
+		return nil, model.ErrAlreadyPaid
+	}
+	if booking.Status != model.BookingHeld {
+		return nil, fmt.Errorf("booking %d is %s: %w", bookingID, booking.Status, model.ErrNotPayable)
+	}
+
+	trip, err := u.tripRepository.GetByID(ctx, booking.TripID)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := u.rules.Price(trip, booking.Passengers, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if payment, err = u.current(ctx, bookingID, quote); payment != nil || err != nil {
+		return payment, err
+	}
+
+	attempts, err := u.paymentRepository.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment = &model.Payment{
+		BookingID: bookingID,
+		Amount:    quote.Total,
+		Currency:  quote.Currency,
+		Items:     paymentItems(quote),
+	}
+	if quote.Total == 0 {
+		payment.Gateway = freeGateway
+		payment.IntentID = fmt.Sprintf("free-%d-%d", bookingID, len(attempts)+1)
+		payment.Status = model.PaymentSucceeded
+	} else {
+		intent, err := u.gateway.CreateIntent(ctx, paymentGateway.IntentRequest{
+			Amount:    quote.Total,
+			Currency:  quote.Currency,
+			Reference: fmt.Sprintf("booking-%d-%d", bookingID, len(attempts)+1),
+		})
+		if err != nil {
+			logger.FromContext(ctx).Error("Payment intent create failed: ", err.Error())
+			return nil, err
+		}
+		payment.Gateway = u.gateway.Name()
+		payment.IntentID = intent.ID
+		payment.ClientSecret = intent.ClientSecret
+		payment.Status = model.PaymentPending
+	}
+
+	if err = u.paymentRepository.Create(ctx, payment); err != nil {
+		logger.FromContext(ctx).Error("Payment create failed: ", err.Error())
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Infof("Payment %d of %d %s started for booking %d", payment.PaymentID, payment.Amount, payment.Currency, bookingID)
+
+	if payment.Status == model.PaymentSucceeded {
+		if err = u.reconcile(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+	return payment, nil
+}
+
+// current returns the payment in progress for a booking with the client
+// secret to complete it, or nil when a new one is needed. A pending payment
+// for a different amount, because passengers changed since, or whose intent
+// the gateway no longer knows, is cancelled.
+func (u *PaymentUsecase) current(ctx context.Context, bookingID int, quote *model.Quote) (*model.Payment, error) {
+	payment, err := u.paymentRepository.Current(ctx, bookingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == model.PaymentSucceeded {
+		return nil, model.ErrAlreadyPaid
+	}
+
+	if payment.Amount == quote.Total && payment.Currency == quote.Currency {
+		intent, err := u.gateway.GetIntent(ctx, payment.IntentID)
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, u.abandon(ctx, payment)
+		}
+		if err != nil {
+			return nil, err
+		}
+		payment.ClientSecret = intent.ClientSecret
+		return payment, nil
+	}
+
+	if err := u.gateway.CancelIntent(ctx, payment.IntentID); err != nil && !errors.Is(err, model.ErrNotFound) {
+		return nil, err
+	}
+	return nil, u.cancelPending(ctx, payment)
+}
+
+// abandon cancels a pending payment whose intent the gateway does not know,
+// e.g. a development gateway that restarted. Nothing can settle it anymore.
+func (u *PaymentUsecase) abandon(ctx context.Context, payment *model.Payment) error {
+	logger.FromContext(ctx).Warnf("Payment %d: gateway %s no longer knows intent %s, cancelling it",
+		payment.PaymentID, payment.Gateway, payment.IntentID)
+	return u.cancelPending(ctx, payment)
+}
+
+// cancelPending cancels a payment unless it was settled meanwhile.
+func (u *PaymentUsecase) cancelPending(ctx context.Context, payment *model.Payment) error {
+	err := u.paymentRepository.UpdateStatus(ctx, payment.PaymentID, model.PaymentPending, model.PaymentCancelled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// HandleWebhook applies a signed gateway notification and reconciles the
+// booking it pays for. Redelivered events are acknowledged and ignored.
+func (u *PaymentUsecase) HandleWebhook(ctx context.Context, payload []byte, signature string) (err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.HandleWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	event, err := u.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Payment webhook rejected: ", err.Error())
+		return err
+	}
+	return u.applyEvent(ctx, event)
+}
+
+func (u *PaymentUsecase) applyEvent(ctx context.Context, event *model.PaymentEvent) error {
+	switch event.Status {
+	case model.PaymentSucceeded, model.PaymentFailed, model.PaymentCancelled:
+	default:
+		logger.FromContext(ctx).Infof("Ignoring payment event %s with status %s", event.EventID, event.Status)
+		return nil
+	}
+
+	payment, err := u.paymentRepository.GetByIntent(ctx, u.gateway.Name(), event.IntentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("payment intent %s: %w", event.IntentID, model.ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if event.Status == model.PaymentSucceeded && (event.Amount != payment.Amount || event.Currency != payment.Currency) {
+		return fmt.Errorf("payment %d: gateway reports %d %s paid, %d %s expected: %w",
+			payment.PaymentID, event.Amount, event.Currency, payment.Amount, payment.Currency, model.ErrConflict)
+	}
+
+	applied, refund, err := u.paymentRepository.ApplyEvent(ctx, payment, event)
+	if err != nil || !applied {
+		return err
+	}
+	if refund != nil {
+		// the money came in on a payment given up already
+		logger.FromContext(ctx).Warnf("Payment %d of booking %d was %s but got paid, refunding it",
+			payment.PaymentID, payment.BookingID, payment.Status)
+		return u.IssueRefund(ctx, refund)
+	}
+
+	if payment, err = u.paymentRepository.GetByID(ctx, payment.PaymentID); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Infof("Payment %d of booking %d is %s", payment.PaymentID, payment.BookingID, payment.Status)
+
+	return u.reconcile(ctx, payment)
+}
+
+// reconcile brings a booking in line with its payment. A succeeded payment
+// confirms a held booking; one that arrives after the hold expired holds the
+// seats again if they are still free. A payment for a booking that can no
+// longer be confirmed is refunded.
+func (u *PaymentUsecase) reconcile(ctx context.Context, payment *model.Payment) error {
+	if payment.Status != model.PaymentSucceeded {
+		return nil
+	}
+
+	booking, err := u.bookings.Detail(ctx, payment.BookingID)
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("payment %d received", payment.PaymentID)
+
+	switch booking.Status {
+	case model.BookingHeld:
+		_, err = u.bookings.Transition(ctx, booking.BookingID, model.BookingConfirmed, reason)
+		if !errors.Is(err, model.ErrHoldExpired) {
+			return ignoreStatusChanged(err)
+		}
+		// the hold lapsed before the reaper released it
+		if _, err = u.bookings.Transition(ctx, booking.BookingID, model.BookingDraft, "hold expired"); err != nil {
+			return ignoreStatusChanged(err)
+		}
+		fallthrough
+	case model.BookingDraft:
+		if _, err = u.bookings.Transition(ctx, booking.BookingID, model.BookingHeld, reason+" after the hold expired"); err != nil {
+			if errors.Is(err, model.ErrConflict) {
+				return u.refund(ctx, payment, err.Error())
+			}
+			return err
+		}
+		_, err = u.bookings.Transition(ctx, booking.BookingID, model.BookingConfirmed, reason)
+		return ignoreStatusChanged(err)
+	case model.BookingCancelled, model.BookingRefunded:
+		return u.refund(ctx, payment, "booking is "+string(booking.Status))
+	default:
+		return nil
+	}
+}
+
+// ignoreStatusChanged drops model.ErrStatusChanged: the booking moved
+// concurrently and the next reconciliation run looks at it again.
+func ignoreStatusChanged(err error) error {
+	if errors.Is(err, model.ErrStatusChanged) {
+		return nil
+	}
+	return err
+}
+
+// refund returns the whole of a payment that can no longer be used.
+func (u *PaymentUsecase) refund(ctx context.Context, payment *model.Payment, reason string) error {
+	if payment.Amount == 0 {
+		return u.paymentRepository.UpdateStatus(ctx, payment.PaymentID, model.PaymentSucceeded, model.PaymentRefunded)
+	}
+
+	refund := &model.Refund{
+		PaymentID: payment.PaymentID,
+		BookingID: payment.BookingID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Reason:    reason,
+	}
+	if err := u.paymentRepository.CreateRefund(ctx, refund); err != nil {
+		return err
+	}
+	return u.IssueRefund(ctx, refund)
+}
+
+// IssueRefund has the gateway pay out a recorded refund and marks it
+// succeeded. It is safe to retry: the refund ID is the gateway's idempotency
+// key. A cancelled booking whose payment was refunded becomes refunded; a
+// refund of a payment that was cancelled before it got paid leaves the
+// booking alone.
+func (u *PaymentUsecase) IssueRefund(ctx context.Context, refund *model.Refund) (err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.IssueRefund")
+	defer func() { tracing.End(span, err) }()
+
+	payment, err := u.paymentRepository.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		return err
+	}
+	gatewayRefundID, err := u.gateway.Refund(ctx, payment.IntentID, refund.Amount, fmt.Sprintf("refund-%d", refund.RefundID))
+	if err != nil {
+		return fmt.Errorf("refund %d of payment %d: %w", refund.RefundID, payment.PaymentID, err)
+	}
+
+	err = u.paymentRepository.CompleteRefund(ctx, refund.RefundID, gatewayRefundID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// completed by a concurrent retry
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	refund.Status = model.RefundSucceeded
+	refund.GatewayRefundID = gatewayRefundID
+	logger.FromContext(ctx).Warnf("Refund %d of %d %s for booking %d issued: %s",
+		refund.RefundID, refund.Amount, refund.Currency, refund.BookingID, refund.Reason)
+
+	booking, err := u.bookings.Detail(ctx, payment.BookingID)
+	if err != nil {
+		return err
+	}
+	if booking.Status == model.BookingCancelled && payment.Status == model.PaymentSucceeded {
+		reason := fmt.Sprintf("refund %d issued", refund.RefundID)
+		_, err = u.bookings.MarkRefunded(ctx, booking.BookingID, reason)
+		return ignoreStatusChanged(err)
+	}
+	return nil
+}
+
+// Reconcile settles payments whose webhook never arrived by asking the
+// gateway, confirms or refunds paid bookings that are still held or were
+// released, and retries refunds the gateway has not accepted yet. Failures
+// are logged and retried on the next run.
+func (u *PaymentUsecase) Reconcile(ctx context.Context, pendingFor time.Duration) (reconciled int, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.Reconcile")
+	defer func() { tracing.End(span, err) }()
+
+	payments, err := u.paymentRepository.ToReconcile(ctx, pendingFor, reconcileBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, payment := range payments {
+		if payment.Status == model.PaymentPending {
+			err = u.poll(ctx, payment)
+		} else {
+			err = u.reconcile(ctx, payment)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Errorf("Reconciling payment %d failed: %v", payment.PaymentID, err)
+			continue
+		}
+		reconciled++
+	}
+
+	refunds, err := u.paymentRepository.PendingRefunds(ctx, reconcileBatch)
+	if err != nil {
+		return reconciled, err
+	}
+	for _, refund := range refunds {
+		if err = u.IssueRefund(ctx, refund); err != nil {
+			logger.FromContext(ctx).Errorf("Issuing refund %d failed: %v", refund.RefundID, err)
+			continue
+		}
+		reconciled++
+	}
+
+	return reconciled, nil
+}
+
+// reconcileBatch caps the payments looked at per reconciliation run.
+const reconcileBatch = 100
+
+// poll applies the gateway's current status of a pending payment as if its
+// webhook had arrived. A payment the gateway does not know is cancelled.
+func (u *PaymentUsecase) poll(ctx context.Context, payment *model.Payment) error {
+	intent, err := u.gateway.GetIntent(ctx, payment.IntentID)
+	if errors.Is(err, model.ErrNotFound) {
+		return u.abandon(ctx, payment)
+	}
+	if err != nil {
+		return err
+	}
+	if intent.Status == model.PaymentPending {
+		return nil
+	}
+
+	return u.applyEvent(ctx, &model.PaymentEvent{
+		EventID:  fmt.Sprintf("poll-%s-%s", intent.ID, intent.Status),
+		IntentID: intent.ID,
+		Status:   intent.Status,
+		Amount:   intent.Amount,
+		Currency: intent.Currency,
+		Created:  time.Now().UTC(),
+	})
+}
+
+// WatchPayments runs Reconcile every interval until ctx is done.
+func (u *PaymentUsecase) WatchPayments(ctx context.Context, interval, pendingFor time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reconciled, err := u.Reconcile(ctx, pendingFor)
+			if err != nil {
+				logger.FromContext(ctx).Error("Payment reconciliation failed: ", err)
+				continue
+			}
+			if reconciled > 0 {
+				logger.FromContext(ctx).Infof("Reconciled %d payments", reconciled)
+			}
+		}
+	}
+}
+
+// CanSimulate reports whether the gateway can settle payments on request,
+// which only development gateways do.
+func (u *PaymentUsecase) CanSimulate() bool {
+	_, ok := u.gateway.(paymentGateway.Simulator)
+	return ok
+}
+
+// Simulate has a development gateway settle a pending payment and delivers
+// the resulting webhook.
+func (u *PaymentUsecase) Simulate(ctx context.Context, paymentID int, status model.PaymentStatus) (payment *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUsecase.Simulate")
+	defer func() { tracing.End(span, err) }()
+
+	simulator, ok := u.gateway.(paymentGateway.Simulator)
+	if !ok {
+		return nil, fmt.Errorf("payment gateway %s cannot simulate payments: %w", u.gateway.Name(), model.ErrNotFound)
+	}
+	if err = validation.Validate(status, validation.In(model.PaymentSucceeded, model.PaymentFailed)); err != nil {
+		return nil, validation.Errors{"status": err}
+	}
+
+	payment, err = u.Detail(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != model.PaymentPending {
+		return nil, fmt.Errorf("payment %d is %s: %w", paymentID, payment.Status, model.ErrConflict)
+	}
+
+	payload, signature, err := simulator.Settle(ctx, payment.IntentID, status)
+	if err != nil {
+		return nil, err
+	}
+	if err = u.HandleWebhook(ctx, payload, signature); err != nil {
+		return nil, err
+	}
+	return u.paymentRepository.GetByID(ctx, paymentID)
+}
@@ -0,0 +1,649 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	paymentGateway "booking_togo/internal/payment"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// stubPayments is an in-memory repository.IPaymentRepository that follows the
+// SQL one: at most one pending or succeeded payment per booking, each event
+// applied once and only to a pending payment, and a refund recorded for one
+// paid after it was cancelled or failed.
+type stubPayments struct {
+	bookings *stubBookings
+
+	mu       sync.Mutex
+	payments map[int]*model.Payment
+	events   map[string]bool
+	refunds  []*model.Refund
+}
+
+func newStubPayments(bookings *stubBookings) *stubPayments {
+	return &stubPayments{bookings: bookings, payments: map[int]*model.Payment{}, events: map[string]bool{}}
+}
+
+func copyPayment(payment *model.Payment) *model.Payment {
+	result := *payment
+	result.ClientSecret = ""
+	return &result
+}
+
+func (s *stubPayments) GetByID(ctx context.Context, paymentID int) (*model.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if payment, ok := s.payments[paymentID]; ok {
+		return copyPayment(payment), nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubPayments) GetByIntent(ctx context.Context, gateway, intentID string) (*model.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, payment := range s.payments {
+		if payment.Gateway == gateway && payment.IntentID == intentID {
+			return copyPayment(payment), nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubPayments) GetByBooking(ctx context.Context, bookingID int) ([]*model.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payments := []*model.Payment{}
+	for _, payment := range s.sorted() {
+		if payment.BookingID == bookingID {
+			payments = append(payments, copyPayment(payment))
+		}
+	}
+	return payments, nil
+}
+
+func (s *stubPayments) sorted() []*model.Payment {
+	payments := make([]*model.Payment, 0, len(s.payments))
+	for _, payment := range s.payments {
+		payments = append(payments, payment)
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].PaymentID < payments[j].PaymentID })
+	return payments
+}
+
+func (s *stubPayments) current(bookingID int) *model.Payment {
+	for _, payment := range s.payments {
+		if payment.BookingID == bookingID &&
+			(payment.Status == model.PaymentPending || payment.Status == model.PaymentSucceeded) {
+			return payment
+		}
+	}
+	return nil
+}
+
+func (s *stubPayments) Current(ctx context.Context, bookingID int) (*model.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if payment := s.current(bookingID); payment != nil {
+		return copyPayment(payment), nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *stubPayments) Create(ctx context.Context, payment *model.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current(payment.BookingID) != nil {
+		return fmt.Errorf("booking %d already has a payment in progress: %w", payment.BookingID, model.ErrConflict)
+	}
+	for _, stored := range s.payments {
+		if stored.Gateway == payment.Gateway && stored.IntentID == payment.IntentID {
+			return fmt.Errorf("duplicate intent %s", payment.IntentID)
+		}
+	}
+	payment.PaymentID = len(s.payments) + 1
+	payment.CreatedAt = time.Now().Add(-time.Hour)
+	s.payments[payment.PaymentID] = copyPayment(payment)
+	return nil
+}
+
+func (s *stubPayments) UpdateStatus(ctx context.Context, paymentID int, from, to model.PaymentStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payment, ok := s.payments[paymentID]
+	if !ok || payment.Status != from {
+		return pgx.ErrNoRows
+	}
+	payment.Status = to
+	return nil
+}
+
+func (s *stubPayments) ApplyEvent(ctx context.Context, payment *model.Payment, event *model.PaymentEvent) (bool, *model.Refund, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := payment.Gateway + "/" + event.EventID
+	if s.events[key] {
+		return false, nil, nil
+	}
+	s.events[key] = true
+
+	stored := s.payments[payment.PaymentID]
+	switch {
+	case stored.Status == model.PaymentPending:
+		stored.Status = event.Status
+	case event.Status == model.PaymentSucceeded && stored.Amount > 0 && !s.refunded(stored.PaymentID) &&
+		(stored.Status == model.PaymentCancelled || stored.Status == model.PaymentFailed):
+		refund := &model.Refund{
+			RefundID:  len(s.refunds) + 1,
+			PaymentID: stored.PaymentID,
+			BookingID: stored.BookingID,
+			Amount:    stored.Amount,
+			Currency:  stored.Currency,
+			Status:    model.RefundPending,
+			Reason:    fmt.Sprintf("paid after the payment was %s", stored.Status),
+		}
+		recorded := *refund
+		s.refunds = append(s.refunds, &recorded)
+		return true, refund, nil
+	}
+	return true, nil, nil
+}
+
+func (s *stubPayments) ToReconcile(ctx context.Context, pendingFor time.Duration, limit int) ([]*model.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payments := []*model.Payment{}
+	for _, payment := range s.sorted() {
+		switch payment.Status {
+		case model.PaymentPending:
+			if payment.CreatedAt.Before(time.Now().Add(-pendingFor)) {
+				payments = append(payments, copyPayment(payment))
+			}
+		case model.PaymentSucceeded:
+			if s.bookings.unconfirmed(payment.BookingID) && !s.refunded(payment.PaymentID) {
+				payments = append(payments, copyPayment(payment))
+			}
+		}
+	}
+	return payments, nil
+}
+
+func (s *stubPayments) refunded(paymentID int) bool {
+	for _, refund := range s.refunds {
+		if refund.PaymentID == paymentID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stubPayments) Items(ctx context.Context, paymentID int) ([]model.PaymentItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if payment, ok := s.payments[paymentID]; ok {
+		return payment.Items, nil
+	}
+	return []model.PaymentItem{}, nil
+}
+
+func (s *stubPayments) CreateRefund(ctx context.Context, refund *model.Refund) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refund.RefundID = len(s.refunds) + 1
+	refund.Status = model.RefundPending
+	stored := *refund
+	s.refunds = append(s.refunds, &stored)
+	return nil
+}
+
+func (s *stubPayments) CompleteRefund(ctx context.Context, refundID int, gatewayRefundID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refund := s.refunds[refundID-1]
+	if refund.Status != model.RefundPending {
+		return pgx.ErrNoRows
+	}
+	refund.Status = model.RefundSucceeded
+	refund.GatewayRefundID = gatewayRefundID
+
+	var refunded int64
+	for _, r := range s.refunds {
+		if r.PaymentID == refund.PaymentID && r.Status == model.RefundSucceeded {
+			refunded += r.Amount
+		}
+	}
+	if payment := s.payments[refund.PaymentID]; payment.Status == model.PaymentSucceeded && payment.Amount <= refunded {
+		payment.Status = model.PaymentRefunded
+	}
+	return nil
+}
+
+func (s *stubPayments) RefundsByBooking(ctx context.Context, bookingID int) ([]*model.Refund, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refunds := []*model.Refund{}
+	for _, refund := range s.refunds {
+		if refund.BookingID == bookingID {
+			stored := *refund
+			refunds = append(refunds, &stored)
+		}
+	}
+	return refunds, nil
+}
+
+func (s *stubPayments) PendingRefunds(ctx context.Context, limit int) ([]*model.Refund, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refunds := []*model.Refund{}
+	for _, refund := range s.refunds {
+		if refund.Status == model.RefundPending {
+			stored := *refund
+			refunds = append(refunds, &stored)
+		}
+	}
+	return refunds, nil
+}
+
+// stubBookings is the part of the booking usecase payments rely on: details
+// and state machine transitions. holdErr fails every hold, as a trip without
+// free seats would.
+type stubBookings struct {
+	IBookingUsecase
+
+	mu       sync.Mutex
+	bookings map[int]*model.Booking
+	holdErr  error
+}
+
+func (s *stubBookings) add(booking model.Booking) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bookings == nil {
+		s.bookings = map[int]*model.Booking{}
+	}
+	s.bookings[booking.BookingID] = &booking
+}
+
+func (s *stubBookings) status(bookingID int) model.BookingStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bookings[bookingID].Status
+}
+
+// unconfirmed reports whether a booking is held, in draft, or was cancelled
+// before it was ever confirmed.
+func (s *stubBookings) unconfirmed(bookingID int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	booking := s.bookings[bookingID]
+	switch booking.Status {
+	case model.BookingHeld, model.BookingDraft:
+		return true
+	case model.BookingCancelled:
+		return booking.ConfirmedAt == nil
+	}
+	return false
+}
+
+func (s *stubBookings) Detail(ctx context.Context, bookingID int) (*model.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	booking, ok := s.bookings[bookingID]
+	if !ok {
+		return nil, bookingNotFound(bookingID)
+	}
+	result := *booking
+	return &result, nil
+}
+
+func (s *stubBookings) Transition(ctx context.Context, bookingID int, to model.BookingStatus, reason string) (*model.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	booking := s.bookings[bookingID]
+	if err := checkTransition(booking.Status, to); err != nil {
+		return nil, err
+	}
+	if to == model.BookingRefunded {
+		return nil, model.ErrRefundRequired
+	}
+	if to == model.BookingHeld && s.holdErr != nil {
+		return nil, s.holdErr
+	}
+	if to == model.BookingConfirmed {
+		now := time.Now()
+		booking.ConfirmedAt = &now
+	}
+	booking.Status = to
+	result := *booking
+	return &result, nil
+}
+
+func (s *stubBookings) MarkRefunded(ctx context.Context, bookingID int, reason string) (*model.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	booking := s.bookings[bookingID]
+	if err := checkTransition(booking.Status, model.BookingRefunded); err != nil {
+		return nil, err
+	}
+	booking.Status = model.BookingRefunded
+	result := *booking
+	return &result, nil
+}
+
+type stubTrips struct {
+	trip *model.Trip
+}
+
+func (s stubTrips) GetAll(ctx context.Context) ([]*model.Trip, error) {
+	return []*model.Trip{s.trip}, nil
+}
+
+func (s stubTrips) GetByID(ctx context.Context, tripID int) (*model.Trip, error) {
+	if tripID != s.trip.TripID {
+		return nil, pgx.ErrNoRows
+	}
+	return s.trip, nil
+}
+
+func (s stubTrips) Create(ctx context.Context, trip *model.Trip) error {
+	return nil
+}
+
+type paymentFixture struct {
+	bookings *stubBookings
+	payments *stubPayments
+	gateway  *paymentGateway.FakeGateway
+	usecase  *PaymentUsecase
+}
+
+const testBookingID = 1
+
+// newPaymentFixture has booking 1 held for one adult on a trip costing
+// 1,000,000 IDR.
+func newPaymentFixture(t *testing.T) *paymentFixture {
+	t.Helper()
+	bookings := &stubBookings{}
+	bookings.add(model.Booking{BookingID: testBookingID, UserID: 1, TripID: 1, Status: model.BookingHeld,
+		Passengers: []model.Passenger{{PassengerID: 1, IsCustomer: true, Name: "Budi Santoso", Dob: "1980-01-01"}}})
+
+	f := &paymentFixture{bookings: bookings, payments: newStubPayments(bookings)}
+	f.restartGateway()
+	return f
+}
+
+// restartGateway replaces the gateway by a new one, which has forgotten
+// every intent, as the fake gateway does on a server restart.
+func (f *paymentFixture) restartGateway() {
+	trips := stubTrips{trip: &model.Trip{TripID: 1, TripCode: "JKT-DPS-1", DepartureAt: time.Now().Add(48 * time.Hour), Fare: 1_000_000}}
+	f.gateway = paymentGateway.NewFakeGateway("whsec_test", time.Minute)
+	f.usecase = NewPaymentUsecase(f.payments, trips, f.bookings, f.gateway, FareRules{Currency: "IDR"})
+}
+
+func (f *paymentFixture) paymentStatus(t *testing.T, paymentID int) model.PaymentStatus {
+	t.Helper()
+	payment, err := f.payments.GetByID(context.Background(), paymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payment.Status
+}
+
+func TestPaymentUsecasePayAfterGatewayRestart(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+
+	first, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if first.Status != model.PaymentPending || first.Amount != 1_000_000 || first.ClientSecret == "" {
+		t.Fatalf("payment = %+v", first)
+	}
+	again, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil || again.PaymentID != first.PaymentID || again.ClientSecret != first.ClientSecret {
+		t.Fatalf("second Pay = %+v, %v, want the payment in progress", again, err)
+	}
+
+	f.restartGateway()
+	second, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatalf("Pay after a restart: %v", err)
+	}
+	if second.PaymentID == first.PaymentID || second.IntentID == first.IntentID {
+		t.Fatalf("Pay after a restart reused payment %d with intent %s", second.PaymentID, second.IntentID)
+	}
+	if status := f.paymentStatus(t, first.PaymentID); status != model.PaymentCancelled {
+		t.Fatalf("forgotten payment is %s, want cancelled", status)
+	}
+}
+
+func TestPaymentUsecaseReconcileForgottenIntent(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+
+	payment, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.restartGateway()
+	reconciled, err := f.usecase.Reconcile(ctx, time.Minute)
+	if err != nil || reconciled != 1 {
+		t.Fatalf("Reconcile = %d, %v, want 1 payment reconciled", reconciled, err)
+	}
+	if status := f.paymentStatus(t, payment.PaymentID); status != model.PaymentCancelled {
+		t.Fatalf("forgotten payment is %s, want cancelled", status)
+	}
+	if reconciled, err = f.usecase.Reconcile(ctx, time.Minute); err != nil || reconciled != 0 {
+		t.Fatalf("second Reconcile = %d, %v, want nothing left to do", reconciled, err)
+	}
+}
+
+func (f *paymentFixture) refunds(t *testing.T) []*model.Refund {
+	t.Helper()
+	refunds, err := f.payments.RefundsByBooking(context.Background(), testBookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return refunds
+}
+
+// settle pays the intent of a payment at the gateway and returns its webhook
+// delivery.
+func (f *paymentFixture) settle(t *testing.T, payment *model.Payment, status model.PaymentStatus) ([]byte, string) {
+	t.Helper()
+	payload, signature, err := f.gateway.Settle(context.Background(), payment.IntentID, status)
+	if err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+	return payload, signature
+}
+
+func TestPaymentUsecaseWebhookConfirmsOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+
+	payment, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature := f.settle(t, payment, model.PaymentSucceeded)
+	for range 2 {
+		if err := f.usecase.HandleWebhook(ctx, payload, signature); err != nil {
+			t.Fatalf("HandleWebhook: %v", err)
+		}
+	}
+
+	if status := f.paymentStatus(t, payment.PaymentID); status != model.PaymentSucceeded {
+		t.Fatalf("payment is %s, want succeeded", status)
+	}
+	if status := f.bookings.status(testBookingID); status != model.BookingConfirmed {
+		t.Fatalf("booking is %s, want confirmed", status)
+	}
+	if refunds := f.refunds(t); len(refunds) != 0 {
+		t.Fatalf("refunds = %+v, want none", refunds)
+	}
+	if _, err := f.usecase.Pay(ctx, testBookingID); !errors.Is(err, model.ErrAlreadyPaid) {
+		t.Fatalf("Pay after payment: err = %v, want ErrAlreadyPaid", err)
+	}
+}
+
+func TestPaymentUsecaseRefundsPaymentAfterCancel(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+
+	payment, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature := f.settle(t, payment, model.PaymentSucceeded)
+	// the payment is given up while the customer completes it
+	if err := f.payments.UpdateStatus(ctx, payment.PaymentID, model.PaymentPending, model.PaymentCancelled); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := f.usecase.HandleWebhook(ctx, payload, signature); err != nil {
+			t.Fatalf("HandleWebhook: %v", err)
+		}
+	}
+
+	refunds := f.refunds(t)
+	if len(refunds) != 1 || refunds[0].Status != model.RefundSucceeded || refunds[0].Amount != payment.Amount {
+		t.Fatalf("refunds = %+v, want one succeeded refund of %d", refunds, payment.Amount)
+	}
+	if status := f.paymentStatus(t, payment.PaymentID); status != model.PaymentCancelled {
+		t.Fatalf("payment is %s, want cancelled", status)
+	}
+	if status := f.bookings.status(testBookingID); status != model.BookingHeld {
+		t.Fatalf("booking is %s, want it still held", status)
+	}
+}
+
+func TestPaymentUsecaseReconcileLatePayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		booking     model.BookingStatus
+		holdErr     error
+		wantBooking model.BookingStatus
+		wantPayment model.PaymentStatus
+	}{
+		{name: "held booking is confirmed", booking: model.BookingHeld,
+			wantBooking: model.BookingConfirmed, wantPayment: model.PaymentSucceeded},
+		{name: "released booking with free seats is confirmed", booking: model.BookingDraft,
+			wantBooking: model.BookingConfirmed, wantPayment: model.PaymentSucceeded},
+		{name: "released booking without free seats is refunded", booking: model.BookingDraft,
+			holdErr:     fmt.Errorf("no seats left: %w", model.ErrConflict),
+			wantBooking: model.BookingDraft, wantPayment: model.PaymentRefunded},
+		{name: "cancelled booking is refunded", booking: model.BookingCancelled,
+			wantBooking: model.BookingRefunded, wantPayment: model.PaymentRefunded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newPaymentFixture(t)
+
+			payment, err := f.usecase.Pay(ctx, testBookingID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.settle(t, payment, model.PaymentSucceeded)
+			f.bookings.bookings[testBookingID].Status = tt.booking
+			f.bookings.holdErr = tt.holdErr
+
+			// the webhook never arrives
+			if reconciled, err := f.usecase.Reconcile(ctx, time.Minute); err != nil || reconciled != 1 {
+				t.Fatalf("Reconcile = %d, %v, want 1 payment reconciled", reconciled, err)
+			}
+			if status := f.bookings.status(testBookingID); status != tt.wantBooking {
+				t.Errorf("booking is %s, want %s", status, tt.wantBooking)
+			}
+			if status := f.paymentStatus(t, payment.PaymentID); status != tt.wantPayment {
+				t.Errorf("payment is %s, want %s", status, tt.wantPayment)
+			}
+			if reconciled, err := f.usecase.Reconcile(ctx, time.Minute); err != nil || reconciled != 0 {
+				t.Fatalf("second Reconcile = %d, %v, want nothing left to do", reconciled, err)
+			}
+		})
+	}
+}
+
+// failingRefunds is a gateway whose refunds fail until fixed.
+type failingRefunds struct {
+	*paymentGateway.FakeGateway
+	failing bool
+}
+
+func (g *failingRefunds) Refund(ctx context.Context, intentID string, amount int64, reference string) (string, error) {
+	if g.failing {
+		return "", errors.New("gateway unavailable")
+	}
+	return g.FakeGateway.Refund(ctx, intentID, amount, reference)
+}
+
+func TestPaymentUsecaseRetriesFailedRefunds(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+	gateway := &failingRefunds{FakeGateway: f.gateway, failing: true}
+	f.usecase.gateway = gateway
+
+	payment, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature := f.settle(t, payment, model.PaymentSucceeded)
+	f.bookings.bookings[testBookingID].Status = model.BookingCancelled
+
+	if err := f.usecase.HandleWebhook(ctx, payload, signature); err == nil {
+		t.Fatal("HandleWebhook succeeded although the refund failed")
+	}
+	if refunds := f.refunds(t); len(refunds) != 1 || refunds[0].Status != model.RefundPending {
+		t.Fatalf("refunds = %+v, want one pending refund", refunds)
+	}
+
+	gateway.failing = false
+	if _, err := f.usecase.Reconcile(ctx, time.Minute); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if refunds := f.refunds(t); len(refunds) != 1 || refunds[0].Status != model.RefundSucceeded {
+		t.Fatalf("refunds = %+v, want the refund issued once", refunds)
+	}
+	if status := f.bookings.status(testBookingID); status != model.BookingRefunded {
+		t.Fatalf("booking is %s, want refunded", status)
+	}
+}
+
+func TestPaymentUsecaseRefundsCancelledHeldBooking(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+
+	payment, err := f.usecase.Pay(ctx, testBookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// paid, but the webhook is lost and the held booking cancelled
+	f.settle(t, payment, model.PaymentSucceeded)
+	if _, err := f.bookings.Transition(ctx, testBookingID, model.BookingCancelled, "changed plans"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.usecase.Reconcile(ctx, time.Minute); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if refunds := f.refunds(t); len(refunds) != 1 || refunds[0].Status != model.RefundSucceeded || refunds[0].Amount != payment.Amount {
+		t.Fatalf("refunds = %+v, want the whole payment refunded", refunds)
+	}
+	if status := f.bookings.status(testBookingID); status != model.BookingRefunded {
+		t.Fatalf("booking is %s, want refunded", status)
+	}
+	if reconciled, err := f.usecase.Reconcile(ctx, time.Minute); err != nil || reconciled != 0 {
+		t.Fatalf("second Reconcile = %d, %v, want nothing left to do", reconciled, err)
+	}
+}
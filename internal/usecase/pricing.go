@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// FareRules prices travellers by age band and takes discounts off the
+// subtotal. Percentages are whole numbers; see config.PricingConfig.
+type FareRules struct {
+	Currency             string
+	InfantMaxAge         int
+	ChildMaxAge          int
+	InfantPercent        int
+	ChildPercent         int
+	GroupMinPassengers   int
+	GroupDiscountPercent int
+	EarlyBookingDays     int
+	EarlyBookingPercent  int
+}
+
+const dateLayout = "2006-01-02"
+
+// Price quotes passengers, whose Name and Dob must be filled in, on trip as
+// booked at now. Ages are taken on the departure date. Discounts apply one
+// after the other, each to what is left after the previous ones, so the total
+// never goes below zero.
+func (r FareRules) Price(trip *model.Trip, passengers []model.Passenger, now time.Time) (*model.Quote, error) {
+	travelDate := trip.DepartureAt.UTC()
+	quote := &model.Quote{
+		TripID:     trip.TripID,
+		TravelDate: travelDate.Format(dateLayout),
+		Currency:   r.Currency,
+		Items:      make([]model.QuoteItem, 0, len(passengers)),
+		Discounts:  []model.QuoteDiscount{},
+	}
+
+	for _, passenger := range passengers {
+		dob, err := time.Parse(dateLayout, passenger.Dob)
+		if err != nil {
+			return nil, validation.NewError("validation_invalid_dob",
+				fmt.Sprintf("date of birth %q of %s is not in YYYY-MM-DD format", passenger.Dob, passenger.Name))
+		}
+		age := ageOn(dob, travelDate)
+		if age < 0 {
+			return nil, validation.NewError("validation_invalid_dob",
+				fmt.Sprintf("%s is born after the travel date", passenger.Name))
+		}
+
+		band, percent := r.band(age)
+		fare := percentOf(trip.Fare, percent)
+		quote.Items = append(quote.Items, model.QuoteItem{
+			PassengerID: passenger.PassengerID,
+			FamilyID:    passenger.FamilyID,
+			IsCustomer:  passenger.IsCustomer,
+			Name:        passenger.Name,
+			Dob:         passenger.Dob,
+			Age:         age,
+			Band:        band,
+			BaseFare:    trip.Fare,
+			FarePercent: percent,
+			Fare:        fare,
+		})
+		quote.Subtotal += fare
+	}
+
+	quote.Total = quote.Subtotal
+	discount := func(code, description string, percent int) {
+		amount := percentOf(quote.Total, percent)
+		quote.Discounts = append(quote.Discounts, model.QuoteDiscount{
+			Code:        code,
+			Description: description,
+			Percent:     percent,
+			Amount:      amount,
+		})
+		quote.Total -= amount
+	}
+	if r.GroupDiscountPercent > 0 && len(passengers) >= r.GroupMinPassengers {
+		discount("group", fmt.Sprintf("%d or more passengers", r.GroupMinPassengers), r.GroupDiscountPercent)
+	}
+	if r.EarlyBookingPercent > 0 && travelDate.Sub(now) >= time.Duration(r.EarlyBookingDays)*24*time.Hour {
+		discount("early_booking", fmt.Sprintf("booked %d or more days before departure", r.EarlyBookingDays), r.EarlyBookingPercent)
+	}
+
+	return quote, nil
+}
+
+// paymentItems splits a quote's total between its travellers in proportion
+// to their fares, so each passenger's share of the discounts is known when
+// they are refunded. Rounding leftovers come off the first items.
+func paymentItems(quote *model.Quote) []model.PaymentItem {
+	items := make([]model.PaymentItem, len(quote.Items))
+	discount := quote.Subtotal - quote.Total
+	left := discount
+	for i, item := range quote.Items {
+		var share int64
+		if quote.Subtotal > 0 {
+			share = discount * item.Fare / quote.Subtotal
+		}
+		left -= share
+		items[i] = model.PaymentItem{PassengerID: item.PassengerID, Band: item.Band, Amount: item.Fare - share}
+	}
+	for i := range items {
+		take := min(left, items[i].Amount)
+		items[i].Amount -= take
+		left -= take
+	}
+	return items
+}
+
+func (r FareRules) band(age int) (model.AgeBand, int) {
+	switch {
+	case age <= r.InfantMaxAge:
+		return model.AgeBandInfant, r.InfantPercent
+	case age <= r.ChildMaxAge:
+		return model.AgeBandChild, r.ChildPercent
+	default:
+		return model.AgeBandAdult, 100
+	}
+}
+
+// ageOn is the age in completed years on date; negative when born after it.
+// Someone born on 29 February turns a year older on 1 March in other years.
+func ageOn(dob, date time.Time) int {
+	age := date.Year() - dob.Year()
+	if date.Month() < dob.Month() || (date.Month() == dob.Month() && date.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+// percentOf is percent of amount, rounded half up.
+func percentOf(amount int64, percent int) int64 {
+	return (amount*int64(percent) + 50) / 100
+}
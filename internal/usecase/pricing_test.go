@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"testing"
+	"time"
+)
+
+func TestAgeOn(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		dob, on string
+		want    int
+	}{
+		{"2000-06-15", "2024-06-14", 23},
+		{"2000-06-15", "2024-06-15", 24},
+		{"2000-06-15", "2024-12-31", 24},
+		{"2024-03-01", "2024-03-01", 0},
+		{"2024-03-02", "2024-03-01", -1},
+		{"2020-02-29", "2023-02-28", 2},
+		{"2020-02-29", "2023-03-01", 3},
+		{"2020-02-29", "2024-02-29", 4},
+	}
+	for _, tt := range tests {
+		if got := ageOn(date(tt.dob), date(tt.on)); got != tt.want {
+			t.Errorf("ageOn(%s, %s) = %d, want %d", tt.dob, tt.on, got, tt.want)
+		}
+	}
+}
+
+func TestFareRulesPrice(t *testing.T) {
+	rules := FareRules{
+		Currency:             "IDR",
+		InfantMaxAge:         1,
+		ChildMaxAge:          11,
+		InfantPercent:        10,
+		ChildPercent:         75,
+		GroupMinPassengers:   4,
+		GroupDiscountPercent: 10,
+		EarlyBookingDays:     30,
+		EarlyBookingPercent:  5,
+	}
+	trip := &model.Trip{TripID: 7, DepartureAt: time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC), Fare: 1_000_001}
+	family := []model.Passenger{
+		{IsCustomer: true, Name: "Parent", Dob: "1990-01-01"},
+		{FamilyID: 1, Name: "Teen", Dob: "2013-07-01"},
+		{FamilyID: 2, Name: "Child", Dob: "2013-07-02"},
+		{FamilyID: 3, Name: "Infant", Dob: "2024-01-01"},
+	}
+
+	tests := []struct {
+		name          string
+		passengers    []model.Passenger
+		now           time.Time
+		wantBands     []model.AgeBand
+		wantSubtotal  int64
+		wantDiscounts []string
+		wantTotal     int64
+	}{
+		{
+			name:         "single adult, no discounts",
+			passengers:   family[:1],
+			now:          trip.DepartureAt.Add(-24 * time.Hour),
+			wantBands:    []model.AgeBand{model.AgeBandAdult},
+			wantSubtotal: 1_000_001,
+			wantTotal:    1_000_001,
+		},
+		{
+			name:       "age bands on the travel date",
+			passengers: family[:3],
+			now:        trip.DepartureAt.Add(-24 * time.Hour),
+			wantBands:  []model.AgeBand{model.AgeBandAdult, model.AgeBandAdult, model.AgeBandChild},
+			// 750000.75 rounds to 750001
+			wantSubtotal: 1_000_001*2 + 750_001,
+			wantTotal:    1_000_001*2 + 750_001,
+		},
+		{
+			name:          "group and early booking discounts compound",
+			passengers:    family,
+			now:           trip.DepartureAt.Add(-30 * 24 * time.Hour),
+			wantBands:     []model.AgeBand{model.AgeBandAdult, model.AgeBandAdult, model.AgeBandChild, model.AgeBandInfant},
+			wantSubtotal:  2_850_003,
+			wantDiscounts: []string{"group", "early_booking"},
+			// 2850003 - 285000 = 2565003, then - 128250
+			wantTotal: 2_436_753,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := rules.Price(trip, tt.passengers, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quote.TravelDate != "2025-07-01" || quote.Currency != "IDR" {
+				t.Fatalf("quote header = %+v", quote)
+			}
+			if len(quote.Items) != len(tt.wantBands) {
+				t.Fatalf("items = %+v", quote.Items)
+			}
+			for i, item := range quote.Items {
+				if item.Band != tt.wantBands[i] {
+					t.Errorf("item %d (%s, age %d) band = %s, want %s", i, item.Name, item.Age, item.Band, tt.wantBands[i])
+				}
+			}
+			if quote.Subtotal != tt.wantSubtotal || quote.Total != tt.wantTotal {
+				t.Errorf("subtotal = %d, total = %d, want %d and %d", quote.Subtotal, quote.Total, tt.wantSubtotal, tt.wantTotal)
+			}
+			if len(quote.Discounts) != len(tt.wantDiscounts) {
+				t.Fatalf("discounts = %+v, want %v", quote.Discounts, tt.wantDiscounts)
+			}
+			for i, discount := range quote.Discounts {
+				if discount.Code != tt.wantDiscounts[i] {
+					t.Errorf("discount %d = %s, want %s", i, discount.Code, tt.wantDiscounts[i])
+				}
+			}
+		})
+	}
+
+	for _, dob := range []string{"01/02/2000", "2025-07-02"} {
+		if _, err := rules.Price(trip, []model.Passenger{{Name: "Bad", Dob: dob}}, time.Time{}); err == nil {
+			t.Errorf("dob %s: expected an error", dob)
+		}
+	}
+}
+
+func TestPaymentItems(t *testing.T) {
+	quote := &model.Quote{
+		Items: []model.QuoteItem{
+			{PassengerID: 1, Band: model.AgeBandAdult, Fare: 1_000_001},
+			{PassengerID: 2, Band: model.AgeBandChild, Fare: 750_001},
+			{PassengerID: 3, Band: model.AgeBandInfant, Fare: 100_000},
+		},
+		Subtotal: 1_850_002,
+		Total:    1_665_002,
+	}
+
+	items := paymentItems(quote)
+	want := []int64{900_000, 675_001, 90_001}
+	var sum int64
+	for i, item := range items {
+		if item.PassengerID != quote.Items[i].PassengerID || item.Band != quote.Items[i].Band {
+			t.Errorf("item %d = %+v, want passenger %d", i, item, quote.Items[i].PassengerID)
+		}
+		if item.Amount != want[i] {
+			t.Errorf("item %d amount = %d, want %d", i, item.Amount, want[i])
+		}
+		sum += item.Amount
+	}
+	if sum != quote.Total {
+		t.Errorf("items add up to %d, want the total %d", sum, quote.Total)
+	}
+
+	free := paymentItems(&model.Quote{Items: []model.QuoteItem{{PassengerID: 1, Fare: 0}}})
+	if len(free) != 1 || free[0].Amount != 0 {
+		t.Errorf("free quote items = %+v", free)
+	}
+}
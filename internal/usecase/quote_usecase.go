@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+type IQuoteUsecase interface {
+	Quote(ctx context.Context, request *model.BookingRequest) (quote *model.Quote, err error)
+}
+
+type QuoteUsecase struct {
+	userRepository repository.IUserRepository
+	tripRepository repository.ITripRepository
+	rules          FareRules
+}
+
+func NewQuoteUsecase(userRepository repository.IUserRepository, tripRepository repository.ITripRepository, rules FareRules) *QuoteUsecase {
+	return &QuoteUsecase{
+		userRepository: userRepository,
+		tripRepository: tripRepository,
+		rules:          rules,
+	}
+}
+
+// Quote prices the passengers a booking request would carry on its trip,
+// using the dates of birth on file for the customer and family members.
+func (u *QuoteUsecase) Quote(ctx context.Context, request *model.BookingRequest) (quote *model.Quote, err error) {
+	ctx, span := tracing.Start(ctx, "QuoteUsecase.Quote")
+	defer func() { tracing.End(span, err) }()
+
+	err = validation.ValidateStruct(request,
+		validation.Field(&request.TripID, validation.Required.Error("Trip ID is required"), validation.Min(1).Error("Trip ID must be a positive integer")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := validatePassengers(ctx, u.userRepository, request)
+	if err != nil {
+		return nil, err
+	}
+
+	trip, err := u.tripRepository.GetByID(ctx, request.TripID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, validation.Errors{"trip_id": errors.New("trip not found")}
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Quote trip lookup failed: ", err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	if !trip.DepartureAt.After(now) {
+		return nil, fmt.Errorf("trip %s: %w", trip.TripCode, model.ErrTripDeparted)
+	}
+
+	return u.rules.Price(trip, travellers(customer, request), now)
+}
+
+// travellers lists the requested passengers with their names and dates of
+// birth, the customer first and then family members in request order.
+func travellers(customer *model.UserDetailResponse, request *model.BookingRequest) []model.Passenger {
+	families := make(map[int]model.Family, len(customer.Families))
+	for _, family := range customer.Families {
+		families[family.FamilyID] = family
+	}
+
+	passengers := []model.Passenger{}
+	if request.IncludeCustomer {
+		passengers = append(passengers, model.Passenger{IsCustomer: true, Name: customer.Name, Dob: customer.Dob})
+	}
+	for _, familyID := range request.FamilyIDs {
+		family := families[familyID]
+		passengers = append(passengers, model.Passenger{FamilyID: familyID, Name: family.Name, Dob: family.Dob})
+	}
+	return passengers
+}
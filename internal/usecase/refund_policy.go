@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefundRule refunds Percent of what a passenger paid when they cancel at
+// least MinDays before departure. An empty FareClass or Band matches any.
+type RefundRule struct {
+	FareClass model.FareClass
+	Band      model.AgeBand
+	MinDays   int
+	Percent   int
+}
+
+// anyValue matches every fare class or passenger type in a rule.
+const anyValue = "any"
+
+// ParseRefundRule parses a rule written as
+// "<fare class>:<passenger type>:<min days>:<percent>", for example
+// "standard:any:14:75". Fare class and passenger type may be "any".
+func ParseRefundRule(s string) (RefundRule, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 4 {
+		return RefundRule{}, fmt.Errorf("refund rule %q: want <fare class>:<passenger type>:<min days>:<percent>", s)
+	}
+
+	var rule RefundRule
+	switch class := model.FareClass(parts[0]); class {
+	case anyValue:
+	case model.FareClassStandard, model.FareClassFlex:
+		rule.FareClass = class
+	default:
+		return RefundRule{}, fmt.Errorf("refund rule %q: unknown fare class %q", s, parts[0])
+	}
+	switch band := model.AgeBand(parts[1]); band {
+	case anyValue:
+	case model.AgeBandAdult, model.AgeBandChild, model.AgeBandInfant:
+		rule.Band = band
+	default:
+		return RefundRule{}, fmt.Errorf("refund rule %q: unknown passenger type %q", s, parts[1])
+	}
+
+	var err error
+	if rule.MinDays, err = strconv.Atoi(parts[2]); err != nil || rule.MinDays < 0 {
+		return RefundRule{}, fmt.Errorf("refund rule %q: min days must be a whole number of days", s)
+	}
+	if rule.Percent, err = strconv.Atoi(parts[3]); err != nil || rule.Percent < 0 || rule.Percent > 100 {
+		return RefundRule{}, fmt.Errorf("refund rule %q: percent must be between 0 and 100", s)
+	}
+	return rule, nil
+}
+
+// String formats the rule the way ParseRefundRule reads it.
+func (r RefundRule) String() string {
+	class, band := string(r.FareClass), string(r.Band)
+	if class == "" {
+		class = anyValue
+	}
+	if band == "" {
+		band = anyValue
+	}
+	return fmt.Sprintf("%s:%s:%d:%d", class, band, r.MinDays, r.Percent)
+}
+
+func (r RefundRule) matches(class model.FareClass, band model.AgeBand, days int) bool {
+	return (r.FareClass == "" || r.FareClass == class) && (r.Band == "" || r.Band == band) && days >= r.MinDays
+}
+
+// RefundPolicy is an ordered list of refund rules; the first rule matching
+// a passenger applies and a passenger no rule matches gets nothing back.
+type RefundPolicy []RefundRule
+
+// ParseRefundPolicy parses rules in order; see ParseRefundRule.
+func ParseRefundPolicy(rules []string) (RefundPolicy, error) {
+	policy := make(RefundPolicy, 0, len(rules))
+	for _, s := range rules {
+		rule, err := ParseRefundRule(s)
+		if err != nil {
+			return nil, err
+		}
+		policy = append(policy, rule)
+	}
+	return policy, nil
+}
+
+// Rule returns the rule that applies to a passenger of band in class
+// cancelling days before departure, and false when none does.
+func (p RefundPolicy) Rule(class model.FareClass, band model.AgeBand, days int) (RefundRule, bool) {
+	for _, rule := range p {
+		if rule.matches(class, band, days) {
+			return rule, true
+		}
+	}
+	return RefundRule{}, false
+}
+
+// Item computes the refund of a passenger who paid paid.
+func (p RefundPolicy) Item(class model.FareClass, band model.AgeBand, days int, paid int64) model.RefundItem {
+	item := model.RefundItem{Band: band, Paid: paid, Rule: "none"}
+	if rule, ok := p.Rule(class, band, days); ok {
+		item.Rule = rule.String()
+		item.Percent = rule.Percent
+		item.Amount = percentOf(paid, rule.Percent)
+	}
+	return item
+}
+
+// daysBefore is the number of whole days left until departure, negative once
+// the trip has left.
+func daysBefore(departure, now time.Time) int {
+	left := departure.Sub(now)
+	if left < 0 {
+		return -1
+	}
+	return int(left / (24 * time.Hour))
+}
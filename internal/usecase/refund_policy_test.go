@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"testing"
+	"time"
+)
+
+func TestParseRefundRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    RefundRule
+		wantErr bool
+	}{
+		{rule: "standard:any:14:75", want: RefundRule{FareClass: model.FareClassStandard, MinDays: 14, Percent: 75}},
+		{rule: "any:infant:0:100", want: RefundRule{Band: model.AgeBandInfant, Percent: 100}},
+		{rule: " flex:child:1:50 ", want: RefundRule{FareClass: model.FareClassFlex, Band: model.AgeBandChild, MinDays: 1, Percent: 50}},
+		{rule: "standard:any:14", wantErr: true},
+		{rule: "business:any:1:10", wantErr: true},
+		{rule: "standard:senior:1:10", wantErr: true},
+		{rule: "standard:any:-1:10", wantErr: true},
+		{rule: "standard:any:1:101", wantErr: true},
+		{rule: "standard:any:x:10", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRefundRule(tt.rule)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRefundRule(%q) = %+v, want an error", tt.rule, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRefundRule(%q): %v", tt.rule, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRefundRule(%q) = %+v, want %+v", tt.rule, got, tt.want)
+		}
+		if again, _ := ParseRefundRule(got.String()); again != got {
+			t.Errorf("%q does not round trip through String: %q", tt.rule, got.String())
+		}
+	}
+}
+
+func TestRefundPolicyItem(t *testing.T) {
+	policy, err := ParseRefundPolicy([]string{
+		"flex:any:1:100", "flex:any:0:50", "standard:infant:0:100", "standard:any:14:75", "standard:any:3:25",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		class model.FareClass
+		band  model.AgeBand
+		days  int
+		want  model.RefundItem
+	}{
+		{"flex the day before", model.FareClassFlex, model.AgeBandAdult, 1,
+			model.RefundItem{Band: model.AgeBandAdult, Paid: 1001, Rule: "flex:any:1:100", Percent: 100, Amount: 1001}},
+		{"flex on the day", model.FareClassFlex, model.AgeBandAdult, 0,
+			model.RefundItem{Band: model.AgeBandAdult, Paid: 1001, Rule: "flex:any:0:50", Percent: 50, Amount: 501}},
+		{"standard infant on the day", model.FareClassStandard, model.AgeBandInfant, 0,
+			model.RefundItem{Band: model.AgeBandInfant, Paid: 1001, Rule: "standard:infant:0:100", Percent: 100, Amount: 1001}},
+		{"standard two weeks ahead", model.FareClassStandard, model.AgeBandChild, 14,
+			model.RefundItem{Band: model.AgeBandChild, Paid: 1001, Rule: "standard:any:14:75", Percent: 75, Amount: 751}},
+		{"standard thirteen days ahead", model.FareClassStandard, model.AgeBandAdult, 13,
+			model.RefundItem{Band: model.AgeBandAdult, Paid: 1001, Rule: "standard:any:3:25", Percent: 25, Amount: 250}},
+		{"standard two days ahead", model.FareClassStandard, model.AgeBandAdult, 2,
+			model.RefundItem{Band: model.AgeBandAdult, Paid: 1001, Rule: "none"}},
+	}
+	for _, tt := range tests {
+		if got := policy.Item(tt.class, tt.band, tt.days, 1001); got != tt.want {
+			t.Errorf("%s: item = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDaysBefore(t *testing.T) {
+	departure := time.Date(2025, 7, 15, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want int
+	}{
+		{departure.Add(-14 * 24 * time.Hour), 14},
+		{departure.Add(-14*24*time.Hour + time.Minute), 13},
+		{departure.Add(-time.Hour), 0},
+		{departure.Add(time.Minute), -1},
+	}
+	for _, tt := range tests {
+		if got := daysBefore(departure, tt.now); got != tt.want {
+			t.Errorf("daysBefore(%s, %s) = %d, want %d", departure, tt.now, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+type ITripUsecase interface {
+	GetAll(ctx context.Context) (trips []*model.Trip, err error)
+	Detail(ctx context.Context, tripID int) (trip *model.Trip, err error)
+	Create(ctx context.Context, trip *model.Trip) (err error)
+}
+
+type TripUsecase struct {
+	tripRepository repository.ITripRepository
+}
+
+func NewTripUsecase(tripRepository repository.ITripRepository) *TripUsecase {
+	return &TripUsecase{
+		tripRepository: tripRepository,
+	}
+}
+
+func (u *TripUsecase) GetAll(ctx context.Context) (trips []*model.Trip, err error) {
+	ctx, span := tracing.Start(ctx, "TripUsecase.GetAll")
+	defer func() { tracing.End(span, err) }()
+
+	trips, err = u.tripRepository.GetAll(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Trip get all failed: ", err.Error())
+	}
+	return
+}
+
+func (u *TripUsecase) Detail(ctx context.Context, tripID int) (trip *model.Trip, err error) {
+	ctx, span := tracing.Start(ctx, "TripUsecase.Detail")
+	defer func() { tracing.End(span, err) }()
+
+	trip, err = u.tripRepository.GetByID(ctx, tripID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("trip %d: %w", tripID, model.ErrNotFound)
+	}
+	return
+}
+
+func (u *TripUsecase) Create(ctx context.Context, trip *model.Trip) (err error) {
+	ctx, span := tracing.Start(ctx, "TripUsecase.Create")
+	defer func() { tracing.End(span, err) }()
+
+	err = validation.ValidateStruct(trip,
+		validation.Field(&trip.TripCode, validation.Required, validation.Length(1, 20)),
+		validation.Field(&trip.Origin, validation.Required, validation.Length(1, 100)),
+		validation.Field(&trip.Destination, validation.Required, validation.Length(1, 100)),
+		validation.Field(&trip.DepartureAt, validation.Required, validation.By(inFuture)),
+		validation.Field(&trip.Capacity, validation.Required, validation.Min(1)),
+		validation.Field(&trip.Fare, validation.Min(int64(0))),
+	)
+	if err != nil {
+		return err
+	}
+
+	// stored without a zone, so always in UTC
+	trip.DepartureAt = trip.DepartureAt.UTC()
+	if err = u.tripRepository.Create(ctx, trip); err != nil {
+		logger.FromContext(ctx).Error("Trip create failed: ", err.Error())
+	}
+	return
+}
+
+func inFuture(value interface{}) error {
+	t, _ := value.(time.Time)
+	if !t.After(time.Now()) {
+		return validation.NewError("validation_not_in_future", "must be in the future")
+	}
+	return nil
+}
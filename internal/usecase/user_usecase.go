@@ -1,15 +1,23 @@
 package usecase
 
 import (
+	"booking_togo/internal/logger"
+	"booking_togo/internal/metrics"
 	"booking_togo/internal/model"
+	"booking_togo/internal/phone"
 	"booking_togo/internal/repository"
+	"booking_togo/internal/tracing"
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/jackc/pgx/v5"
 )
 
 type IUserUsecase interface {
@@ -19,22 +27,30 @@ type IUserUsecase interface {
 	Update(ctx context.Context, user *model.User) (err error)
 	Delete(ctx context.Context, userID int) (err error)
 	DeleteFamily(ctx context.Context, userID int, familyID int) (err error)
+	Search(ctx context.Context, search model.UserSearch) (users []*model.UserDetailResponse, err error)
 }
 
 type UserUsecase struct {
-	userRepository repository.IUserRepository
+	userRepository        repository.IUserRepository
+	nationalityRepository repository.INationalityRepository
 }
 
-func NewUserUsecase(userRepository repository.IUserRepository) *UserUsecase {
+// NewUserUsecase builds the customer usecase. Nationalities give the region
+// phone numbers without a country code are read in.
+func NewUserUsecase(userRepository repository.IUserRepository, nationalityRepository repository.INationalityRepository) *UserUsecase {
 	return &UserUsecase{
-		userRepository: userRepository,
+		userRepository:        userRepository,
+		nationalityRepository: nationalityRepository,
 	}
 }
 
 func (u *UserUsecase) GetAll(ctx context.Context) (users []*model.UserDetailResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetAll")
+	defer func() { tracing.End(span, err) }()
+
 	users, err = u.userRepository.GetAll(ctx)
 	if err != nil {
-		log.Error("User get all failed: ", err.Error())
+		logger.FromContext(ctx).Error("User get all failed: ", err.Error())
 		return
 	}
 	return
@@ -42,9 +58,119 @@ func (u *UserUsecase) GetAll(ctx context.Context) (users []*model.UserDetailResp
 }
 
 func (u *UserUsecase) Create(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err = u.validateCreate(ctx, user); err != nil {
+		return err
+	}
+
+	if err = u.userRepository.Create(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("User create failed: ", err.Error())
+		return
+	}
+
+	metrics.CustomersCreated.Inc()
+	metrics.FamilyMembersUpserted.Add(float64(len(user.Families)))
+
+	return
+}
+
+func (u *UserUsecase) Detail(ctx context.Context, id int) (user *model.UserDetailResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Detail")
+	defer func() { tracing.End(span, err) }()
+
+	userDetail, userDetailErr := u.userRepository.GetUserDetail(ctx, id)
+	if userDetailErr != nil {
+		err = userDetailErr
+		return
+	}
+	return userDetail, nil
+}
+
+func (u *UserUsecase) Update(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if err = u.validateUpdate(ctx, user); err != nil {
+		return err
+	}
+
+	if err = u.userRepository.Update(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("User - Family Update failed: ", err)
+		return err
+	}
+
+	metrics.FamilyMembersUpserted.Add(float64(len(user.Families)))
+
+	return
+}
+
+func (u *UserUsecase) Delete(ctx context.Context, userID int) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	if err = u.userRepository.Delete(ctx, userID); err != nil {
+		logger.FromContext(ctx).Error("User - Family Delete failed: ", err)
+		return
+	}
+
+	metrics.CustomersDeleted.Inc()
+
+	return
+}
+
+func (u *UserUsecase) DeleteFamily(ctx context.Context, userID int, familyID int) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.DeleteFamily")
+	defer func() { tracing.End(span, err) }()
+
+	if err = u.userRepository.DeleteFamily(ctx, userID, familyID); err != nil {
+		logger.FromContext(ctx).Error("Family Delete failed: ", err)
+		return
+	}
+
+	return
+}
+
+// Search finds customers by email, regardless of case, and/or phone. The
+// phone must include its country code.
+func (u *UserUsecase) Search(ctx context.Context, search model.UserSearch) (users []*model.UserDetailResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Search")
+	defer func() { tracing.End(span, err) }()
+
+	search.Email = strings.TrimSpace(search.Email)
+	err = validation.ValidateStruct(&search,
+		validation.Field(&search.Email, validation.Required.When(search.Phone == "").Error("email or phone is required"), is.EmailFormat),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if search.Phone != "" {
+		if search.Phone, err = phone.Normalize(search.Phone, ""); err != nil {
+			return nil, validation.Errors{"phone": err}
+		}
+	}
+
+	users, err = u.userRepository.Search(ctx, search)
+	if err != nil {
+		logger.FromContext(ctx).Error("User search failed: ", err.Error())
+		return nil, err
+	}
+	return users, nil
+}
+
+func (u *UserUsecase) validateCreate(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.validateCreate")
+	defer func() { tracing.End(span, err) }()
+
 	err = u.validateUserFamilies(*user)
 	if err != nil {
-		log.Error("User validation failed: ", err.Error())
+		logger.FromContext(ctx).Error("User validation failed: ", err.Error())
+		return err
+	}
+
+	if err = u.validateContact(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("User contact validation failed: ", err.Error())
 		return err
 	}
 
@@ -55,36 +181,36 @@ func (u *UserUsecase) Create(ctx context.Context, user *model.User) (err error)
 		err = validation.ValidateStruct(&v,
 			validation.Field(&v.Name, validation.Required.Error(msgErrorName), validation.Length(5, 50)),
 			validation.Field(&v.Dob, validation.Required.Error(msgErrorDob), validation.Match(regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)).Error("Date must be in YYYY-MM-DD format"),
-				validation.By(validateDOBFormat)),
+				validation.By(validateDateFormat)),
 		)
 
 		if err != nil {
-			log.Error("User - Family validation failed: ", err)
+			logger.FromContext(ctx).Error("User - Family validation failed: ", err)
 			return err
 		}
 	}
 
-	if err = u.userRepository.Create(ctx, user); err != nil {
-		log.Error("User create failed: ", err.Error())
-		return
+	// new family members have no ID yet, so only the customer can be named
+	if err = validateGuardians(user, nil); err != nil {
+		logger.FromContext(ctx).Error("User - Family guardian validation failed: ", err)
+		return err
 	}
 
-	return
+	return nil
 }
 
-func (u *UserUsecase) Detail(ctx context.Context, id int) (user *model.UserDetailResponse, err error) {
-	userDetail, userDetailErr := u.userRepository.GetUserDetail(ctx, id)
-	if userDetailErr != nil {
-		err = userDetailErr
-		return
-	}
-	return userDetail, nil
-}
+func (u *UserUsecase) validateUpdate(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.validateUpdate")
+	defer func() { tracing.End(span, err) }()
 
-func (u *UserUsecase) Update(ctx context.Context, user *model.User) (err error) {
 	err = u.validateUserFamilies(*user)
 	if err != nil {
-		log.Error("User validation failed: ", err.Error())
+		logger.FromContext(ctx).Error("User validation failed: ", err.Error())
+		return err
+	}
+
+	if err = u.validateContact(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("User contact validation failed: ", err.Error())
 		return err
 	}
 
@@ -94,63 +220,229 @@ func (u *UserUsecase) Update(ctx context.Context, user *model.User) (err error)
 
 		err = validation.ValidateStruct(&v,
 			validation.Field(&v.FamilyID, validation.Min(0).Error("Family ID must be a positive integer or zero for new family record")),
-			validation.Field(&v.UserID, validation.Required.Error("User ID is required"), validation.Min(1).Error("User ID must be a positive integer")),
+			validation.Field(&v.UserID, validation.Required.Error("User ID is required"),
+				validation.In(user.UserID).Error("User ID must be the ID of the customer being updated")),
 			validation.Field(&v.Name, validation.Required.Error(msgErrorName), validation.Length(5, 50)),
 			validation.Field(&v.Dob, validation.Required.Error(msgErrorDob), validation.Match(regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)).Error("Date must be in YYYY-MM-DD format"),
-				validation.By(validateDOBFormat)),
+				validation.By(validateDateFormat)),
 		)
 
 		if err != nil {
-			log.Error("User - Family validation failed: ", err)
+			logger.FromContext(ctx).Error("User - Family validation failed: ", err)
 			return err
 		}
 	}
 
-	if err = u.userRepository.Update(ctx, user); err != nil {
-		log.Error("User - Family Update failed: ", err)
+	stored, err := u.storedFamilies(ctx, user)
+	if err != nil {
+		return err
+	}
+	if err = validateFamilyOwnership(user, stored); err != nil {
+		logger.FromContext(ctx).Error("User - Family ownership validation failed: ", err)
+		return err
+	}
+	if err = validateGuardians(user, stored); err != nil {
+		logger.FromContext(ctx).Error("User - Family guardian validation failed: ", err)
 		return err
 	}
 
-	return
+	return nil
 }
 
-func (u *UserUsecase) Delete(ctx context.Context, userID int) (err error) {
-	if err = u.userRepository.Delete(ctx, userID); err != nil {
-		log.Error("User - Family Delete failed: ", err)
-		return
+// storedFamilies loads the family members user already has, when the
+// payload refers to any of them by ID.
+func (u *UserUsecase) storedFamilies(ctx context.Context, user *model.User) ([]model.Family, error) {
+	referenced := false
+	for _, family := range user.Families {
+		if family.FamilyID != 0 || (family.Guardian != nil && family.Guardian.FamilyID != 0) {
+			referenced = true
+			break
+		}
+	}
+	if !referenced {
+		return nil, nil
 	}
 
-	return
+	current, err := u.userRepository.GetUserDetail(ctx, user.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if current == nil {
+		return nil, nil
+	}
+	return current.Families, nil
 }
 
-func (u *UserUsecase) DeleteFamily(ctx context.Context, userID int, familyID int) (err error) {
-	if err = u.userRepository.DeleteFamily(ctx, userID, familyID); err != nil {
-		log.Error("Family Delete failed: ", err)
-		return
+// validateFamilyOwnership rejects updates naming a family member of another
+// customer, which the upsert would otherwise overwrite and move over.
+func validateFamilyOwnership(user *model.User, stored []model.Family) error {
+	owned := make(map[int]bool, len(stored))
+	for _, family := range stored {
+		owned[family.FamilyID] = true
 	}
-
-	return
+	for _, family := range user.Families {
+		if family.FamilyID != 0 && !owned[family.FamilyID] {
+			return validation.Errors{"families": validation.NewError("validation_family_not_owned",
+				fmt.Sprintf("family member %d is not a family member of the customer", family.FamilyID))}
+		}
+	}
+	return nil
 }
 
 func (u *UserUsecase) validateUserFamilies(user model.User) error {
 	err := validation.ValidateStruct(&user,
 		validation.Field(&user.Name, validation.Required, validation.Length(5, 50)),
 		validation.Field(&user.Dob, validation.Required, validation.Match(regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)).Error("Date must be in YYYY-MM-DD format"),
-			validation.By(validateDOBFormat)),
+			validation.By(validateDateFormat)),
 		validation.Field(&user.NationalityID, validation.Required),
 	)
 
 	return err
 }
 
-func validateDOBFormat(value interface{}) error {
-	dob, ok := value.(string)
+// validateContact checks the optional email, phone, address and emergency
+// contacts of user and normalises them in place: emails are trimmed and
+// phones turned into E.164, national numbers being read in the region of the
+// customer's nationality.
+func (u *UserUsecase) validateContact(ctx context.Context, user *model.User) error {
+	user.Email = strings.TrimSpace(user.Email)
+	user.Phone = strings.TrimSpace(user.Phone)
+
+	err := validation.ValidateStruct(user,
+		validation.Field(&user.Email, validation.Length(0, 254), is.EmailFormat),
+		validation.Field(&user.EmergencyContacts, validation.Length(0, maxEmergencyContacts)),
+	)
+	if err != nil {
+		return err
+	}
+	if err = validateAddress(user.Address); err != nil {
+		return validation.Errors{"address": err}
+	}
+
+	if user.Phone == "" && len(user.EmergencyContacts) == 0 {
+		return nil
+	}
+	region := ""
+	nationality, err := u.nationalityRepository.GetByID(ctx, user.NationalityID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if nationality != nil {
+		region = nationality.NationalityCode
+	}
+	if user.Phone != "" {
+		if user.Phone, err = phone.Normalize(user.Phone, region); err != nil {
+			return validation.Errors{"phone": err}
+		}
+	}
+	for i := range user.EmergencyContacts {
+		if err = validateEmergencyContact(&user.EmergencyContacts[i], region); err != nil {
+			return validation.Errors{"emergency_contacts": validation.Errors{strconv.Itoa(i): err}}
+		}
+	}
+	return nil
+}
+
+// maxEmergencyContacts is how many emergency contacts a customer can keep.
+const maxEmergencyContacts = 3
+
+func validateEmergencyContact(contact *model.EmergencyContact, region string) error {
+	contact.Name = strings.TrimSpace(contact.Name)
+	contact.Relationship = strings.TrimSpace(contact.Relationship)
+	contact.Email = strings.TrimSpace(contact.Email)
+
+	err := validation.ValidateStruct(contact,
+		validation.Field(&contact.Name, validation.Required, validation.Length(2, 100)),
+		validation.Field(&contact.Relationship, validation.Required, validation.Length(1, 50)),
+		validation.Field(&contact.Phone, validation.Required),
+		validation.Field(&contact.Email, validation.Length(0, 254), is.EmailFormat),
+	)
+	if err != nil {
+		return err
+	}
+	if contact.Phone, err = phone.Normalize(contact.Phone, region); err != nil {
+		return validation.Errors{"phone": err}
+	}
+	return nil
+}
+
+// adultAge is the age from which someone can be a guardian and travel
+// without one.
+const adultAge = 18
+
+// validateGuardians checks the guardian of every family member of user: the
+// customer, or another stored family member of theirs who is not the member
+// itself. Either way the guardian must be an adult today, by the DOB in user
+// when the payload changes it. Family DOBs must already be valid.
+func validateGuardians(user *model.User, stored []model.Family) error {
+	dobs := make(map[int]string, len(stored))
+	for _, family := range stored {
+		dobs[family.FamilyID] = family.Dob
+	}
+	for _, family := range user.Families {
+		if _, ok := dobs[family.FamilyID]; ok {
+			dobs[family.FamilyID] = family.Dob
+		}
+	}
+
+	today := time.Now().UTC()
+	for _, family := range user.Families {
+		if family.Guardian == nil {
+			continue
+		}
+		guardianID := family.Guardian.FamilyID
+		guardianDob, ok := dobs[guardianID]
+		switch {
+		case guardianID == 0:
+			guardianDob = user.Dob
+		case guardianID == family.FamilyID:
+			return validation.Errors{"guardian": validation.NewError("validation_guardian_self",
+				fmt.Sprintf("%s cannot be their own guardian", family.Name))}
+		case !ok:
+			return validation.Errors{"guardian": validation.NewError("validation_guardian_not_in_family",
+				fmt.Sprintf("guardian %d of %s is not a family member of the customer", guardianID, family.Name))}
+		}
+
+		dob, err := time.Parse(dateLayout, guardianDob)
+		if err != nil {
+			return err
+		}
+		if ageOn(dob, today) < adultAge {
+			return validation.Errors{"guardian": validation.NewError("validation_guardian_minor",
+				fmt.Sprintf("the guardian of %s must be at least %d years old", family.Name, adultAge))}
+		}
+	}
+	return nil
+}
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+func validateAddress(address *model.Address) error {
+	if address == nil {
+		return nil
+	}
+	address.CountryCode = strings.ToUpper(strings.TrimSpace(address.CountryCode))
+	return validation.ValidateStruct(address,
+		validation.Field(&address.Line1, validation.Required, validation.Length(1, 100)),
+		validation.Field(&address.Line2, validation.Length(0, 100)),
+		validation.Field(&address.City, validation.Required, validation.Length(1, 100)),
+		validation.Field(&address.Region, validation.Length(0, 100)),
+		validation.Field(&address.PostalCode, validation.Required, validation.Length(1, 20)),
+		validation.Field(&address.CountryCode, validation.Required,
+			validation.Match(countryCodePattern).Error("must be an ISO 3166 alpha-2 code")),
+	)
+}
+
+// validateDateFormat checks a YYYY-MM-DD calendar date, such as a date of
+// birth or a document expiry.
+func validateDateFormat(value interface{}) error {
+	date, ok := value.(string)
 	if !ok {
-		return validation.NewError("validation_invalid_dob", "DOB must be a string")
+		return validation.NewError("validation_invalid_date", "date must be a string")
 	}
 
 	// Parse the date to ensure it's valid
-	_, err := time.Parse("2006-01-02", dob)
+	_, err := time.Parse("2006-01-02", date)
 	if err != nil {
 		return validation.NewError("validation_invalid_date", "Invalid date format. Use YYYY-MM-DD")
 	}
@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"booking_togo/internal/model"
+	"booking_togo/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUserUsecaseCreateValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    model.User
+		wantErr bool
+	}{
+		{
+			name: "valid customer with family",
+			user: model.User{Name: "Budi Santoso", Dob: "1990-05-15", NationalityID: 1,
+				Families: []model.Family{{Name: "Siti Aminah", Dob: "1992-01-01"}}},
+		},
+		{
+			name:    "name too short",
+			user:    model.User{Name: "Budi", Dob: "1990-05-15", NationalityID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			user:    model.User{Name: "Budi Santoso", Dob: "1990-02-30", NationalityID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "missing nationality",
+			user:    model.User{Name: "Budi Santoso", Dob: "1990-05-15"},
+			wantErr: true,
+		},
+		{
+			name: "invalid family member",
+			user: model.User{Name: "Budi Santoso", Dob: "1990-05-15", NationalityID: 1,
+				Families: []model.Family{{Name: "Siti Aminah", Dob: "01-01-1992"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryUserRepository()
+			u := NewUserUsecase(repo, repo.Nationalities())
+
+			err := u.Create(context.Background(), &tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			users, _ := repo.GetAll(context.Background())
+			if wantStored := !tt.wantErr; (len(users) == 1) != wantStored {
+				t.Fatalf("stored %d users, want stored = %v", len(users), wantStored)
+			}
+		})
+	}
+}
+
+func TestUserUsecaseUpdateRequiresFamilyOwner(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository()
+	u := NewUserUsecase(repo, repo.Nationalities())
+	ctx := context.Background()
+
+	user := &model.User{Name: "Budi Santoso", Dob: "1990-05-15", NationalityID: 1}
+	if err := u.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	user.Families = []model.Family{{Name: "Siti Aminah", Dob: "1992-01-01"}}
+	if err := u.Update(ctx, user); err == nil {
+		t.Fatal("Update accepted a family member without user_id")
+	}
+
+	user.Families[0].UserID = user.UserID
+	if err := u.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	detail, err := u.Detail(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("Detail: %v", err)
+	}
+	if len(detail.Families) != 1 || detail.Families[0].Name != "Siti Aminah" {
+		t.Fatalf("families = %+v", detail.Families)
+	}
+	victimFamily := detail.Families[0]
+
+	other := &model.User{Name: "Andi Wijaya", Dob: "1985-02-20", NationalityID: 1}
+	if err := u.Create(ctx, other); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	takeovers := map[string]model.Family{
+		"family member filed under another customer": {UserID: user.UserID, Name: "Rina Wijaya", Dob: "1990-01-01"},
+		"another customer's family member by ID":     {FamilyID: victimFamily.FamilyID, UserID: other.UserID, Name: "Stolen Member", Dob: "1992-01-01"},
+		"unknown family member ID":                   {FamilyID: 999, UserID: other.UserID, Name: "Ghost Member", Dob: "1992-01-01"},
+	}
+	for name, family := range takeovers {
+		other.Families = []model.Family{family}
+		if err := u.Update(ctx, other); err == nil {
+			t.Errorf("%s: Update accepted", name)
+		}
+	}
+
+	detail, err = u.Detail(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("Detail: %v", err)
+	}
+	if len(detail.Families) != 1 || detail.Families[0] != victimFamily {
+		t.Fatalf("families of the first customer changed to %+v", detail.Families)
+	}
+}
+
+func TestUserUsecaseContactDetails(t *testing.T) {
+	ctx := context.Background()
+	address := &model.Address{Line1: "Jl. Sudirman 1", City: "Jakarta", PostalCode: "10220", CountryCode: "id"}
+
+	tests := []struct {
+		name      string
+		user      model.User
+		wantPhone string
+		wantErr   bool
+	}{
+		{
+			name:      "national phone is read in the nationality's region",
+			user:      model.User{Email: " budi@example.com ", Phone: "0812-3456-7890", Address: address},
+			wantPhone: "+6281234567890",
+		},
+		{
+			name:      "international phone keeps its country code",
+			user:      model.User{Phone: "+65 6123 4567"},
+			wantPhone: "+6561234567",
+		},
+		{name: "invalid email", user: model.User{Email: "budi@"}, wantErr: true},
+		{name: "invalid phone", user: model.User{Phone: "0812-CALL-ME"}, wantErr: true},
+		{name: "incomplete address", user: model.User{Address: &model.Address{Line1: "Jl. Sudirman 1"}}, wantErr: true},
+		{name: "address country", user: model.User{Address: &model.Address{Line1: "x", City: "y", PostalCode: "1", CountryCode: "IDN"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryUserRepository()
+			u := NewUserUsecase(repo, repo.Nationalities())
+
+			user := tt.user
+			user.Name, user.Dob, user.NationalityID = "Budi Santoso", "1990-05-15", repo.AddNationality("Indonesia", "ID")
+			err := u.Create(ctx, &user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			detail, _ := u.Detail(ctx, user.UserID)
+			if detail.Phone != tt.wantPhone || detail.Email != strings.TrimSpace(tt.user.Email) {
+				t.Fatalf("stored email %q, phone %q, want phone %q", detail.Email, detail.Phone, tt.wantPhone)
+			}
+			if tt.user.Address != nil && detail.Address.CountryCode != "ID" {
+				t.Fatalf("address = %+v", detail.Address)
+			}
+		})
+	}
+}
+
+func TestUserUsecaseSearch(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository()
+	u := NewUserUsecase(repo, repo.Nationalities())
+	ctx := context.Background()
+
+	user := &model.User{Name: "Budi Santoso", Dob: "1990-05-15", NationalityID: repo.AddNationality("Indonesia", "ID"),
+		Email: "budi@example.com", Phone: "0812 3456 7890"}
+	if err := u.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := u.Create(ctx, &model.User{Name: "Budi Kembar", Dob: "1990-05-15", NationalityID: user.NationalityID,
+		Email: "BUDI@example.com"}); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Create with a used email: err = %v, want model.ErrConflict", err)
+	}
+
+	for _, search := range []model.UserSearch{{Email: "Budi@Example.com"}, {Phone: "+62 812-3456-7890"}} {
+		users, err := u.Search(ctx, search)
+		if err != nil {
+			t.Fatalf("Search(%+v): %v", search, err)
+		}
+		if len(users) != 1 || users[0].UserID != user.UserID {
+			t.Fatalf("Search(%+v) = %+v", search, users)
+		}
+	}
+
+	for _, search := range []model.UserSearch{{}, {Email: "not-an-email"}, {Phone: "0812 3456 7890"}} {
+		if _, err := u.Search(ctx, search); err == nil {
+			t.Fatalf("Search(%+v) accepted", search)
+		}
+	}
+}
+
+func TestUserUsecaseEmergencyContacts(t *testing.T) {
+	ctx := context.Background()
+	sister := model.EmergencyContact{Name: "Siti Aminah", Relationship: "sister", Phone: "0812-3456-7890"}
+
+	tests := []struct {
+		name      string
+		contacts  []model.EmergencyContact
+		wantPhone string
+		wantErr   bool
+	}{
+		{name: "national phone is read in the nationality's region", contacts: []model.EmergencyContact{sister}, wantPhone: "+6281234567890"},
+		{name: "missing phone", contacts: []model.EmergencyContact{{Name: "Siti Aminah", Relationship: "sister"}}, wantErr: true},
+		{name: "missing relationship", contacts: []model.EmergencyContact{{Name: "Siti Aminah", Phone: "+6281234567890"}}, wantErr: true},
+		{name: "invalid email", contacts: []model.EmergencyContact{{Name: "Siti Aminah", Relationship: "sister", Phone: "+6281234567890", Email: "siti@"}}, wantErr: true},
+		{name: "too many contacts", contacts: []model.EmergencyContact{sister, sister, sister, sister}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryUserRepository()
+			u := NewUserUsecase(repo, repo.Nationalities())
+
+			user := model.User{Name: "Budi Santoso", Dob: "1990-05-15", NationalityID: repo.AddNationality("Indonesia", "ID"),
+				EmergencyContacts: tt.contacts}
+			err := u.Create(ctx, &user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			detail, _ := u.Detail(ctx, user.UserID)
+			if len(detail.EmergencyContacts) != 1 || detail.EmergencyContacts[0].Phone != tt.wantPhone {
+				t.Fatalf("emergency contacts = %+v, want phone %q", detail.EmergencyContacts, tt.wantPhone)
+			}
+		})
+	}
+}
+
+func TestUserUsecaseGuardians(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository()
+	u := NewUserUsecase(repo, repo.Nationalities())
+	ctx := context.Background()
+	nationalityID := repo.AddNationality("Indonesia", "ID")
+
+	customerGuardian := &model.Guardian{FamilyID: 0}
+	if err := u.Create(ctx, &model.User{Name: "Young Customer", Dob: "2012-01-01", NationalityID: nationalityID,
+		Families: []model.Family{{Name: "Baby Sibling", Dob: "2020-01-01", Guardian: customerGuardian}}}); err == nil {
+		t.Fatal("Create accepted a customer under 18 as guardian")
+	}
+	if err := u.Create(ctx, &model.User{Name: "Budi Santoso", Dob: "1980-01-01", NationalityID: nationalityID,
+		Families: []model.Family{{Name: "Minor Child", Dob: "2015-01-01", Guardian: &model.Guardian{FamilyID: 1}}}}); err == nil {
+		t.Fatal("Create accepted a family guardian, new members have no ID yet")
+	}
+
+	user := &model.User{Name: "Budi Santoso", Dob: "1980-01-01", NationalityID: nationalityID,
+		Families: []model.Family{
+			{Name: "Adult Sibling", Dob: "1985-01-01"},
+			{Name: "Minor Child", Dob: "2015-01-01", Guardian: customerGuardian},
+			{Name: "Older Child", Dob: "2012-01-01"},
+		}}
+	if err := u.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	ids := familyIDs(t, repo, user.UserID)
+	adultID, minorID, olderID := ids[0], ids[1], ids[2]
+
+	other := &model.User{Name: "Other Customer", Dob: "1975-01-01", NationalityID: nationalityID,
+		Families: []model.Family{{Name: "Other Adult", Dob: "1970-01-01"}}}
+	if err := u.Create(ctx, other); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	otherAdultID := familyIDs(t, repo, other.UserID)[0]
+	if err := u.Create(ctx, &model.User{Name: "Budi Santoso", Dob: "1980-01-01", NationalityID: nationalityID,
+		Families: []model.Family{
+			{FamilyID: otherAdultID, Name: "Other Adult", Dob: "1970-01-01"},
+			{Name: "Minor Child", Dob: "2015-01-01", Guardian: &model.Guardian{FamilyID: otherAdultID}},
+		}}); err == nil {
+		t.Fatal("Create accepted another customer's family member as guardian")
+	}
+
+	tests := []struct {
+		name       string
+		guardianID int
+		wantErr    bool
+	}{
+		{name: "stored adult family member", guardianID: adultID},
+		{name: "the customer", guardianID: 0},
+		{name: "themselves", guardianID: minorID, wantErr: true},
+		{name: "another minor", guardianID: olderID, wantErr: true},
+		{name: "not a family member", guardianID: 999, wantErr: true},
+		{name: "another customer's family member", guardianID: otherAdultID, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user.Families = []model.Family{{FamilyID: minorID, UserID: user.UserID, Name: "Minor Child", Dob: "2015-01-01",
+				Guardian: &model.Guardian{FamilyID: tt.guardianID}}}
+			err := u.Update(ctx, user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}